@@ -0,0 +1,237 @@
+package bundle
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/feature"
+	"github.com/whosonfirst/go-whosonfirst-uri"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+const FORMAT_TAR string = "tar"
+const FORMAT_ZIP string = "zip"
+
+var zip_magic = []byte("PK\x03\x04")
+
+var ErrNotFound = errors.New("Feature not found in bundle")
+
+// BundleFunc is invoked with the path of an entry inside the bundle and the
+// feature that was decoded from it.
+
+type BundleFunc func(ctx context.Context, path string, f geojson.Feature) error
+
+// Bundle reads GeoJSON features from a tar, tar.gz, tar.bz2 or zip archive
+// without unpacking it to disk.
+
+type Bundle struct {
+	path   string
+	format string
+}
+
+func Open(path string) (*Bundle, error) {
+
+	fh, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer fh.Close()
+
+	magic := make([]byte, len(zip_magic))
+	_, err = io.ReadFull(fh, magic)
+
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+
+	format := FORMAT_TAR
+
+	if bytes.Equal(magic, zip_magic) {
+		format = FORMAT_ZIP
+	}
+
+	b := &Bundle{
+		path:   path,
+		format: format,
+	}
+
+	return b, nil
+}
+
+func (b *Bundle) Format() string {
+	return b.format
+}
+
+// Iterate decodes every GeoJSON entry (files ending in ".geojson" optionally
+// followed by ".gz" or ".bz2") in the bundle, using feature.LoadFeature, and
+// passes it to cb. Iteration stops at the first error.
+
+func (b *Bundle) Iterate(ctx context.Context, cb BundleFunc) error {
+
+	return b.walk(ctx, isGeoJSONEntry, cb)
+}
+
+// LoadFeatureById returns the feature whose in-archive path ends with the
+// relative path derived from id and args by uri.Id2RelPath. It returns
+// ErrNotFound if there is no such entry.
+
+func (b *Bundle) LoadFeatureById(ctx context.Context, id int64, args ...*uri.URIArgs) (geojson.Feature, error) {
+
+	rel_path, err := uri.Id2RelPath(id, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	match := func(name string) bool {
+
+		name = feature.StripCompressionExtension(name)
+		return name == rel_path || strings.HasSuffix(name, "/"+rel_path)
+	}
+
+	var found geojson.Feature
+
+	cb := func(ctx context.Context, path string, f geojson.Feature) error {
+		found = f
+		return io.EOF
+	}
+
+	err = b.walk(ctx, match, cb)
+
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	if found == nil {
+		return nil, ErrNotFound
+	}
+
+	return found, nil
+}
+
+func (b *Bundle) walk(ctx context.Context, match func(string) bool, cb BundleFunc) error {
+
+	switch b.format {
+	case FORMAT_ZIP:
+		return b.walkZip(ctx, match, cb)
+	default:
+		return b.walkTar(ctx, match, cb)
+	}
+}
+
+func (b *Bundle) walkTar(ctx context.Context, match func(string) bool, cb BundleFunc) error {
+
+	fh, err := os.Open(b.path)
+
+	if err != nil {
+		return err
+	}
+
+	defer fh.Close()
+
+	r, err := feature.NewDecompressingReader(fh, "")
+
+	if err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(r)
+
+	for {
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		hdr, err := tr.Next()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if hdr.Typeflag != tar.TypeReg || !match(hdr.Name) {
+			continue
+		}
+
+		err = b.loadEntry(ctx, hdr.Name, tr, cb)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *Bundle) walkZip(ctx context.Context, match func(string) bool, cb BundleFunc) error {
+
+	zr, err := zip.OpenReader(b.path)
+
+	if err != nil {
+		return err
+	}
+
+	defer zr.Close()
+
+	for _, zf := range zr.File {
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if zf.FileInfo().IsDir() || !match(zf.Name) {
+			continue
+		}
+
+		fh, err := zf.Open()
+
+		if err != nil {
+			return err
+		}
+
+		err = b.loadEntry(ctx, zf.Name, fh, cb)
+		fh.Close()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *Bundle) loadEntry(ctx context.Context, name string, fh io.Reader, cb BundleFunc) error {
+
+	r, err := feature.NewDecompressingReader(fh, name)
+
+	if err != nil {
+		return fmt.Errorf("Failed to read %s, %w", name, err)
+	}
+
+	f, err := feature.LoadFeatureFromReader(r)
+
+	if err != nil && !(f != nil && geojson.IsWarning(err)) {
+		return fmt.Errorf("Failed to load %s, %w", name, err)
+	}
+
+	return cb(ctx, name, f)
+}
+
+func isGeoJSONEntry(name string) bool {
+
+	name = feature.StripCompressionExtension(name)
+	return path.Ext(name) == ".geojson"
+}
@@ -4,29 +4,52 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2/feature"
 	"log"
+	"os"
 )
 
 func main() {
 
 	flag.Parse()
 
-	for _, path := range flag.Args() {
+	to_spr := func(f geojson.Feature) {
 
-		f, err := feature.LoadFeatureFromFile(path)
+		s, err := f.SPR()
 
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		s, err := f.SPR()
+		body, err := json.Marshal(s)
+		fmt.Println(string(body))
+	}
+
+	for _, path := range flag.Args() {
+
+		if path == "-" {
+
+			seq := feature.NewFeatureSequenceReader(os.Stdin)
+
+			err := seq.Walk(func(f geojson.Feature) error {
+				to_spr(f)
+				return nil
+			})
+
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			continue
+		}
+
+		f, err := feature.LoadFeatureFromFile(path)
 
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		body, err := json.Marshal(s)
-		fmt.Println(string(body))
+		to_spr(f)
 	}
 }
@@ -3,11 +3,13 @@ package main
 import (
 	"flag"
 	"fmt"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2/feature"
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2/properties/geometry"
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2/properties/whosonfirst"
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2/utils"
 	"log"
+	"os"
 )
 
 func main() {
@@ -17,15 +19,9 @@ func main() {
 	flag.Parse()
 	args := flag.Args()
 
-	for _, path := range args {
-
-		f, err := feature.LoadFeatureFromFile(path)
-
-		if err != nil {
-			log.Fatal(err)
-		}
+	dump := func(label string, f geojson.Feature) {
 
-		fmt.Printf("# %s\n", path)
+		fmt.Printf("# %s\n", label)
 
 		fmt.Printf("ID is %s\n", f.Id())
 		fmt.Printf("WOF ID is %d\n", whosonfirst.Id(f))
@@ -44,11 +40,11 @@ func main() {
 
 		fmt.Printf("IS ALT %t\n", whosonfirst.IsAlt(f))
 
-		wof, err := feature.LoadWOFFeatureFromFile(path)
+		wof, err := feature.NewWOFFeature(f.Bytes())
 
 		if err != nil {
 
-			alt, alt_err := feature.LoadWOFAltFeatureFromFile(path)
+			alt, alt_err := feature.NewWOFAltFeature(f.Bytes())
 
 			if alt_err != nil {
 				log.Fatal(alt_err, err)
@@ -72,4 +68,31 @@ func main() {
 		}
 	}
 
+	for _, path := range args {
+
+		if path == "-" {
+
+			seq := feature.NewFeatureSequenceReader(os.Stdin)
+
+			err := seq.Walk(func(f geojson.Feature) error {
+				dump(fmt.Sprintf("%s#%d", path, seq.Index()), f)
+				return nil
+			})
+
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			continue
+		}
+
+		f, err := feature.LoadFeatureFromFile(path)
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		dump(path, f)
+	}
+
 }
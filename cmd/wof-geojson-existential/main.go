@@ -2,10 +2,12 @@ package main
 
 import (
 	"flag"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2/feature"
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2/properties/whosonfirst"
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2/utils"
 	"log"
+	"os"
 )
 
 func main() {
@@ -13,13 +15,7 @@ func main() {
 	flag.Parse()
 	args := flag.Args()
 
-	for _, path := range args {
-
-		f, err := feature.LoadWOFFeatureFromFile(path)
-
-		if err != nil {
-			log.Fatal(err)
-		}
+	existential := func(f geojson.Feature) {
 
 		is_current, _ := whosonfirst.IsCurrent(f)
 		is_current_raw := utils.StringProperty(f.Bytes(), []string{"properties.mz:is_current"}, "")
@@ -45,7 +41,40 @@ func main() {
 		is_superseding_raw := utils.StringProperty(f.Bytes(), []string{"properties.wof:supersedes"}, "")
 
 		log.Printf("is superseding:%s raw:%s\n", is_superseding, is_superseding_raw)
+	}
+
+	for _, path := range args {
+
+		if path == "-" {
+
+			seq := feature.NewFeatureSequenceReader(os.Stdin)
+
+			err := seq.WalkBytes(func(body []byte) error {
+
+				f, err := feature.NewWOFFeature(body)
+
+				if err != nil {
+					return err
+				}
+
+				existential(f)
+				return nil
+			})
+
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			continue
+		}
+
+		f, err := feature.LoadWOFFeatureFromFile(path)
+
+		if err != nil {
+			log.Fatal(err)
+		}
 
+		existential(f)
 	}
 
 }
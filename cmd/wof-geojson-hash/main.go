@@ -3,11 +3,13 @@ package main
 import (
 	"flag"
 	"fmt"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2/feature"
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2/properties/geometry"
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2/utils"
 	"github.com/whosonfirst/go-whosonfirst-hash"
 	"log"
+	"os"
 )
 
 func main() {
@@ -15,49 +17,90 @@ func main() {
 	flag.Parse()
 	args := flag.Args()
 
-	for _, path := range args {
+	h, err := hash.NewWOFHash()
 
-		f, err := feature.LoadWOFFeatureFromFile(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	hash_feature := func(f geojson.Feature) {
+
+		feature_hash, err := utils.HashFeature(f)
 
 		if err != nil {
-			log.Fatal(err)
+			fmt.Printf("failed to generate feature hash because %s\n", err)
+		} else {
+			fmt.Printf("feature hash is %s\n", feature_hash)
 		}
 
-		h, err := hash.NewWOFHash()
+		str_geom, err := geometry.ToString(f)
 
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		file_hash, err := h.HashFile(path)
+		geom_hash, err := utils.HashGeometry([]byte(str_geom))
 
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		fmt.Printf("file hash is %s\n", file_hash)
+		fmt.Printf("geometry hash is %s\n", geom_hash)
+	}
 
-		feature_hash, err := utils.HashFeature(f)
+	for _, path := range args {
 
-		if err != nil {
-			fmt.Printf("failed to generate feature hash because %s\n", err)
-		} else {
-			fmt.Printf("feature hash is %s\n", feature_hash)
+		if path == "-" {
+
+			seq := feature.NewFeatureSequenceReader(os.Stdin)
+
+			err := seq.WalkBytes(func(body []byte) error {
+
+				f, err := feature.NewWOFFeature(body)
+
+				if err != nil {
+					return err
+				}
+
+				// there is no file to hash so hash the record instead
+
+				record_hash, err := h.HashBytes(body)
+
+				if err != nil {
+					return err
+				}
+
+				fmt.Printf("record hash is %s\n", record_hash)
+
+				hash_feature(f)
+				return nil
+			})
+
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			continue
 		}
 
-		str_geom, err := geometry.ToString(f)
+		f, err := feature.LoadWOFFeatureFromFile(path)
 
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		geom_hash, err := utils.HashGeometry([]byte(str_geom))
+		// hash the body of the feature rather than the file itself since
+		// the file may be compressed
+
+		file_hash, err := h.HashBytes(f.Bytes())
 
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		fmt.Printf("geometry hash is %s\n", geom_hash)
+		fmt.Printf("file hash is %s\n", file_hash)
+
+		hash_feature(f)
 	}
 
 }
@@ -28,9 +28,12 @@ package main
 
 import (
 	"flag"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2/feature"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/geometry"
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2/utils"
 	"log"
+	"os"
 	"strconv"
 	"strings"
 )
@@ -40,6 +43,8 @@ func main() {
 	var lat = flag.Float64("latitude", 0.0, "...")
 	var lon = flag.Float64("longitude", 0.0, "...")
 	var point = flag.String("point", "", "")
+	point_tolerance := flag.Float64("point-tolerance", geometry.DEFAULT_POINT_TOLERANCE, "The distance, in metres, within which a coordinate intersects a point")
+	line_tolerance := flag.Float64("line-tolerance", geometry.DEFAULT_LINE_TOLERANCE, "The distance, in metres, within which a coordinate intersects a line")
 	verbose := flag.Bool("verbose", false, "...")
 
 	flag.Parse()
@@ -71,30 +76,33 @@ func main() {
 		*lon = fl_lon
 	}
 
-	for _, path := range flag.Args() {
+	coord, err := utils.NewCoordinateFromLatLons(*lat, *lon)
 
-		f, err := feature.LoadWOFFeatureFromFile(path)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-		if err != nil {
-			log.Fatal(err)
-		}
+	opts := &geometry.ContainsCoordOptions{
+		PointTolerance: *point_tolerance,
+		LineTolerance:  *line_tolerance,
+	}
 
-		coord, err := utils.NewCoordinateFromLatLons(*lat, *lon)
+	intersects := func(label string, f geojson.Feature) {
 
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		contained, err := f.ContainsCoord(coord)
+		rsp, err := geometry.FeatureContainsCoordWithOptions(f, coord, opts)
 
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		log.Printf("%s %t\n", path, contained)
+		log.Printf("%s %t\n", label, rsp.Contains)
 
 		if !*verbose {
-			continue
+			return
+		}
+
+		if rsp.Contains {
+			log.Printf("%s matched %s %d (%f metres)\n", label, rsp.Type, rsp.Index, rsp.Distance)
 		}
 
 		polys, err := f.Polygons()
@@ -107,12 +115,42 @@ func main() {
 
 			poly_contained := p.ContainsCoord(coord)
 
+			log.Printf("%s %d %t\n", label, i, poly_contained)
+		}
+	}
+
+	for _, path := range flag.Args() {
+
+		if path == "-" {
+
+			seq := feature.NewFeatureSequenceReader(os.Stdin)
+
+			err := seq.WalkBytes(func(body []byte) error {
+
+				f, err := feature.NewWOFFeature(body)
+
+				if err != nil {
+					return err
+				}
+
+				intersects(f.Id(), f)
+				return nil
+			})
+
 			if err != nil {
 				log.Fatal(err)
 			}
 
-			log.Printf("%s %d %t\n", path, i, poly_contained)
+			continue
+		}
+
+		f, err := feature.LoadWOFFeatureFromFile(path)
+
+		if err != nil {
+			log.Fatal(err)
 		}
+
+		intersects(path, f)
 	}
 
 }
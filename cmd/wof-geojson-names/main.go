@@ -2,9 +2,11 @@ package main
 
 import (
 	"flag"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2/feature"
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2/properties/whosonfirst"
 	"log"
+	"os"
 )
 
 func main() {
@@ -12,13 +14,7 @@ func main() {
 	flag.Parse()
 	args := flag.Args()
 
-	for _, path := range args {
-
-		f, err := feature.LoadWOFFeatureFromFile(path)
-
-		if err != nil {
-			log.Fatal(err)
-		}
+	names := func(f geojson.Feature) {
 
 		names_map := whosonfirst.Names(f)
 
@@ -30,4 +26,38 @@ func main() {
 			}
 		}
 	}
+
+	for _, path := range args {
+
+		if path == "-" {
+
+			seq := feature.NewFeatureSequenceReader(os.Stdin)
+
+			err := seq.WalkBytes(func(body []byte) error {
+
+				f, err := feature.NewWOFFeature(body)
+
+				if err != nil {
+					return err
+				}
+
+				names(f)
+				return nil
+			})
+
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			continue
+		}
+
+		f, err := feature.LoadWOFFeatureFromFile(path)
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		names(f)
+	}
 }
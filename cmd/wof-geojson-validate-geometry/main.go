@@ -0,0 +1,79 @@
+package main
+
+/*
+
+./bin/wof-geojson-validate-geometry -strict /usr/local/data/whosonfirst-data/data/856/330/41/85633041.geojson
+
+Prints each problem found (the path, severity, gjson path of the offending value and a description)
+and exits with a non-zero status if any geometry has errors, or warnings when -strict is set.
+
+*/
+
+import (
+	"flag"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/feature"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/geometry"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/utils"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/validation"
+	"log"
+	"os"
+)
+
+func main() {
+
+	strict := flag.Bool("strict", false, "Treat warnings, like rings that don't follow the right-hand rule, as errors")
+	quiet := flag.Bool("quiet", false, "Only report errors")
+
+	flag.Parse()
+
+	failed := false
+
+	validate := func(label string, body []byte) {
+
+		report := geometry.ValidateGeometry(body)
+
+		for _, f := range report.Findings {
+
+			if *quiet && f.Severity == validation.SEVERITY_WARNING {
+				continue
+			}
+
+			log.Printf("%s %s %s %v\n", label, f.Severity, f.Path, f.Err)
+		}
+
+		if report.HasErrors() || (*strict && report.HasWarnings()) {
+			failed = true
+		}
+	}
+
+	for _, path := range flag.Args() {
+
+		if path == "-" {
+
+			seq := feature.NewFeatureSequenceReader(os.Stdin)
+
+			err := seq.WalkBytes(func(body []byte) error {
+				validate(utils.FeatureId(body), body)
+				return nil
+			})
+
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			continue
+		}
+
+		body, err := feature.UnmarshalFeatureFromFile(path)
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		validate(path, body)
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
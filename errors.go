@@ -0,0 +1,146 @@
+package geojson
+
+import (
+	"errors"
+	"fmt"
+	"github.com/whosonfirst/warning"
+)
+
+// Sentinel errors for use with errors.Is. Each of the error types below
+// reports itself as matching the corresponding sentinel.
+
+var ErrMissingProperty = errors.New("Missing property")
+var ErrInvalidJSON = errors.New("Invalid JSON")
+var ErrInvalidGeometry = errors.New("Invalid geometry")
+var ErrInvalidPlacetype = errors.New("Invalid placetype")
+var ErrInvalidProperty = errors.New("Invalid property")
+
+type MissingPropertyError struct {
+	Path      string
+	FeatureId string
+}
+
+func (e *MissingPropertyError) Error() string {
+	return fmt.Sprintf("Feature is missing a %s property", e.Path)
+}
+
+func (e *MissingPropertyError) Is(target error) bool {
+	return target == ErrMissingProperty
+}
+
+type InvalidJSONError struct {
+	// The byte offset at which the error was detected or -1 if unknown
+	Offset    int64
+	FeatureId string
+	Err       error
+}
+
+func (e *InvalidJSONError) Error() string {
+
+	if e.Offset < 0 {
+		return fmt.Sprintf("Invalid JSON, %v", e.Err)
+	}
+
+	return fmt.Sprintf("Invalid JSON at offset %d, %v", e.Offset, e.Err)
+}
+
+func (e *InvalidJSONError) Is(target error) bool {
+	return target == ErrInvalidJSON
+}
+
+func (e *InvalidJSONError) Unwrap() error {
+	return e.Err
+}
+
+type InvalidGeometryError struct {
+	Path      string
+	FeatureId string
+	Message   string
+	Err       error
+}
+
+func (e *InvalidGeometryError) Error() string {
+
+	if e.Err != nil {
+		return fmt.Sprintf("%s, %v", e.Message, e.Err)
+	}
+
+	return e.Message
+}
+
+func (e *InvalidGeometryError) Is(target error) bool {
+	return target == ErrInvalidGeometry
+}
+
+func (e *InvalidGeometryError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidPlacetypeWarning is returned, alongside a usable feature, by
+// constructors like feature.NewWOFFeature when a document's placetype is not
+// defined by go-whosonfirst-placetypes. Use IsWarning rather than
+// warning.IsWarning to test for it.
+
+type InvalidPlacetypeWarning struct {
+	Path      string
+	Placetype string
+	FeatureId string
+}
+
+func (e *InvalidPlacetypeWarning) Error() string {
+	return fmt.Sprintf("Invalid %s '%s'", e.Path, e.Placetype)
+}
+
+func (e *InvalidPlacetypeWarning) Is(target error) bool {
+	return target == ErrInvalidPlacetype
+}
+
+func (e *InvalidPlacetypeWarning) IsWarning() bool {
+	return true
+}
+
+// IsWarning reports whether err, or any error it wraps, is a warning. This
+// includes warning.Warning values as well as errors, like
+// InvalidPlacetypeWarning, that have an IsWarning method returning true.
+
+func IsWarning(err error) bool {
+
+	if err == nil {
+		return false
+	}
+
+	if warning.IsWarning(err) {
+		return true
+	}
+
+	var w interface {
+		IsWarning() bool
+	}
+
+	if errors.As(err, &w) {
+		return w.IsWarning()
+	}
+
+	return false
+}
+
+// InvalidPropertyError is returned when a property exists but its value does
+// not have the expected type or shape.
+
+type InvalidPropertyError struct {
+	Path      string
+	FeatureId string
+	Err       error
+}
+
+func (e *InvalidPropertyError) Error() string {
+	return fmt.Sprintf("Invalid %s property, %v", e.Path, e.Err)
+}
+
+func (e *InvalidPropertyError) Is(target error) bool {
+	return target == ErrInvalidProperty
+}
+
+func (e *InvalidPropertyError) Unwrap() error {
+	return e.Err
+}
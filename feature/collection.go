@@ -0,0 +1,262 @@
+package feature
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"io"
+)
+
+// FeatureCollection reads the members of a GeoJSON FeatureCollection one at a
+// time rather than decoding the entire document in to memory. Members that
+// appear after the "features" array (like a trailing "bbox") are only known once
+// Next has returned io.EOF.
+
+type FeatureCollection struct {
+	decoder *json.Decoder
+	closer  io.Closer
+	bbox    []float64
+	foreign map[string]json.RawMessage
+	index   int
+	started bool
+	done    bool
+	err     error
+}
+
+type FeatureCollectionError struct {
+	Index int
+	Err   error
+}
+
+func (e *FeatureCollectionError) Error() string {
+	return fmt.Sprintf("Failed to load feature at index %d, %v", e.Index, e.Err)
+}
+
+func (e *FeatureCollectionError) Unwrap() error {
+	return e.Err
+}
+
+func LoadFeatureCollectionFromReader(fh io.Reader) (*FeatureCollection, error) {
+
+	c := &FeatureCollection{
+		decoder: json.NewDecoder(fh),
+		foreign: make(map[string]json.RawMessage),
+		index:   -1,
+	}
+
+	err := c.expectDelim('{')
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.readMembers()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.started {
+		return nil, errors.New("FeatureCollection is missing a features property")
+	}
+
+	return c, nil
+}
+
+// LoadFeatureCollectionFromFile opens path, which may be gzip or bzip2
+// compressed, and returns a FeatureCollection that reads from it. The file is
+// closed when the collection's Close method is called.
+
+func LoadFeatureCollectionFromFile(path string) (*FeatureCollection, error) {
+
+	fh, err := OpenFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := LoadFeatureCollectionFromReader(fh)
+
+	if err != nil {
+		fh.Close()
+		return nil, err
+	}
+
+	c.closer = fh
+	return c, nil
+}
+
+// Next returns the next feature in the collection or io.EOF once all the
+// features have been read. Errors loading an individual feature are returned
+// as a *FeatureCollectionError and do not prevent subsequent calls to Next;
+// errors parsing the collection itself are permanent.
+
+func (c *FeatureCollection) Next() (geojson.Feature, error) {
+
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	if c.done {
+		return nil, io.EOF
+	}
+
+	if !c.decoder.More() {
+
+		err := c.expectDelim(']')
+
+		if err == nil {
+			err = c.readMembers()
+		}
+
+		if err != nil {
+			c.err = err
+			return nil, err
+		}
+
+		c.done = true
+		return nil, io.EOF
+	}
+
+	var raw json.RawMessage
+	err := c.decoder.Decode(&raw)
+
+	c.index += 1
+
+	if err != nil {
+
+		syntax_err, ok := err.(*json.SyntaxError)
+
+		if ok {
+			err = &geojson.InvalidJSONError{Offset: syntax_err.Offset, Err: err}
+		}
+
+		c.err = &FeatureCollectionError{Index: c.index, Err: err}
+		return nil, c.err
+	}
+
+	body, err := UnmarshalFeature(raw)
+
+	if err != nil {
+		return nil, &FeatureCollectionError{Index: c.index, Err: err}
+	}
+
+	f, err := LoadFeature(body)
+
+	if err != nil {
+		return f, &FeatureCollectionError{Index: c.index, Err: err}
+	}
+
+	return f, nil
+}
+
+// Index returns the index of the feature most recently returned by Next.
+
+func (c *FeatureCollection) Index() int {
+	return c.index
+}
+
+func (c *FeatureCollection) BBox() []float64 {
+	return c.bbox
+}
+
+func (c *FeatureCollection) ForeignMembers() map[string]json.RawMessage {
+	return c.foreign
+}
+
+func (c *FeatureCollection) Close() error {
+
+	if c.closer == nil {
+		return nil
+	}
+
+	return c.closer.Close()
+}
+
+// readMembers consumes top-level members until it reaches either the start of
+// the "features" array or the end of the collection.
+
+func (c *FeatureCollection) readMembers() error {
+
+	for c.decoder.More() {
+
+		t, err := c.decoder.Token()
+
+		if err != nil {
+			return err
+		}
+
+		key, ok := t.(string)
+
+		if !ok {
+			return fmt.Errorf("Unexpected token in FeatureCollection: %v", t)
+		}
+
+		switch key {
+		case "type":
+
+			var str_type string
+			err := c.decoder.Decode(&str_type)
+
+			if err != nil {
+				return err
+			}
+
+			if str_type != "FeatureCollection" {
+				return fmt.Errorf("Invalid type '%s', expected FeatureCollection", str_type)
+			}
+
+		case "bbox":
+
+			err := c.decoder.Decode(&c.bbox)
+
+			if err != nil {
+				return err
+			}
+
+		case "features":
+
+			if c.started {
+				return errors.New("FeatureCollection has more than one features property")
+			}
+
+			err := c.expectDelim('[')
+
+			if err != nil {
+				return err
+			}
+
+			c.started = true
+			return nil
+
+		default:
+
+			var raw json.RawMessage
+			err := c.decoder.Decode(&raw)
+
+			if err != nil {
+				return err
+			}
+
+			c.foreign[key] = raw
+		}
+	}
+
+	return c.expectDelim('}')
+}
+
+func (c *FeatureCollection) expectDelim(d json.Delim) error {
+
+	t, err := c.decoder.Token()
+
+	if err != nil {
+		return err
+	}
+
+	if t != d {
+		return fmt.Errorf("Unexpected token in FeatureCollection: %v, expected %v", t, d)
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package feature
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var gzip_magic = []byte{0x1f, 0x8b}
+var bzip2_magic = []byte("BZh")
+
+type fileReadCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r *fileReadCloser) Close() error {
+
+	var err error
+
+	for i := len(r.closers) - 1; i >= 0; i-- {
+
+		c_err := r.closers[i].Close()
+
+		if c_err != nil && err == nil {
+			err = c_err
+		}
+	}
+
+	return err
+}
+
+// NewDecompressingReader returns a reader that will decompress gzip or bzip2
+// encoded data if either the path (which may be empty) has a ".gz" or ".bz2"
+// extension or the data itself starts with the corresponding magic bytes.
+// Anything else is passed through as-is.
+
+func NewDecompressingReader(fh io.Reader, path string) (io.Reader, error) {
+
+	br := bufio.NewReader(fh)
+
+	// an error here just means there are fewer than 3 bytes to read
+	// which will be dealt with by whatever is trying to parse them
+
+	magic, _ := br.Peek(len(bzip2_magic))
+
+	ext := strings.ToLower(filepath.Ext(path))
+
+	if ext == ".gz" || bytes.HasPrefix(magic, gzip_magic) {
+		return gzip.NewReader(br)
+	}
+
+	if ext == ".bz2" || bytes.HasPrefix(magic, bzip2_magic) {
+		return bzip2.NewReader(br), nil
+	}
+
+	return br, nil
+}
+
+// OpenFile opens path for reading, transparently decompressing it if necessary.
+
+func OpenFile(path string) (io.ReadCloser, error) {
+
+	fh, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := NewDecompressingReader(fh, path)
+
+	if err != nil {
+		fh.Close()
+		return nil, err
+	}
+
+	rc := &fileReadCloser{
+		Reader:  r,
+		closers: []io.Closer{fh},
+	}
+
+	gz, ok := r.(*gzip.Reader)
+
+	if ok {
+		rc.closers = append(rc.closers, gz)
+	}
+
+	return rc, nil
+}
+
+// StripCompressionExtension removes a trailing ".gz" or ".bz2" extension from
+// path, so that "123.geojson.gz" can be treated like "123.geojson".
+
+func StripCompressionExtension(path string) string {
+
+	ext := filepath.Ext(path)
+
+	switch strings.ToLower(ext) {
+	case ".gz", ".bz2":
+		return strings.TrimSuffix(path, ext)
+	default:
+		return path
+	}
+}
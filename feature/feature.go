@@ -1,29 +1,30 @@
 package feature
 
 import (
-	"encoding/json"
+	"errors"
 	"github.com/tidwall/gjson"
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2/utils"
 	"io"
 	"io/ioutil"
-	"os"
 )
 
 // Feature
 
+// LoadFeature creates a new feature using the first registered feature type,
+// in order of priority, whose detect function matches body. See registry.go
+// for details.
+
 func LoadFeature(body []byte) (geojson.Feature, error) {
 
-	is_wof := isWOF(body)
-	is_alt := isAlt(body)
+	for _, t := range FeatureTypes() {
 
-	if is_wof && is_alt {
-		return NewWOFAltFeature(body)
-	} else if is_wof {
-		return NewWOFFeature(body)
-	} else {
-		return NewGeoJSONFeature(body)
+		if t.Detect(body) {
+			return t.New(body)
+		}
 	}
+
+	return nil, errors.New("No registered feature type matches feature")
 }
 
 func LoadFeatureFromReader(fh io.Reader) (geojson.Feature, error) {
@@ -48,6 +49,28 @@ func LoadFeatureFromFile(path string) (geojson.Feature, error) {
 	return LoadFeature(body)
 }
 
+func LoadFeatureAsFromReader(name string, fh io.Reader) (geojson.Feature, error) {
+
+	body, err := UnmarshalFeatureFromReader(fh)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadFeatureAs(name, body)
+}
+
+func LoadFeatureAsFromFile(name string, path string) (geojson.Feature, error) {
+
+	body, err := UnmarshalFeatureFromFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadFeatureAs(name, body)
+}
+
 // WOF
 
 func LoadWOFFeatureFromReader(fh io.Reader) (geojson.Feature, error) {
@@ -120,18 +143,15 @@ func LoadGeoJSONFeatureFromFile(path string) (geojson.Feature, error) {
 
 func UnmarshalFeature(body []byte) ([]byte, error) {
 
-	var stub interface{}
-	err := json.Unmarshal(body, &stub)
+	err := utils.EnsureJSON(body)
 
 	if err != nil {
 		return nil, err
 	}
 
 	all := []string{
-		"geometry",
-		"geometry.type",
-		"geometry.coordinates",
 		"type",
+		"geometry",
 	}
 
 	err = utils.EnsureProperties(body, all)
@@ -140,6 +160,34 @@ func UnmarshalFeature(body []byte) ([]byte, error) {
 		return nil, err
 	}
 
+	// RFC 7946 allows a feature's geometry to be null
+
+	geom_rsp := gjson.GetBytes(body, "geometry")
+
+	if geom_rsp.Type == gjson.Null {
+		return body, nil
+	}
+
+	err = utils.EnsureProperties(body, []string{"geometry.type"})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// GeometryCollections have "geometries" rather than "coordinates"
+
+	members := "geometry.coordinates"
+
+	if gjson.GetBytes(body, "geometry.type").String() == "GeometryCollection" {
+		members = "geometry.geometries"
+	}
+
+	err = utils.EnsureProperties(body, []string{members})
+
+	if err != nil {
+		return nil, err
+	}
+
 	return body, nil
 }
 
@@ -156,7 +204,7 @@ func UnmarshalFeatureFromReader(fh io.Reader) ([]byte, error) {
 
 func UnmarshalFeatureFromFile(path string) ([]byte, error) {
 
-	fh, err := os.Open(path)
+	fh, err := OpenFile(path)
 
 	if err != nil {
 		return nil, err
@@ -166,13 +214,3 @@ func UnmarshalFeatureFromFile(path string) ([]byte, error) {
 
 	return UnmarshalFeatureFromReader(fh)
 }
-
-func isWOF(body []byte) bool {
-	wofid := gjson.GetBytes(body, "properties.wof:id")
-	return wofid.Exists()
-}
-
-func isAlt(body []byte) bool {
-	alt_label := gjson.GetBytes(body, "properties.src:alt_label")
-	return alt_label.Exists()
-}
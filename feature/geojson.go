@@ -15,7 +15,8 @@ import (
 
 type GeoJSONFeature struct {
 	geojson.Feature
-	body []byte
+	body  []byte
+	cache *geometry.GeometryCache
 }
 
 type GeoJSONStandardPlacesResult struct {
@@ -33,15 +34,15 @@ type GeoJSONStandardPlacesResult struct {
 
 func NewGeoJSONFeature(body []byte) (geojson.Feature, error) {
 
-	var stub interface{}
-	err := json.Unmarshal(body, &stub)
+	err := utils.EnsureJSON(body)
 
 	if err != nil {
 		return nil, err
 	}
 
 	f := GeoJSONFeature{
-		body: body,
+		body:  body,
+		cache: geometry.NewGeometryCache(),
 	}
 
 	return &f, nil
@@ -49,7 +50,7 @@ func NewGeoJSONFeature(body []byte) (geojson.Feature, error) {
 
 func (f *GeoJSONFeature) ContainsCoord(c geom.Coord) (bool, error) {
 
-	return geometry.FeatureContainsCoord(f, c)
+	return f.cache.ContainsCoord(f, c)
 }
 
 func (f *GeoJSONFeature) String() string {
@@ -127,11 +128,32 @@ func (f *GeoJSONFeature) uid() string {
 }
 
 func (f *GeoJSONFeature) BoundingBoxes() (geojson.BoundingBoxes, error) {
-	return geometry.BoundingBoxesForFeature(f)
+	return f.cache.BoundingBoxes(f)
 }
 
 func (f *GeoJSONFeature) Polygons() ([]geojson.Polygon, error) {
-	return geometry.PolygonsForFeature(f)
+	return f.cache.Polygons(f)
+}
+
+// Members returns each of the individual points, lines and polygons in the
+// feature's geometry. See geometry.MembersForGeometry for details.
+
+func (f *GeoJSONFeature) Members() ([]geojson.Geometry, error) {
+	return f.cache.Members(f)
+}
+
+// ContainsCoordWithOptions is like ContainsCoord but allows the tolerances for
+// points and lines to be specified and reports which member matched.
+
+func (f *GeoJSONFeature) ContainsCoordWithOptions(c geom.Coord, opts *geometry.ContainsCoordOptions) (*geometry.ContainsCoordResult, error) {
+	return f.cache.ContainsCoordWithOptions(f, c, opts)
+}
+
+// DropGeometryCache discards the feature's parsed geometry, polygons and
+// bounding boxes. They will be re-parsed the next time they are needed.
+
+func (f *GeoJSONFeature) DropGeometryCache() {
+	f.cache.Drop()
 }
 
 func (f *GeoJSONFeature) SPR() (spr.StandardPlacesResult, error) {
@@ -144,8 +166,13 @@ func (f *GeoJSONFeature) SPR() (spr.StandardPlacesResult, error) {
 
 	mbr := bboxes.MBR()
 
-	lat := mbr.Min.Y + ((mbr.Max.Y - mbr.Min.Y) / 2.0)
-	lon := mbr.Min.X + ((mbr.Max.X - mbr.Min.X) / 2.0)
+	// mbr.Min.X will be greater than mbr.Max.X if the feature crosses
+	// the antimeridian which RectCenter accounts for
+
+	center := utils.RectCenter(mbr)
+
+	lat := center.Y
+	lon := center.X
 
 	spr := GeoJSONStandardPlacesResult{
 		SPRId:           f.Id(),
@@ -219,7 +246,7 @@ func (spr *GeoJSONStandardPlacesResult) MinLongitude() float64 {
 }
 
 func (spr *GeoJSONStandardPlacesResult) MaxLatitude() float64 {
-	return spr.SPRLatitude
+	return spr.SPRMaxLatitude
 }
 
 func (spr *GeoJSONStandardPlacesResult) MaxLongitude() float64 {
@@ -0,0 +1,172 @@
+package feature
+
+import (
+	"errors"
+	"fmt"
+	"github.com/tidwall/gjson"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"sort"
+	"sync"
+)
+
+// DetectFeatureFunc reports whether body is a document that a registered
+// feature type knows how to handle.
+
+type DetectFeatureFunc func(body []byte) bool
+
+// NewFeatureFunc creates a new geojson.Feature instance from body.
+
+type NewFeatureFunc func(body []byte) (geojson.Feature, error)
+
+type FeatureType struct {
+	Name     string
+	Priority int
+	Detect   DetectFeatureFunc
+	New      NewFeatureFunc
+}
+
+const FEATURE_TYPE_WOF_ALT string = "whosonfirst-alt"
+const FEATURE_TYPE_WOF string = "whosonfirst"
+const FEATURE_TYPE_GEOJSON string = "geojson"
+
+var registry_mu = new(sync.RWMutex)
+var registry map[string]*FeatureType
+
+func init() {
+
+	registry = make(map[string]*FeatureType)
+
+	// higher priority feature types are tested first, plain GeoJSON
+	// is the catch-all so it has the lowest priority
+
+	builtin := []*FeatureType{
+		&FeatureType{
+			Name:     FEATURE_TYPE_WOF_ALT,
+			Priority: 200,
+			Detect:   isWOFAlt,
+			New:      NewWOFAltFeature,
+		},
+		&FeatureType{
+			Name:     FEATURE_TYPE_WOF,
+			Priority: 100,
+			Detect:   isWOF,
+			New:      NewWOFFeature,
+		},
+		&FeatureType{
+			Name:     FEATURE_TYPE_GEOJSON,
+			Priority: 0,
+			Detect:   isGeoJSON,
+			New:      NewGeoJSONFeature,
+		},
+	}
+
+	for _, t := range builtin {
+
+		err := RegisterFeatureType(t)
+
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterFeatureType adds t to the list of feature types that LoadFeature
+// will try. It is an error to register the same name twice.
+
+func RegisterFeatureType(t *FeatureType) error {
+
+	if t.Name == "" {
+		return errors.New("Feature type is missing a name")
+	}
+
+	if t.Detect == nil || t.New == nil {
+		return fmt.Errorf("Feature type '%s' is missing a detect or constructor function", t.Name)
+	}
+
+	registry_mu.Lock()
+	defer registry_mu.Unlock()
+
+	_, exists := registry[t.Name]
+
+	if exists {
+		return fmt.Errorf("Feature type '%s' is already registered", t.Name)
+	}
+
+	registry[t.Name] = t
+	return nil
+}
+
+func UnregisterFeatureType(name string) error {
+
+	registry_mu.Lock()
+	defer registry_mu.Unlock()
+
+	_, exists := registry[name]
+
+	if !exists {
+		return fmt.Errorf("Feature type '%s' is not registered", name)
+	}
+
+	delete(registry, name)
+	return nil
+}
+
+// FeatureTypes returns the registered feature types in the order they are
+// tested by LoadFeature: highest priority first and then by name.
+
+func FeatureTypes() []*FeatureType {
+
+	registry_mu.RLock()
+	defer registry_mu.RUnlock()
+
+	types := make([]*FeatureType, 0, len(registry))
+
+	for _, t := range registry {
+		types = append(types, t)
+	}
+
+	sort.Slice(types, func(i, j int) bool {
+
+		if types[i].Priority != types[j].Priority {
+			return types[i].Priority > types[j].Priority
+		}
+
+		return types[i].Name < types[j].Name
+	})
+
+	return types
+}
+
+// LoadFeatureAs skips detection and creates a feature using the registered
+// feature type called name.
+
+func LoadFeatureAs(name string, body []byte) (geojson.Feature, error) {
+
+	registry_mu.RLock()
+	t, exists := registry[name]
+	registry_mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("Feature type '%s' is not registered", name)
+	}
+
+	return t.New(body)
+}
+
+func isWOF(body []byte) bool {
+	wofid := gjson.GetBytes(body, "properties.wof:id")
+	return wofid.Exists()
+}
+
+func isAlt(body []byte) bool {
+	alt_label := gjson.GetBytes(body, "properties.src:alt_label")
+	return alt_label.Exists()
+}
+
+func isWOFAlt(body []byte) bool {
+	return isWOF(body) && isAlt(body)
+}
+
+func isGeoJSON(body []byte) bool {
+	return true
+}
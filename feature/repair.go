@@ -0,0 +1,31 @@
+package feature
+
+import (
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/geometry"
+)
+
+// RepairFeature returns a new feature, of the same type as f, whose geometry
+// has been repaired by geometry.RepairGeometry along with a log of the changes
+// that were made. If nothing needed to be changed f itself is returned.
+
+func RepairFeature(f geojson.Feature) (geojson.Feature, []*geometry.RepairAction, error) {
+
+	g, actions, err := geometry.RepairFeatureGeometry(f)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(actions) == 0 {
+		return f, actions, nil
+	}
+
+	repaired_f, err := replaceGeometry(f, g)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return repaired_f, actions, nil
+}
@@ -0,0 +1,250 @@
+package feature
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"io"
+)
+
+// https://tools.ietf.org/html/rfc8142
+// https://tools.ietf.org/html/rfc7464
+
+const RECORD_SEPARATOR byte = 0x1e
+
+type FeatureSequenceError struct {
+	Index int
+	Err   error
+}
+
+func (e *FeatureSequenceError) Error() string {
+	return fmt.Sprintf("Failed to load feature at index %d, %v", e.Index, e.Err)
+}
+
+func (e *FeatureSequenceError) Unwrap() error {
+	return e.Err
+}
+
+// FeatureSequenceReader reads both RFC 8142 (GeoJSON text sequences, where each
+// record is prefixed by a RS character) and plain newline-delimited streams. The
+// format is determined by the first non-whitespace byte in the stream.
+
+type FeatureSequenceReader struct {
+	reader    *bufio.Reader
+	delimiter byte
+	index     int
+}
+
+func NewFeatureSequenceReader(fh io.Reader) *FeatureSequenceReader {
+
+	r := &FeatureSequenceReader{
+		reader: bufio.NewReader(fh),
+		index:  -1,
+	}
+
+	return r
+}
+
+// Next returns the next feature in the sequence or io.EOF. Errors loading an
+// individual record are returned as a *FeatureSequenceError and do not prevent
+// subsequent calls to Next.
+
+func (r *FeatureSequenceReader) Next() (geojson.Feature, error) {
+
+	body, err := r.NextBytes()
+
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := LoadFeature(body)
+
+	if err != nil {
+		return f, &FeatureSequenceError{Index: r.index, Err: err}
+	}
+
+	return f, nil
+}
+
+// NextBytes returns the body of the next record in the sequence, after it has
+// been validated with UnmarshalFeature, or io.EOF.
+
+func (r *FeatureSequenceReader) NextBytes() ([]byte, error) {
+
+	for {
+
+		record, err := r.readRecord()
+
+		if err != nil {
+			return nil, err
+		}
+
+		record = bytes.TrimSpace(record)
+
+		if len(record) == 0 {
+			continue
+		}
+
+		r.index += 1
+
+		body, err := UnmarshalFeature(record)
+
+		if err != nil {
+			return nil, &FeatureSequenceError{Index: r.index, Err: err}
+		}
+
+		return body, nil
+	}
+}
+
+// Index returns the index of the record most recently returned by Next or NextBytes.
+
+func (r *FeatureSequenceReader) Index() int {
+	return r.index
+}
+
+// FeatureSequenceFunc is invoked by FeatureSequenceReader.Walk for each feature
+// in a sequence.
+
+type FeatureSequenceFunc func(f geojson.Feature) error
+
+// FeatureSequenceBytesFunc is invoked by FeatureSequenceReader.WalkBytes for
+// each record in a sequence.
+
+type FeatureSequenceBytesFunc func(body []byte) error
+
+// Walk invokes cb for each remaining feature in the sequence. Walking stops,
+// and the first error is returned, if a record can not be loaded or cb returns
+// an error. Reaching the end of the sequence is not an error.
+
+func (r *FeatureSequenceReader) Walk(cb FeatureSequenceFunc) error {
+
+	for {
+
+		f, err := r.Next()
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		err = cb(f)
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// WalkBytes is like Walk but invokes cb with the body of each record, as
+// returned by NextBytes.
+
+func (r *FeatureSequenceReader) WalkBytes(cb FeatureSequenceBytesFunc) error {
+
+	for {
+
+		body, err := r.NextBytes()
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		err = cb(body)
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (r *FeatureSequenceReader) readRecord() ([]byte, error) {
+
+	if r.delimiter == 0 {
+
+		for {
+
+			b, err := r.reader.ReadByte()
+
+			if err != nil {
+				return nil, err
+			}
+
+			if b == ' ' || b == '\t' || b == '\r' || b == '\n' {
+				continue
+			}
+
+			if b == RECORD_SEPARATOR {
+				r.delimiter = RECORD_SEPARATOR
+			} else {
+				r.delimiter = '\n'
+				r.reader.UnreadByte()
+			}
+
+			break
+		}
+	}
+
+	record, err := r.reader.ReadBytes(r.delimiter)
+
+	if err == io.EOF && len(record) > 0 {
+		err = nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	record = bytes.TrimRight(record, string(r.delimiter))
+	return record, nil
+}
+
+// FeatureSequenceWriter writes features compacted to a single line. If RFC 8142
+// output is enabled each record is prefixed by a RS character.
+
+type FeatureSequenceWriter struct {
+	writer  io.Writer
+	rfc8142 bool
+}
+
+func NewFeatureSequenceWriter(fh io.Writer, rfc8142 bool) *FeatureSequenceWriter {
+
+	w := &FeatureSequenceWriter{
+		writer:  fh,
+		rfc8142: rfc8142,
+	}
+
+	return w
+}
+
+func (w *FeatureSequenceWriter) WriteFeature(f geojson.Feature) error {
+	return w.WriteBytes(f.Bytes())
+}
+
+func (w *FeatureSequenceWriter) WriteBytes(body []byte) error {
+
+	var buf bytes.Buffer
+
+	if w.rfc8142 {
+		buf.WriteByte(RECORD_SEPARATOR)
+	}
+
+	err := json.Compact(&buf, body)
+
+	if err != nil {
+		return err
+	}
+
+	buf.WriteByte('\n')
+
+	_, err = w.writer.Write(buf.Bytes())
+	return err
+}
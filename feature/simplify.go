@@ -0,0 +1,71 @@
+package feature
+
+import (
+	"encoding/json"
+	"errors"
+	pm_geojson "github.com/paulmach/go.geojson"
+	"github.com/tidwall/gjson"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/geometry"
+)
+
+// SimplifyFeature returns a new feature, of the same type as f, whose geometry
+// has been simplified according to opts. Everything other than the geometry,
+// including the properties, is left untouched.
+
+func SimplifyFeature(f geojson.Feature, opts *geometry.SimplifyOptions) (geojson.Feature, error) {
+
+	g, err := geometry.SimplifyFeatureGeometry(f, opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return replaceGeometry(f, g)
+}
+
+// replaceGeometry returns a new feature with the same body as f except for its
+// geometry, which is replaced by g (or null if g is nil).
+
+func replaceGeometry(f geojson.Feature, g *pm_geojson.Geometry) (geojson.Feature, error) {
+
+	body := f.Bytes()
+
+	geom_rsp := gjson.GetBytes(body, "geometry")
+
+	if !geom_rsp.Exists() || geom_rsp.Index == 0 {
+		return nil, errors.New("Unable to locate feature geometry")
+	}
+
+	enc_geom := []byte("null")
+
+	if g != nil {
+
+		b, err := json.Marshal(g)
+
+		if err != nil {
+			return nil, err
+		}
+
+		enc_geom = b
+	}
+
+	start := geom_rsp.Index
+	end := start + len(geom_rsp.Raw)
+
+	new_body := make([]byte, 0, len(body)-len(geom_rsp.Raw)+len(enc_geom))
+	new_body = append(new_body, body[0:start]...)
+	new_body = append(new_body, enc_geom...)
+	new_body = append(new_body, body[end:]...)
+
+	switch f.(type) {
+	case *WOFFeature:
+		return NewWOFFeature(new_body)
+	case *WOFAltFeature:
+		return NewWOFAltFeature(new_body)
+	case *GeoJSONFeature:
+		return NewGeoJSONFeature(new_body)
+	default:
+		return LoadFeature(new_body)
+	}
+}
@@ -6,22 +6,24 @@ import (
 	"github.com/sfomuseum/go-edtf"
 	"github.com/sfomuseum/go-edtf/parser"
 	"github.com/skelterjohn/geom"
+	"github.com/tidwall/gjson"
 	"github.com/whosonfirst/go-whosonfirst-flags"
 	"github.com/whosonfirst/go-whosonfirst-flags/existential"
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2/geometry"
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2/properties/whosonfirst"
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2/utils"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/validation"
 	"github.com/whosonfirst/go-whosonfirst-placetypes"
 	"github.com/whosonfirst/go-whosonfirst-spr/v2"
 	"github.com/whosonfirst/go-whosonfirst-uri"
-	"github.com/whosonfirst/warning"
 	"strconv"
 )
 
 type WOFFeature struct {
 	geojson.Feature
-	body []byte
+	body  []byte
+	cache *geometry.GeometryCache
 }
 
 type WOFStandardPlacesResult struct {
@@ -53,8 +55,23 @@ type WOFStandardPlacesResult struct {
 	WOFLastModified          int64   `json:"wof:lastmodified"`
 }
 
+// EnsureWOFFeature returns the first problem found by ValidateWOFFeature,
+// preferring errors over warnings, or nil if there are none.
+
 func EnsureWOFFeature(body []byte) error {
 
+	report := ValidateWOFFeature(body)
+	return report.First()
+}
+
+// ValidateWOFFeature checks body for every required Who's On First property,
+// rather than stopping at the first missing one, and returns a report listing
+// all of the problems it found.
+
+func ValidateWOFFeature(body []byte) *validation.Report {
+
+	report := validation.NewReport()
+
 	required := []string{
 		"properties.wof:id",
 		"properties.wof:name",
@@ -68,25 +85,34 @@ func EnsureWOFFeature(body []byte) error {
 		// "properties.geom:bbox",
 	}
 
-	err := utils.EnsureProperties(body, required)
+	for _, path := range required {
 
-	if err != nil {
-		return err
+		err := utils.EnsureProperties(body, []string{path})
+
+		if err != nil {
+			report.AddError(path, err)
+		}
 	}
 
 	// strictly speaking we probably want to ensure all if the spr_geom
 	// properties if we have to test one of them but let's see how this
 	// works first... (20180223/thisisaaronland)
 
-	required_geom := map[string][]string{
-		"properties.geom:latitude":  []string{"properties.mz:latitude"},
-		"properties.geom:longitude": []string{"properties.mz:latitude"},
-		"properties.geom:bbox":      []string{"properties.mz:min_latitude", "properties.mz:min_longitude", "properties.mz:max_latitude", "properties.mz:max_longitude"},
+	// this used to be a map but a slice keeps the order of the findings
+	// in a report stable
+
+	required_geom := [][]string{
+		[]string{"properties.geom:latitude", "properties.mz:latitude"},
+		[]string{"properties.geom:longitude", "properties.mz:longitude"},
+		[]string{"properties.geom:bbox", "properties.mz:min_latitude", "properties.mz:min_longitude", "properties.mz:max_latitude", "properties.mz:max_longitude"},
 	}
 
-	for wof_geom, spr_geom := range required_geom {
+	for _, paths := range required_geom {
 
-		err = utils.EnsureProperties(body, []string{wof_geom})
+		wof_geom := paths[0]
+		spr_geom := paths[1:]
+
+		err := utils.EnsureProperties(body, []string{wof_geom})
 
 		if err == nil {
 			continue
@@ -95,7 +121,7 @@ func EnsureWOFFeature(body []byte) error {
 		err = utils.EnsureProperties(body, spr_geom)
 
 		if err != nil {
-			return err
+			report.AddError(wof_geom, err)
 		}
 	}
 
@@ -111,21 +137,36 @@ func EnsureWOFFeature(body []byte) error {
 	// forked (to the whosonfirst org) version of https://github.com/lunemec/warning
 	// (20180405/thisisaaronland)
 
-	pt := utils.StringProperty(body, []string{"properties.wof:placetype"}, "")
+	// that warning is now a geojson.InvalidPlacetypeWarning so that it can be
+	// inspected with errors.As - use geojson.IsWarning to test for it since
+	// warning.IsWarning only knows about its own Warning type
+
+	pt_rsp := gjson.GetBytes(body, "properties.wof:placetype")
+
+	if pt_rsp.Exists() {
 
-	if !placetypes.IsValidPlacetype(pt) {
-		return warning.New("Invalid wof:placetype")
+		pt := pt_rsp.String()
+
+		if !placetypes.IsValidPlacetype(pt) {
+
+			w := &geojson.InvalidPlacetypeWarning{
+				Path:      "wof:placetype",
+				Placetype: pt,
+				FeatureId: utils.FeatureId(body),
+			}
+
+			report.AddWarning("properties.wof:placetype", w)
+		}
 	}
 
 	// check wof:repo here?
 
-	return nil
+	return report
 }
 
 func NewWOFFeature(body []byte) (geojson.Feature, error) {
 
-	var stub interface{}
-	err := json.Unmarshal(body, &stub)
+	err := utils.EnsureJSON(body)
 
 	if err != nil {
 		return nil, err
@@ -133,15 +174,16 @@ func NewWOFFeature(body []byte) (geojson.Feature, error) {
 
 	err = EnsureWOFFeature(body)
 
-	if err != nil && !warning.IsWarning(err) {
+	if err != nil && !geojson.IsWarning(err) {
 		return nil, err
 	}
 
 	f := WOFFeature{
-		body: body,
+		body:  body,
+		cache: geometry.NewGeometryCache(),
 	}
 
-	// because err might be a warning.Error / see notes above in EnsureWOFFeature
+	// because err might be a geojson.InvalidPlacetypeWarning / see notes above in EnsureWOFFeature
 	// I don't really love this... (20180405/thisisaaronland)
 
 	return &f, err
@@ -176,15 +218,36 @@ func (f *WOFFeature) Placetype() string {
 }
 
 func (f *WOFFeature) BoundingBoxes() (geojson.BoundingBoxes, error) {
-	return geometry.BoundingBoxesForFeature(f)
+	return f.cache.BoundingBoxes(f)
 }
 
 func (f *WOFFeature) Polygons() ([]geojson.Polygon, error) {
-	return geometry.PolygonsForFeature(f)
+	return f.cache.Polygons(f)
+}
+
+// Members returns each of the individual points, lines and polygons in the
+// feature's geometry. See geometry.MembersForGeometry for details.
+
+func (f *WOFFeature) Members() ([]geojson.Geometry, error) {
+	return f.cache.Members(f)
+}
+
+// ContainsCoordWithOptions is like ContainsCoord but allows the tolerances for
+// points and lines to be specified and reports which member matched.
+
+func (f *WOFFeature) ContainsCoordWithOptions(c geom.Coord, opts *geometry.ContainsCoordOptions) (*geometry.ContainsCoordResult, error) {
+	return f.cache.ContainsCoordWithOptions(f, c, opts)
+}
+
+// DropGeometryCache discards the feature's parsed geometry, polygons and
+// bounding boxes. They will be re-parsed the next time they are needed.
+
+func (f *WOFFeature) DropGeometryCache() {
+	f.cache.Drop()
 }
 
 func (f *WOFFeature) ContainsCoord(c geom.Coord) (bool, error) {
-	return geometry.FeatureContainsCoord(f, c)
+	return f.cache.ContainsCoord(f, c)
 }
 
 func (f *WOFFeature) SPR() (spr.StandardPlacesResult, error) {
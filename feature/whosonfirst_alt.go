@@ -11,16 +11,17 @@ import (
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2/geometry"
 	props_wof "github.com/whosonfirst/go-whosonfirst-geojson-v2/properties/whosonfirst"
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2/utils"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/validation"
 	"github.com/whosonfirst/go-whosonfirst-spr/v2"
 	"github.com/whosonfirst/go-whosonfirst-uri"
-	"github.com/whosonfirst/warning"
 	"strconv"
 	"strings"
 )
 
 type WOFAltFeature struct {
 	geojson.Feature
-	body []byte
+	body  []byte
+	cache *geometry.GeometryCache
 }
 
 type WOFAltStandardPlacesResult struct {
@@ -38,27 +39,43 @@ type WOFAltStandardPlacesResult struct {
 	WOFRepo                  string  `json:"wof:repo"`
 }
 
+// EnsureWOFAltFeature returns the first problem found by
+// ValidateWOFAltFeature or nil if there are none.
+
 func EnsureWOFAltFeature(body []byte) error {
 
+	report := ValidateWOFAltFeature(body)
+	return report.First()
+}
+
+// ValidateWOFAltFeature checks body for every property required by an
+// alternate geometry and returns a report listing all the missing ones.
+
+func ValidateWOFAltFeature(body []byte) *validation.Report {
+
+	report := validation.NewReport()
+
 	required := []string{
 		"properties.wof:id",
 		"properties.wof:repo",
 		"properties.src:alt_label",
 	}
 
-	err := utils.EnsureProperties(body, required)
+	for _, path := range required {
 
-	if err != nil {
-		return err
+		err := utils.EnsureProperties(body, []string{path})
+
+		if err != nil {
+			report.AddError(path, err)
+		}
 	}
 
-	return nil
+	return report
 }
 
 func NewWOFAltFeature(body []byte) (geojson.Feature, error) {
 
-	var stub interface{}
-	err := json.Unmarshal(body, &stub)
+	err := utils.EnsureJSON(body)
 
 	if err != nil {
 		return nil, err
@@ -66,12 +83,13 @@ func NewWOFAltFeature(body []byte) (geojson.Feature, error) {
 
 	err = EnsureWOFAltFeature(body)
 
-	if err != nil && !warning.IsWarning(err) {
+	if err != nil && !geojson.IsWarning(err) {
 		return nil, err
 	}
 
 	f := WOFAltFeature{
-		body: body,
+		body:  body,
+		cache: geometry.NewGeometryCache(),
 	}
 
 	return &f, nil
@@ -79,7 +97,7 @@ func NewWOFAltFeature(body []byte) (geojson.Feature, error) {
 
 func (f *WOFAltFeature) ContainsCoord(c geom.Coord) (bool, error) {
 
-	return geometry.FeatureContainsCoord(f, c)
+	return f.cache.ContainsCoord(f, c)
 }
 
 func (f *WOFAltFeature) String() string {
@@ -118,11 +136,32 @@ func (f *WOFAltFeature) Placetype() string {
 }
 
 func (f *WOFAltFeature) BoundingBoxes() (geojson.BoundingBoxes, error) {
-	return geometry.BoundingBoxesForFeature(f)
+	return f.cache.BoundingBoxes(f)
 }
 
 func (f *WOFAltFeature) Polygons() ([]geojson.Polygon, error) {
-	return geometry.PolygonsForFeature(f)
+	return f.cache.Polygons(f)
+}
+
+// Members returns each of the individual points, lines and polygons in the
+// feature's geometry. See geometry.MembersForGeometry for details.
+
+func (f *WOFAltFeature) Members() ([]geojson.Geometry, error) {
+	return f.cache.Members(f)
+}
+
+// ContainsCoordWithOptions is like ContainsCoord but allows the tolerances for
+// points and lines to be specified and reports which member matched.
+
+func (f *WOFAltFeature) ContainsCoordWithOptions(c geom.Coord, opts *geometry.ContainsCoordOptions) (*geometry.ContainsCoordResult, error) {
+	return f.cache.ContainsCoordWithOptions(f, c, opts)
+}
+
+// DropGeometryCache discards the feature's parsed geometry, polygons and
+// bounding boxes. They will be re-parsed the next time they are needed.
+
+func (f *WOFAltFeature) DropGeometryCache() {
+	f.cache.Drop()
 }
 
 func (f *WOFAltFeature) SPR() (spr.StandardPlacesResult, error) {
@@ -168,8 +207,13 @@ func (f *WOFAltFeature) SPR() (spr.StandardPlacesResult, error) {
 
 	mbr := bboxes.MBR()
 
-	lat := mbr.Min.Y + ((mbr.Max.Y - mbr.Min.Y) / 2.0)
-	lon := mbr.Min.X + ((mbr.Max.X - mbr.Min.X) / 2.0)
+	// mbr.Min.X will be greater than mbr.Max.X if the feature crosses
+	// the antimeridian which RectCenter accounts for
+
+	center := utils.RectCenter(mbr)
+
+	lat := center.Y
+	lon := center.X
 
 	spr := WOFAltStandardPlacesResult{
 		WOFId:          f.Id(),
@@ -237,7 +281,7 @@ func (spr *WOFAltStandardPlacesResult) MinLongitude() float64 {
 }
 
 func (spr *WOFAltStandardPlacesResult) MaxLatitude() float64 {
-	return spr.MZLatitude
+	return spr.MZMaxLatitude
 }
 
 func (spr *WOFAltStandardPlacesResult) MaxLongitude() float64 {
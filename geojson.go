@@ -34,4 +34,14 @@ type Polygon interface {
 	ContainsCoord(geom.Coord) bool
 }
 
+type Point interface {
+	Coord() geom.Coord
+}
+
+type LineString interface {
+	Path() geom.Path
+}
+
+// Geometry is one of Point, LineString or Polygon
+
 type Geometry interface{}
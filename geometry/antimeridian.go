@@ -0,0 +1,254 @@
+package geometry
+
+import (
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/utils"
+	"math"
+	"sort"
+)
+
+// Rings and lines that cross the antimeridian can be encoded in one of two
+// ways: split in to separate parts that meet at +/-180 degrees, as RFC 7946
+// recommends, or as a single sequence of coordinates that "jumps" from, say,
+// 179 to -179 degrees. The first case only matters when it comes to combining
+// the bounding boxes of the parts (see mbrForRects). The second case also
+// needs the coordinates to be "unwrapped", so that they are continuous, before
+// they can be measured or tested for containment.
+
+// crossesAntimeridian reports whether any edge between consecutive vertices
+// jumps across the antimeridian. Edges that run along it, from -180 to 180
+// (for example around the pole in Antarctica), don't count.
+
+func crossesAntimeridian(vertices []geom.Coord) bool {
+
+	for i := 1; i < len(vertices); i++ {
+
+		a := vertices[i-1]
+		b := vertices[i]
+
+		if math.Abs(b.X-a.X) <= 180.0 {
+			continue
+		}
+
+		if math.Abs(a.X) == 180.0 && math.Abs(b.X) == 180.0 {
+			continue
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// unwrapVertices returns a copy of vertices where each longitude is shifted
+// by a multiple of 360 degrees so that it is within 180 degrees of the
+// previous one. The first vertex is shifted to be within 180 degrees of ref.
+
+func unwrapVertices(vertices []geom.Coord, ref float64) []geom.Coord {
+
+	unwrapped := make([]geom.Coord, len(vertices))
+
+	prev := ref
+
+	for i, v := range vertices {
+
+		x := v.X
+
+		for x-prev > 180.0 {
+			x -= 360.0
+		}
+
+		for prev-x > 180.0 {
+			x += 360.0
+		}
+
+		unwrapped[i] = geom.Coord{X: x, Y: v.Y}
+		prev = x
+	}
+
+	return unwrapped
+}
+
+// continuousVertices returns vertices unchanged unless they cross the
+// antimeridian in which case they are unwrapped relative to the first vertex.
+
+func continuousVertices(vertices []geom.Coord) []geom.Coord {
+
+	if len(vertices) == 0 || !crossesAntimeridian(vertices) {
+		return vertices
+	}
+
+	return unwrapVertices(vertices, vertices[0].X)
+}
+
+// boundsForVertices returns the bounding box for a ring or a line, with a
+// western edge greater than its eastern edge if it crosses the antimeridian.
+
+func boundsForVertices(vertices []geom.Coord) *geom.Rect {
+
+	r := geom.NilRect()
+
+	if len(vertices) == 0 {
+		return &r
+	}
+
+	for _, v := range continuousVertices(vertices) {
+		r.ExpandToContainCoord(v)
+	}
+
+	if r.Max.X-r.Min.X >= 360.0 {
+		r.Min.X = -180.0
+		r.Max.X = 180.0
+		return &r
+	}
+
+	r.Min.X = utils.NormalizeLongitude(r.Min.X)
+	r.Max.X = utils.NormalizeLongitude(r.Max.X)
+
+	return &r
+}
+
+// mbrForRects returns the smallest rectangle that contains all of rects, any
+// of which may cross the antimeridian. Longitudes are treated as intervals on
+// a circle and the MBR is everything except the largest gap between them, so
+// a feature with parts at 179 and -179 degrees gets a two degree wide box that
+// crosses the antimeridian rather than one that spans the globe.
+
+func mbrForRects(rects []*geom.Rect) geom.Rect {
+
+	mbr := geom.NilRect()
+
+	if len(rects) == 0 {
+		return mbr
+	}
+
+	type interval struct {
+		min float64
+		max float64
+	}
+
+	intervals := make([]interval, 0)
+
+	for _, r := range rects {
+
+		mbr.Min.Y = math.Min(mbr.Min.Y, r.Min.Y)
+		mbr.Max.Y = math.Max(mbr.Max.Y, r.Max.Y)
+
+		if r.Min.X > r.Max.X {
+			intervals = append(intervals, interval{r.Min.X, 180.0})
+			intervals = append(intervals, interval{-180.0, r.Max.X})
+		} else {
+			intervals = append(intervals, interval{r.Min.X, r.Max.X})
+		}
+	}
+
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].min < intervals[j].min
+	})
+
+	merged := []interval{intervals[0]}
+
+	for _, i := range intervals[1:] {
+
+		last := &merged[len(merged)-1]
+
+		if i.min <= last.max {
+			last.max = math.Max(last.max, i.max)
+			continue
+		}
+
+		merged = append(merged, i)
+	}
+
+	first := merged[0]
+	last := merged[len(merged)-1]
+
+	// the gap that wraps around the antimeridian, which is what a regular
+	// (non-crossing) bounding box leaves out
+
+	gap := (first.min + 360.0) - last.max
+
+	mbr.Min.X = first.min
+	mbr.Max.X = last.max
+
+	for i := 1; i < len(merged); i++ {
+
+		d := merged[i].min - merged[i-1].max
+
+		if d > gap {
+			gap = d
+			mbr.Min.X = merged[i].min
+			mbr.Max.X = merged[i-1].max
+		}
+	}
+
+	return mbr
+}
+
+// rectIntervals returns the longitude intervals covered by r, two if it
+// crosses the antimeridian.
+
+func rectIntervals(r geom.Rect) [][2]float64 {
+
+	if r.Min.X > r.Max.X {
+		return [][2]float64{
+			[2]float64{r.Min.X, 180.0},
+			[2]float64{-180.0, r.Max.X},
+		}
+	}
+
+	return [][2]float64{
+		[2]float64{r.Min.X, r.Max.X},
+	}
+}
+
+// rectsIntersect is like geom.Rect's Intersects method but accounts for
+// rectangles that cross the antimeridian.
+
+func rectsIntersect(a geom.Rect, b geom.Rect) bool {
+
+	if a.Max.Y < b.Min.Y || a.Min.Y > b.Max.Y {
+		return false
+	}
+
+	for _, ia := range rectIntervals(a) {
+
+		for _, ib := range rectIntervals(b) {
+
+			if ia[1] >= ib[0] && ia[0] <= ib[1] {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// rectContainsRect reports whether b is inside a, accounting for rectangles
+// that cross the antimeridian.
+
+func rectContainsRect(a geom.Rect, b geom.Rect) bool {
+
+	if b.Min.Y < a.Min.Y || b.Max.Y > a.Max.Y {
+		return false
+	}
+
+	for _, ib := range rectIntervals(b) {
+
+		contained := false
+
+		for _, ia := range rectIntervals(a) {
+
+			if ib[0] >= ia[0] && ib[1] <= ia[1] {
+				contained = true
+				break
+			}
+		}
+
+		if !contained {
+			return false
+		}
+	}
+
+	return true
+}
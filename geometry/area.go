@@ -0,0 +1,224 @@
+package geometry
+
+import (
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/utils"
+	"math"
+)
+
+// PlanarArea returns the area of p in square degrees, treating longitude and
+// latitude as planar coordinates, with the area of its interior rings
+// subtracted. This is what WOF stores as geom:area.
+
+func PlanarArea(p geojson.Polygon) float64 {
+
+	ext := p.ExteriorRing()
+	area := math.Abs(planarRingArea(ext.Path.Vertices()))
+
+	for _, int_ring := range p.InteriorRings() {
+		area -= math.Abs(planarRingArea(int_ring.Path.Vertices()))
+	}
+
+	return area
+}
+
+// GeodesicArea returns the area of p in square metres on the WGS84 ellipsoid,
+// with the area of its interior rings subtracted. This is what WOF stores as
+// geom:area_square_m. See geodesicRingArea for details.
+
+func GeodesicArea(p geojson.Polygon) float64 {
+
+	ext := p.ExteriorRing()
+	area := math.Abs(geodesicRingArea(ext.Path.Vertices()))
+
+	for _, int_ring := range p.InteriorRings() {
+		area -= math.Abs(geodesicRingArea(int_ring.Path.Vertices()))
+	}
+
+	return area
+}
+
+// GeodesicPerimeter returns the length in metres, on the WGS84 ellipsoid, of
+// all of the rings (exterior and interior) of p.
+
+func GeodesicPerimeter(p geojson.Polygon) float64 {
+
+	ext := p.ExteriorRing()
+	perimeter := geodesicRingLength(ext.Path.Vertices())
+
+	for _, int_ring := range p.InteriorRings() {
+		perimeter += geodesicRingLength(int_ring.Path.Vertices())
+	}
+
+	return perimeter
+}
+
+// GeodesicLength returns the length of l in metres on the WGS84 ellipsoid.
+
+func GeodesicLength(l geojson.LineString) float64 {
+
+	path := l.Path()
+	vertices := path.Vertices()
+
+	length := 0.0
+
+	for i := 1; i < len(vertices); i++ {
+		length += VincentyDistance(vertices[i-1], vertices[i])
+	}
+
+	return length
+}
+
+func PlanarAreaForFeature(f geojson.Feature) (float64, error) {
+
+	polys, err := f.Polygons()
+
+	if err != nil {
+		return 0.0, err
+	}
+
+	area := 0.0
+
+	for _, p := range polys {
+		area += PlanarArea(p)
+	}
+
+	return area, nil
+}
+
+func GeodesicAreaForFeature(f geojson.Feature) (float64, error) {
+
+	polys, err := f.Polygons()
+
+	if err != nil {
+		return 0.0, err
+	}
+
+	area := 0.0
+
+	for _, p := range polys {
+		area += GeodesicArea(p)
+	}
+
+	return area, nil
+}
+
+func GeodesicPerimeterForFeature(f geojson.Feature) (float64, error) {
+
+	polys, err := f.Polygons()
+
+	if err != nil {
+		return 0.0, err
+	}
+
+	perimeter := 0.0
+
+	for _, p := range polys {
+		perimeter += GeodesicPerimeter(p)
+	}
+
+	return perimeter, nil
+}
+
+// GeodesicLengthForFeature returns the combined length, in metres, of all the
+// lines in f. Polygons and points have no length.
+
+func GeodesicLengthForFeature(f geojson.Feature) (float64, error) {
+
+	members, err := MembersForFeature(f)
+
+	if err != nil {
+		return 0.0, err
+	}
+
+	length := 0.0
+
+	for _, m := range members {
+
+		l, ok := m.(geojson.LineString)
+
+		if ok {
+			length += GeodesicLength(l)
+		}
+	}
+
+	return length, nil
+}
+
+// planarRingArea returns the signed (shoelace) area of a ring, which may or
+// may not repeat its first vertex at the end.
+
+func planarRingArea(vertices []geom.Coord) float64 {
+
+	vertices = continuousVertices(vertices)
+	count := len(vertices)
+
+	if count < 3 {
+		return 0.0
+	}
+
+	sum := 0.0
+
+	for i := 0; i < count; i++ {
+
+		a := vertices[i]
+		b := vertices[(i+1)%count]
+
+		sum += (a.X * b.Y) - (b.X * a.Y)
+	}
+
+	return sum / 2.0
+}
+
+// geodesicRingArea returns the signed area of a ring on the WGS84 ellipsoid.
+// Latitudes are converted to authalic latitudes, which preserve area, and the
+// spherical excess of the ring is calculated on a sphere with the same surface
+// area as the ellipsoid. Edges are treated as great circles on that sphere
+// which, for the densely noded rings WOF deals with, differs from true
+// ellipsoidal geodesics by a negligible amount.
+
+func geodesicRingArea(vertices []geom.Coord) float64 {
+
+	count := len(vertices)
+
+	if count < 3 {
+		return 0.0
+	}
+
+	excess := 0.0
+
+	for i := 0; i < count; i++ {
+
+		a := vertices[i]
+		b := vertices[(i+1)%count]
+
+		lambda := radians(utils.NormalizeLongitude(b.X - a.X))
+
+		t1 := math.Tan(authalicLatitude(radians(a.Y)) / 2.0)
+		t2 := math.Tan(authalicLatitude(radians(b.Y)) / 2.0)
+
+		excess += 2.0 * math.Atan2(math.Tan(lambda/2.0)*(t1+t2), 1.0+t1*t2)
+	}
+
+	return excess * authalic_radius * authalic_radius
+}
+
+func geodesicRingLength(vertices []geom.Coord) float64 {
+
+	length := 0.0
+
+	for i := 1; i < len(vertices); i++ {
+		length += VincentyDistance(vertices[i-1], vertices[i])
+	}
+
+	count := len(vertices)
+
+	// account for rings that don't repeat their first vertex
+
+	if count > 2 && vertices[0] != vertices[count-1] {
+		length += VincentyDistance(vertices[count-1], vertices[0])
+	}
+
+	return length
+}
@@ -21,28 +21,87 @@ func (b Bboxes) MBR() geom.Rect {
 
 func BoundingBoxesForFeature(f geojson.Feature) (geojson.BoundingBoxes, error) {
 
-	polys, err := PolygonsForFeature(f)
+	members, err := MembersForFeature(f)
 
 	if err != nil {
 		return nil, err
 	}
 
-	mbr := geom.NilRect()
+	return BoundingBoxesForMembers(members), nil
+}
+
+func BoundingBoxesForPolygons(polys []geojson.Polygon) geojson.BoundingBoxes {
+
+	members := make([]geojson.Geometry, len(polys))
+
+	for i, p := range polys {
+		members[i] = p
+	}
+
+	return BoundingBoxesForMembers(members)
+}
+
+// BoundingBoxesForMembers returns a bounding box for each of members, in the
+// same order, and their MBR. Members without any positions have no bounds and
+// are skipped. If there are no bounds (for example because a feature's
+// geometry is null) the MBR is the empty rectangle at (0, 0) rather than
+// geom.NilRect, whose infinite coordinates can not be encoded as JSON.
+
+func BoundingBoxesForMembers(members []geojson.Geometry) geojson.BoundingBoxes {
+
 	bounds := make([]*geom.Rect, 0)
 
-	for _, poly := range polys {
+	for _, m := range members {
+
+		var b *geom.Rect
+
+		switch m := m.(type) {
+		case geojson.Point:
 
-		ext := poly.ExteriorRing()
-		b := ext.Path.Bounds()
+			c := m.Coord()
+			b = &geom.Rect{Min: c, Max: c}
+
+		case geojson.LineString:
+
+			path := m.Path()
+			b = boundsForVertices(path.Vertices())
+
+		case geojson.Polygon:
+
+			ext := m.ExteriorRing()
+			b = boundsForVertices(ext.Path.Vertices())
+
+		default:
+			continue
+		}
+
+		// geom.NilRect, which has an inverted (and infinite) latitude
+		// range, is the bounds of no positions at all
+
+		if b.Min.Y > b.Max.Y {
+			continue
+		}
 
-		mbr.ExpandToContainRect(*b)
 		bounds = append(bounds, b)
 	}
 
+	if len(bounds) == 0 {
+
+		wb := Bboxes{
+			BBoxesBounds: bounds,
+			BBoxesMBR:    geom.Rect{},
+		}
+
+		return wb
+	}
+
+	// bounds (and the MBR) for features that cross the antimeridian have a
+	// western edge that is greater than their eastern edge; see antimeridian.go
+
 	wb := Bboxes{
 		BBoxesBounds: bounds,
-		BBoxesMBR:    mbr,
+		BBoxesMBR:    mbrForRects(bounds),
 	}
 
-	return wb, nil
+	return wb
 }
@@ -0,0 +1,274 @@
+package geometry
+
+import (
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"math"
+	"math/big"
+)
+
+// The ways a coordinate on the boundary of a polygon (on the edge or at a
+// vertex of its exterior ring or any of its interior rings) can be treated by
+// ContainsCoordOptions.Boundary and PolygonContainsCoordWithBoundary.
+
+const (
+	// Whatever geojson.Polygon.ContainsCoord does, which is not consistent
+	// between vertices and edges or between polygons
+	BOUNDARY_DEFAULT int = iota
+	// Coordinates on the boundary are contained by the polygon
+	BOUNDARY_INCLUSIVE
+	// Coordinates on the boundary are not contained by the polygon
+	BOUNDARY_EXCLUSIVE
+	// Coordinates on the boundary are not contained by the polygon but are
+	// reported separately, see ContainsCoordResult.Boundary
+	BOUNDARY_SEPARATE
+)
+
+// LocateCoord reports whether c is in the interior, on the boundary or in the
+// exterior of p, as one of LOCATION_INTERIOR, LOCATION_BOUNDARY or
+// LOCATION_EXTERIOR. Unlike p.ContainsCoord the answer is exact: a coordinate
+// is only on the boundary if it is exactly on an edge, or at a vertex, of one
+// of p's rings. A coordinate that is not on a shared edge between two polygons
+// is always in the interior of one and the exterior of the other, regardless
+// of which direction each polygon's rings run in.
+
+func LocateCoord(p geojson.Polygon, c geom.Coord) int {
+
+	rings, unwrapped := locationRings(p)
+
+	if !unwrapped {
+		return locateCoordInRings(rings, c)
+	}
+
+	// see Polygon.containsUnwrappedCoord
+
+	loc := LOCATION_EXTERIOR
+
+	for _, offset := range []float64{0.0, 360.0, -360.0} {
+
+		shifted := geom.Coord{X: c.X + offset, Y: c.Y}
+
+		switch locateCoordInRings(rings, shifted) {
+		case LOCATION_INTERIOR:
+			return LOCATION_INTERIOR
+		case LOCATION_BOUNDARY:
+			loc = LOCATION_BOUNDARY
+		}
+	}
+
+	return loc
+}
+
+// PolygonContainsCoordWithBoundary reports whether p contains c, treating
+// coordinates on p's boundary according to mode. BOUNDARY_SEPARATE is the same
+// as BOUNDARY_EXCLUSIVE; use LocateCoord to tell the two cases apart.
+
+func PolygonContainsCoordWithBoundary(p geojson.Polygon, c geom.Coord, mode int) bool {
+
+	if mode == BOUNDARY_DEFAULT {
+		return p.ContainsCoord(c)
+	}
+
+	switch LocateCoord(p, c) {
+	case LOCATION_INTERIOR:
+		return true
+	case LOCATION_BOUNDARY:
+		return mode == BOUNDARY_INCLUSIVE
+	default:
+		return false
+	}
+}
+
+// LocateCoordForFeature is like LocateCoord for all of the polygons in f. A
+// coordinate is in the interior of f if it is in the interior of any of them
+// and on its boundary if it is on the boundary of any of them but not in the
+// interior of any of them.
+
+func LocateCoordForFeature(f geojson.Feature, c geom.Coord) (int, error) {
+
+	polys, err := f.Polygons()
+
+	if err != nil {
+		return LOCATION_EXTERIOR, err
+	}
+
+	loc := LOCATION_EXTERIOR
+
+	for _, p := range polys {
+
+		switch LocateCoord(p, c) {
+		case LOCATION_INTERIOR:
+			return LOCATION_INTERIOR, nil
+		case LOCATION_BOUNDARY:
+			loc = LOCATION_BOUNDARY
+		}
+	}
+
+	return loc, nil
+}
+
+// locationRings returns the rings of p, exterior first, and whether they have
+// been unwrapped because p crosses the antimeridian.
+
+func locationRings(p geojson.Polygon) ([][]geom.Coord, bool) {
+
+	var unwrapped []geom.Polygon
+
+	switch poly := p.(type) {
+	case Polygon:
+		unwrapped = poly.unwrapped
+	case *Polygon:
+		unwrapped = poly.unwrapped
+	case *PreparedPolygon:
+		return locationRings(poly.Polygon)
+	}
+
+	rings := make([][]geom.Coord, 0)
+
+	if unwrapped != nil {
+
+		for _, ring := range unwrapped {
+			rings = append(rings, ring.Path.Vertices())
+		}
+
+		return rings, true
+	}
+
+	ext := p.ExteriorRing()
+	ext_vertices := ext.Path.Vertices()
+
+	rings = append(rings, ext_vertices)
+
+	for _, int := range p.InteriorRings() {
+		rings = append(rings, int.Path.Vertices())
+	}
+
+	if !crossesAntimeridian(ext_vertices) {
+		return rings, false
+	}
+
+	ref := ext_vertices[0].X
+
+	for i, ring := range rings {
+		rings[i] = unwrapVertices(ring, ref)
+	}
+
+	return rings, true
+}
+
+func locateCoordInRings(rings [][]geom.Coord, c geom.Coord) int {
+
+	if len(rings) == 0 || !isFinite(c.X) || !isFinite(c.Y) {
+		return LOCATION_EXTERIOR
+	}
+
+	switch locateCoordInRing(rings[0], c) {
+	case LOCATION_EXTERIOR:
+		return LOCATION_EXTERIOR
+	case LOCATION_BOUNDARY:
+		return LOCATION_BOUNDARY
+	}
+
+	for _, int := range rings[1:] {
+
+		switch locateCoordInRing(int, c) {
+		case LOCATION_INTERIOR:
+			return LOCATION_EXTERIOR
+		case LOCATION_BOUNDARY:
+			return LOCATION_BOUNDARY
+		}
+	}
+
+	return LOCATION_INTERIOR
+}
+
+// locateCoordInRing casts a ray east from c and counts the edges of ring it
+// crosses. An edge is crossed if c is strictly to the left of it, looking from
+// its lower end to its upper end, and c's latitude is in the half-open range
+// [lower, upper). Testing edges from their lower end means the answer doesn't
+// depend on which direction they run in.
+
+func locateCoordInRing(ring []geom.Coord, c geom.Coord) int {
+
+	count := len(ring)
+
+	if count < 3 {
+		return LOCATION_EXTERIOR
+	}
+
+	crossings := 0
+
+	for i := 0; i < count; i++ {
+
+		lo := ring[i]
+		hi := ring[(i+1)%count]
+
+		if !isFinite(lo.X) || !isFinite(lo.Y) || !isFinite(hi.X) || !isFinite(hi.Y) {
+			continue
+		}
+
+		if hi.Y < lo.Y || (hi.Y == lo.Y && hi.X < lo.X) {
+			lo, hi = hi, lo
+		}
+
+		if c.Y < lo.Y || c.Y > hi.Y {
+			continue
+		}
+
+		if c.X > math.Max(lo.X, hi.X) {
+			continue
+		}
+
+		o := exactOrientation(lo, hi, c)
+
+		if o == 0 && c.X >= math.Min(lo.X, hi.X) {
+			return LOCATION_BOUNDARY
+		}
+
+		if o > 0 && c.Y < hi.Y {
+			crossings += 1
+		}
+	}
+
+	if crossings%2 == 1 {
+		return LOCATION_INTERIOR
+	}
+
+	return LOCATION_EXTERIOR
+}
+
+// exactOrientation returns 1 if c is to the left of the line from a to b, -1
+// if it is to the right and 0 if it is on the line. Floating point arithmetic
+// is used unless the answer is close enough to 0 that rounding might change
+// its sign (or the coordinates are so large that it overflows) in which case it
+// is recalculated exactly. a, b and c must be finite.
+
+func exactOrientation(a geom.Coord, b geom.Coord, c geom.Coord) int {
+
+	l := (b.X - a.X) * (c.Y - a.Y)
+	r := (b.Y - a.Y) * (c.X - a.X)
+	v := l - r
+
+	if isFinite(v) && math.Abs(v) > 1e-12*(math.Abs(l)+math.Abs(r)) {
+
+		if v > 0 {
+			return 1
+		}
+
+		return -1
+	}
+
+	rat := func(f float64) *big.Rat {
+		return new(big.Rat).SetFloat64(f)
+	}
+
+	dx_ab := new(big.Rat).Sub(rat(b.X), rat(a.X))
+	dy_ac := new(big.Rat).Sub(rat(c.Y), rat(a.Y))
+	dy_ab := new(big.Rat).Sub(rat(b.Y), rat(a.Y))
+	dx_ac := new(big.Rat).Sub(rat(c.X), rat(a.X))
+
+	exact_l := new(big.Rat).Mul(dx_ab, dy_ac)
+	exact_r := new(big.Rat).Mul(dy_ab, dx_ac)
+
+	return exact_l.Cmp(exact_r)
+}
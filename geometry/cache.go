@@ -0,0 +1,182 @@
+package geometry
+
+import (
+	pm_geojson "github.com/paulmach/go.geojson"
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"sync"
+)
+
+// GeometryCache parses a feature's geometry, and derives its members, polygons
+// and bounding boxes, at most once. It is safe to use from multiple goroutines.
+// Values returned by the cache are shared between callers and should be
+// treated as read-only.
+
+type GeometryCache struct {
+	mu       sync.Mutex
+	loaded   bool
+	geometry *pm_geojson.Geometry
+	members  []geojson.Geometry
+	polygons []geojson.Polygon
+	bboxes   geojson.BoundingBoxes
+}
+
+func NewGeometryCache() *GeometryCache {
+	return &GeometryCache{}
+}
+
+func (c *GeometryCache) Geometry(f geojson.Feature) (*pm_geojson.Geometry, error) {
+
+	// a nil cache is valid and simply doesn't cache anything
+
+	if c == nil {
+		return GeometryForFeature(f)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.loadGeometry(f)
+}
+
+func (c *GeometryCache) Members(f geojson.Feature) ([]geojson.Geometry, error) {
+
+	if c == nil {
+		return MembersForFeature(f)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.loadMembers(f)
+}
+
+func (c *GeometryCache) Polygons(f geojson.Feature) ([]geojson.Polygon, error) {
+
+	if c == nil {
+		return PolygonsForFeature(f)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.polygons != nil {
+		return c.polygons, nil
+	}
+
+	members, err := c.loadMembers(f)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.polygons = PolygonsForMembers(members)
+	return c.polygons, nil
+}
+
+func (c *GeometryCache) BoundingBoxes(f geojson.Feature) (geojson.BoundingBoxes, error) {
+
+	if c == nil {
+		return BoundingBoxesForFeature(f)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.bboxes != nil {
+		return c.bboxes, nil
+	}
+
+	members, err := c.loadMembers(f)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.bboxes = BoundingBoxesForMembers(members)
+	return c.bboxes, nil
+}
+
+func (c *GeometryCache) ContainsCoord(f geojson.Feature, coord geom.Coord) (bool, error) {
+
+	rsp, err := c.ContainsCoordWithOptions(f, coord, DefaultContainsCoordOptions())
+
+	if err != nil {
+		return false, err
+	}
+
+	return rsp.Contains, nil
+}
+
+func (c *GeometryCache) ContainsCoordWithOptions(f geojson.Feature, coord geom.Coord, opts *ContainsCoordOptions) (*ContainsCoordResult, error) {
+
+	members, err := c.Members(f)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return MembersContainsCoord(members, coord, opts), nil
+}
+
+// Drop discards everything in the cache. The next call to any of the cache's
+// methods will parse the feature's geometry again.
+
+func (c *GeometryCache) Drop() {
+
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.loaded = false
+	c.geometry = nil
+	c.members = nil
+	c.polygons = nil
+	c.bboxes = nil
+}
+
+func (c *GeometryCache) loadGeometry(f geojson.Feature) (*pm_geojson.Geometry, error) {
+
+	// the geometry may legitimately be nil (null) so track whether it
+	// has been loaded separately
+
+	if c.loaded {
+		return c.geometry, nil
+	}
+
+	g, err := GeometryForFeature(f)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.geometry = g
+	c.loaded = true
+
+	return c.geometry, nil
+}
+
+func (c *GeometryCache) loadMembers(f geojson.Feature) ([]geojson.Geometry, error) {
+
+	if c.members != nil {
+		return c.members, nil
+	}
+
+	g, err := c.loadGeometry(f)
+
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := MembersForGeometry(f, g)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.members = members
+	return c.members, nil
+}
@@ -0,0 +1,531 @@
+package geometry
+
+import (
+	"container/heap"
+	"encoding/json"
+	"errors"
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/utils"
+	"math"
+)
+
+// The sources for centroids that have been computed from a feature's geometry
+// rather than read from its properties.
+
+const CENTROID_SOURCE_GEOM string = "geom:computed"
+const CENTROID_SOURCE_LBL string = "lbl:computed"
+
+// The default precision, in degrees, for the PoleOfInaccessibility of a
+// polygon computed by LabelCentroidForFeature. This is roughly 10 metres at
+// the equator.
+
+const DEFAULT_POLYLABEL_PRECISION float64 = 0.0001
+
+type Centroid struct {
+	geojson.Centroid
+	coord  geom.Coord
+	source string
+}
+
+func NewCentroid(coord geom.Coord, source string) geojson.Centroid {
+
+	c := Centroid{
+		coord:  coord,
+		source: source,
+	}
+
+	return &c
+}
+
+func (c *Centroid) Coord() geom.Coord {
+	return c.coord
+}
+
+func (c *Centroid) Source() string {
+	return c.source
+}
+
+func (c *Centroid) ToString() (string, error) {
+
+	type Geometry struct {
+		Type        string    `json:"type"`
+		Coordinates []float64 `json:"coordinates"`
+	}
+
+	g := Geometry{
+		Type:        "Point",
+		Coordinates: []float64{c.coord.X, c.coord.Y},
+	}
+
+	b, err := json.Marshal(g)
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// CentroidForFeature returns the mathematical centroid of f's geometry, with a
+// source of CENTROID_SOURCE_GEOM. For polygons this is the area-weighted
+// centroid, with interior rings subtracted. If f has no polygons (or they have
+// no area) the length-weighted centroid of its lines is used and failing that
+// the mean of its points. The centroid of a polygon is not guaranteed to fall
+// inside it; see LabelCentroidForFeature for that.
+
+func CentroidForFeature(f geojson.Feature) (geojson.Centroid, error) {
+
+	members, err := MembersForFeature(f)
+
+	if err != nil {
+		return nil, err
+	}
+
+	coord, ok := membersCentroid(members)
+
+	if !ok {
+		return nil, errors.New("Feature has no geometry to derive a centroid from")
+	}
+
+	return NewCentroid(coord, CENTROID_SOURCE_GEOM), nil
+}
+
+// LabelCentroidForFeature returns a point, with a source of CENTROID_SOURCE_LBL,
+// suitable for placing a label. For polygons this is the pole of inaccessibility
+// of the largest polygon, which is always inside that polygon. For other
+// geometries it is the vertex closest to the feature's centroid.
+
+func LabelCentroidForFeature(f geojson.Feature) (geojson.Centroid, error) {
+
+	members, err := MembersForFeature(f)
+
+	if err != nil {
+		return nil, err
+	}
+
+	polys := PolygonsForMembers(members)
+
+	var largest geojson.Polygon
+	largest_area := -1.0
+
+	for _, p := range polys {
+
+		area := PlanarArea(p)
+
+		if area > largest_area {
+			largest = p
+			largest_area = area
+		}
+	}
+
+	if largest != nil && largest_area > 0.0 {
+		coord := PoleOfInaccessibility(largest, DEFAULT_POLYLABEL_PRECISION)
+		return NewCentroid(coord, CENTROID_SOURCE_LBL), nil
+	}
+
+	centroid, ok := membersCentroid(members)
+
+	if !ok {
+		return nil, errors.New("Feature has no geometry to derive a label centroid from")
+	}
+
+	coord := centroid
+	best := math.Inf(1)
+
+	for _, v := range membersVertices(members) {
+
+		d := math.Hypot(v.X-centroid.X, v.Y-centroid.Y)
+
+		if d < best {
+			best = d
+			coord = v
+		}
+	}
+
+	return NewCentroid(coord, CENTROID_SOURCE_LBL), nil
+}
+
+// PolygonCentroid returns the area-weighted centroid of p, with its interior
+// rings subtracted, and the (planar) area that was used to weight it.
+
+func PolygonCentroid(p geojson.Polygon) (geom.Coord, float64) {
+
+	var x, y, area float64
+
+	rings := polygonRings(p)
+
+	for i, ring := range rings {
+
+		c, a := ringCentroid(ring)
+
+		// interior rings are subtracted
+
+		if i > 0 {
+			a = -a
+		}
+
+		x += c.X * a
+		y += c.Y * a
+		area += a
+	}
+
+	if area <= 0.0 {
+		return geom.Coord{}, 0.0
+	}
+
+	return geom.Coord{X: utils.NormalizeLongitude(x / area), Y: y / area}, area
+}
+
+// PoleOfInaccessibility returns the point inside p that is farthest from its
+// edges, to within precision degrees, using the "polylabel" algorithm. See
+// also: https://github.com/mapbox/polylabel
+
+func PoleOfInaccessibility(p geojson.Polygon, precision float64) geom.Coord {
+
+	rings := polygonRings(p)
+
+	bounds := geom.NilRect()
+
+	for _, v := range rings[0] {
+		bounds.ExpandToContainCoord(v)
+	}
+
+	width := bounds.Max.X - bounds.Min.X
+	height := bounds.Max.Y - bounds.Min.Y
+
+	cell_size := math.Min(width, height)
+
+	if cell_size == 0.0 || math.IsInf(cell_size, 0) {
+		return geom.Coord{X: utils.NormalizeLongitude(bounds.Min.X), Y: bounds.Min.Y}
+	}
+
+	h := cell_size / 2.0
+
+	cells := &polylabelQueue{}
+
+	for x := bounds.Min.X; x < bounds.Max.X; x += cell_size {
+
+		for y := bounds.Min.Y; y < bounds.Max.Y; y += cell_size {
+			heap.Push(cells, newPolylabelCell(geom.Coord{X: x + h, Y: y + h}, h, rings))
+		}
+	}
+
+	// start with the centroid and the center of the bounding box as the
+	// best guesses; the centroid is normalized so make sure it is in the
+	// same frame of reference as the (possibly unwrapped) rings
+
+	centroid, _ := PolygonCentroid(p)
+
+	for centroid.X < bounds.Min.X-180.0 {
+		centroid.X += 360.0
+	}
+
+	best := newPolylabelCell(centroid, 0.0, rings)
+
+	bbox_cell := newPolylabelCell(geom.Coord{X: bounds.Min.X + width/2.0, Y: bounds.Min.Y + height/2.0}, 0.0, rings)
+
+	if bbox_cell.d > best.d {
+		best = bbox_cell
+	}
+
+	for cells.Len() > 0 {
+
+		cell := heap.Pop(cells).(*polylabelCell)
+
+		if cell.d > best.d {
+			best = cell
+		}
+
+		if cell.max-best.d <= precision {
+			continue
+		}
+
+		h = cell.h / 2.0
+
+		heap.Push(cells, newPolylabelCell(geom.Coord{X: cell.c.X - h, Y: cell.c.Y - h}, h, rings))
+		heap.Push(cells, newPolylabelCell(geom.Coord{X: cell.c.X + h, Y: cell.c.Y - h}, h, rings))
+		heap.Push(cells, newPolylabelCell(geom.Coord{X: cell.c.X - h, Y: cell.c.Y + h}, h, rings))
+		heap.Push(cells, newPolylabelCell(geom.Coord{X: cell.c.X + h, Y: cell.c.Y + h}, h, rings))
+	}
+
+	return geom.Coord{X: utils.NormalizeLongitude(best.c.X), Y: best.c.Y}
+}
+
+func membersCentroid(members []geojson.Geometry) (geom.Coord, bool) {
+
+	var x, y, weight float64
+
+	// if the feature crosses the antimeridian shift everything east of the
+	// MBR's western edge so that parts on either side are averaged correctly
+
+	mbr := BoundingBoxesForMembers(members).MBR()
+
+	shift := func(c geom.Coord) geom.Coord {
+
+		if utils.RectCrossesAntimeridian(mbr) && c.X < mbr.Min.X {
+			c.X += 360.0
+		}
+
+		return c
+	}
+
+	for _, p := range PolygonsForMembers(members) {
+
+		c, a := PolygonCentroid(p)
+		c = shift(c)
+
+		x += c.X * a
+		y += c.Y * a
+		weight += a
+	}
+
+	if weight > 0.0 {
+		return geom.Coord{X: utils.NormalizeLongitude(x / weight), Y: y / weight}, true
+	}
+
+	for _, m := range members {
+
+		l, ok := m.(geojson.LineString)
+
+		if !ok {
+			continue
+		}
+
+		path := l.Path()
+		vertices := path.Vertices()
+
+		for i := 1; i < len(vertices); i++ {
+
+			a := shift(vertices[i-1])
+			b := shift(vertices[i])
+			d := math.Hypot(b.X-a.X, b.Y-a.Y)
+
+			x += (a.X + b.X) / 2.0 * d
+			y += (a.Y + b.Y) / 2.0 * d
+			weight += d
+		}
+	}
+
+	if weight > 0.0 {
+		return geom.Coord{X: utils.NormalizeLongitude(x / weight), Y: y / weight}, true
+	}
+
+	// points, or lines and polygons that have collapsed to a point
+
+	vertices := membersVertices(members)
+
+	if len(vertices) == 0 {
+		return geom.Coord{}, false
+	}
+
+	for _, v := range vertices {
+		v = shift(v)
+		x += v.X
+		y += v.Y
+	}
+
+	count := float64(len(vertices))
+	return geom.Coord{X: utils.NormalizeLongitude(x / count), Y: y / count}, true
+}
+
+func membersVertices(members []geojson.Geometry) []geom.Coord {
+
+	vertices := make([]geom.Coord, 0)
+
+	for _, m := range members {
+
+		switch m := m.(type) {
+		case geojson.Point:
+			vertices = append(vertices, m.Coord())
+		case geojson.LineString:
+			path := m.Path()
+			vertices = append(vertices, path.Vertices()...)
+		case geojson.Polygon:
+			ext := m.ExteriorRing()
+			vertices = append(vertices, ext.Path.Vertices()...)
+		}
+	}
+
+	return vertices
+}
+
+// ringCentroid returns the centroid of a ring and its (unsigned) area.
+
+func ringCentroid(vertices []geom.Coord) (geom.Coord, float64) {
+
+	count := len(vertices)
+
+	if count < 3 {
+		return geom.Coord{}, 0.0
+	}
+
+	var x, y, area float64
+
+	for i := 0; i < count; i++ {
+
+		a := vertices[i]
+		b := vertices[(i+1)%count]
+
+		cross := (a.X * b.Y) - (b.X * a.Y)
+
+		x += (a.X + b.X) * cross
+		y += (a.Y + b.Y) * cross
+		area += cross
+	}
+
+	area = area / 2.0
+
+	if area == 0.0 {
+		return geom.Coord{}, 0.0
+	}
+
+	c := geom.Coord{
+		X: x / (6.0 * area),
+		Y: y / (6.0 * area),
+	}
+
+	return c, math.Abs(area)
+}
+
+// polygonRings returns the vertices of each of the rings of p, exterior ring
+// first. If the exterior ring crosses the antimeridian all of the rings are
+// unwrapped relative to its first vertex so they can be treated as planar.
+
+func polygonRings(p geojson.Polygon) [][]geom.Coord {
+
+	ext := p.ExteriorRing()
+	ext_vertices := ext.Path.Vertices()
+
+	rings := [][]geom.Coord{
+		ext_vertices,
+	}
+
+	for _, int_ring := range p.InteriorRings() {
+		rings = append(rings, int_ring.Path.Vertices())
+	}
+
+	if len(ext_vertices) == 0 || !crossesAntimeridian(ext_vertices) {
+		return rings
+	}
+
+	ref := ext_vertices[0].X
+
+	for i, ring := range rings {
+		rings[i] = unwrapVertices(ring, ref)
+	}
+
+	return rings
+}
+
+// polylabelDistance returns the distance from c to the closest edge of rings,
+// negative if c is outside the polygon they describe.
+
+func polylabelDistance(c geom.Coord, rings [][]geom.Coord) float64 {
+
+	inside := false
+	min_sq := math.Inf(1)
+
+	for _, ring := range rings {
+
+		count := len(ring)
+
+		for i, j := 0, count-1; i < count; j, i = i, i+1 {
+
+			a := ring[i]
+			b := ring[j]
+
+			if (a.Y > c.Y) != (b.Y > c.Y) && c.X < (b.X-a.X)*(c.Y-a.Y)/(b.Y-a.Y)+a.X {
+				inside = !inside
+			}
+
+			min_sq = math.Min(min_sq, segmentDistanceSq(c, a, b))
+		}
+	}
+
+	d := math.Sqrt(min_sq)
+
+	if !inside {
+		return -d
+	}
+
+	return d
+}
+
+func segmentDistanceSq(c geom.Coord, a geom.Coord, b geom.Coord) float64 {
+
+	x := a.X
+	y := a.Y
+	dx := b.X - x
+	dy := b.Y - y
+
+	if dx != 0.0 || dy != 0.0 {
+
+		t := ((c.X-x)*dx + (c.Y-y)*dy) / (dx*dx + dy*dy)
+
+		if t > 1.0 {
+			x = b.X
+			y = b.Y
+		} else if t > 0.0 {
+			x += dx * t
+			y += dy * t
+		}
+	}
+
+	dx = c.X - x
+	dy = c.Y - y
+
+	return dx*dx + dy*dy
+}
+
+type polylabelCell struct {
+	c   geom.Coord
+	h   float64
+	d   float64
+	max float64
+}
+
+func newPolylabelCell(c geom.Coord, h float64, rings [][]geom.Coord) *polylabelCell {
+
+	d := polylabelDistance(c, rings)
+
+	cell := &polylabelCell{
+		c:   c,
+		h:   h,
+		d:   d,
+		max: d + h*math.Sqrt2,
+	}
+
+	return cell
+}
+
+// polylabelQueue is a container/heap of cells ordered by their potential
+// maximum distance, largest first.
+
+type polylabelQueue []*polylabelCell
+
+func (q polylabelQueue) Len() int {
+	return len(q)
+}
+
+func (q polylabelQueue) Less(i, j int) bool {
+	return q[i].max > q[j].max
+}
+
+func (q polylabelQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *polylabelQueue) Push(x interface{}) {
+	*q = append(*q, x.(*polylabelCell))
+}
+
+func (q *polylabelQueue) Pop() interface{} {
+
+	old := *q
+	n := len(old)
+	cell := old[n-1]
+	*q = old[:n-1]
+
+	return cell
+}
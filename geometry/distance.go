@@ -0,0 +1,272 @@
+package geometry
+
+import (
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/utils"
+	"math"
+)
+
+// DistanceResult describes the closest point, on one of the members of a
+// feature's geometry (as returned by MembersForFeature), to a coordinate.
+
+type DistanceResult struct {
+	// The geodesic distance, in metres, between the coordinate and Coord. It
+	// is 0 if the coordinate is inside (or on the boundary of) a polygon and
+	// +Inf if the geometry is empty.
+	Distance float64
+	// The closest point, which is the coordinate itself if it is inside a
+	// polygon
+	Coord geom.Coord
+	// The index of the closest member or -1
+	Index int
+	// "Point", "LineString" or "Polygon" or "" if the geometry is empty
+	Type string
+}
+
+// DistanceToFeature returns the distance from c to the closest point of any of
+// the points, lines or polygons in f's geometry. Candidate points are compared
+// using the HaversineDistance, and ClosestPointOnSegment, and the distance to
+// the closest one is then measured on the WGS84 ellipsoid using the
+// VincentyDistance.
+
+func DistanceToFeature(f geojson.Feature, c geom.Coord) (*DistanceResult, error) {
+
+	members, err := featureMembers(f)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return DistanceToMembers(members, c), nil
+}
+
+// DistanceToFeatureBoundary is like DistanceToFeature except that the distance
+// to a polygon is measured to the closest point on any of its rings, even if
+// c is inside it. This is the distance from a place to the edge of, say, the
+// locality it is in.
+
+func DistanceToFeatureBoundary(f geojson.Feature, c geom.Coord) (*DistanceResult, error) {
+
+	members, err := featureMembers(f)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return distanceToMembers(members, c, true), nil
+}
+
+// DistanceToMembers is like DistanceToFeature for a list of members, as
+// returned by MembersForGeometry.
+
+func DistanceToMembers(members []geojson.Geometry, c geom.Coord) *DistanceResult {
+	return distanceToMembers(members, c, false)
+}
+
+// DistanceToPolygon returns the distance, in metres, from c to the closest
+// point on p along with that point. Both are 0 and c if p contains c.
+
+func DistanceToPolygon(p geojson.Polygon, c geom.Coord) (float64, geom.Coord) {
+
+	if LocateCoord(p, c) != LOCATION_EXTERIOR {
+		return 0.0, c
+	}
+
+	return DistanceToPolygonBoundary(p, c)
+}
+
+// DistanceToPolygonBoundary returns the distance, in metres, from c to the
+// closest point on any of the rings of p along with that point.
+
+func DistanceToPolygonBoundary(p geojson.Polygon, c geom.Coord) (float64, geom.Coord) {
+
+	d, pt := closestPointOnPolygon(p, c)
+	return geodesicDistance(d, c, pt), pt
+}
+
+// DistanceToLineString returns the distance, in metres, from c to the closest
+// point on l along with that point.
+
+func DistanceToLineString(l geojson.LineString, c geom.Coord) (float64, geom.Coord) {
+
+	path := l.Path()
+
+	d, pt := closestPointOnVertices(path.Vertices(), c, false)
+	return geodesicDistance(d, c, pt), pt
+}
+
+// MinDistanceToRect returns a lower bound, in metres, for the distance from c
+// to any point in r, which may cross the antimeridian. It is 0 if r contains
+// c. It is cheap to calculate and intended for discarding features whose
+// bounding boxes are too far away to be worth measuring.
+
+func MinDistanceToRect(c geom.Coord, r geom.Rect) float64 {
+
+	// the angular distance between two points is at least the difference in
+	// their latitudes and at least the distance from one of them to the
+	// meridian of the other, both of which grow with the difference
+
+	d_lat := math.Max(0.0, math.Max(r.Min.Y-c.Y, c.Y-r.Max.Y))
+	d_lon := longitudeGap(c.X, r.Min.X, r.Max.X)
+
+	lat_bound := radians(d_lat)
+	lon_bound := math.Asin(math.Cos(radians(c.Y)) * math.Sin(radians(math.Min(d_lon, 90.0))))
+
+	// the WGS84 ellipsoid is within half a percent of the mean radius of the
+	// Earth everywhere so allow for twice that
+
+	return math.Max(lat_bound, lon_bound) * EARTH_RADIUS * 0.99
+}
+
+func distanceToMembers(members []geojson.Geometry, c geom.Coord, boundary bool) *DistanceResult {
+
+	rsp := &DistanceResult{
+		Distance: math.Inf(1),
+		Index:    -1,
+	}
+
+	best := math.Inf(1)
+
+	for i, m := range members {
+
+		var d float64
+		var pt geom.Coord
+
+		switch m := m.(type) {
+		case geojson.Polygon:
+
+			if !boundary && LocateCoord(m, c) != LOCATION_EXTERIOR {
+
+				rsp = &DistanceResult{Distance: 0.0, Coord: c, Index: i, Type: "Polygon"}
+				return rsp
+			}
+
+			d, pt = closestPointOnPolygon(m, c)
+
+		case geojson.LineString:
+
+			path := m.Path()
+			d, pt = closestPointOnVertices(path.Vertices(), c, false)
+
+		case geojson.Point:
+
+			pt = m.Coord()
+			d = HaversineDistance(c, pt)
+
+		default:
+			continue
+		}
+
+		if d < best {
+			best = d
+			rsp = &DistanceResult{Distance: d, Coord: pt, Index: i, Type: MemberType(m)}
+		}
+	}
+
+	if rsp.Index != -1 {
+		rsp.Distance = geodesicDistance(rsp.Distance, c, rsp.Coord)
+	}
+
+	return rsp
+}
+
+// closestPointOnPolygon returns the HaversineDistance from c to the closest
+// point on any of the rings of p along with that point.
+
+func closestPointOnPolygon(p geojson.Polygon, c geom.Coord) (float64, geom.Coord) {
+
+	ext := p.ExteriorRing()
+
+	best, closest := closestPointOnVertices(ext.Path.Vertices(), c, true)
+
+	for _, int := range p.InteriorRings() {
+
+		d, pt := closestPointOnVertices(int.Path.Vertices(), c, true)
+
+		if d < best {
+			best = d
+			closest = pt
+		}
+	}
+
+	return best, closest
+}
+
+// closestPointOnVertices returns the HaversineDistance from c to the closest
+// point on the line through vertices, or the ring if closed is true, along
+// with that point.
+
+func closestPointOnVertices(vertices []geom.Coord, c geom.Coord, closed bool) (float64, geom.Coord) {
+
+	count := len(vertices)
+
+	switch count {
+	case 0:
+		return math.Inf(1), c
+	case 1:
+		return HaversineDistance(c, vertices[0]), vertices[0]
+	}
+
+	best := math.Inf(1)
+	closest := vertices[0]
+
+	edges := count - 1
+
+	if closed && vertices[0] != vertices[count-1] {
+		edges = count
+	}
+
+	for i := 0; i < edges; i++ {
+
+		pt := ClosestPointOnSegment(c, vertices[i], vertices[(i+1)%count])
+		d := HaversineDistance(c, pt)
+
+		if d < best {
+			best = d
+			closest = pt
+		}
+	}
+
+	return best, closest
+}
+
+// geodesicDistance returns the VincentyDistance between c and pt, or d if it
+// is infinite (because there was no pt).
+
+func geodesicDistance(d float64, c geom.Coord, pt geom.Coord) float64 {
+
+	if math.IsInf(d, 1) {
+		return d
+	}
+
+	return VincentyDistance(c, pt)
+}
+
+// longitudeGap returns the smallest difference, in degrees, between x and any
+// longitude from min_x east to max_x.
+
+func longitudeGap(x float64, min_x float64, max_x float64) float64 {
+
+	width := max_x - min_x
+
+	if width < 0.0 {
+		width += 360.0
+	}
+
+	if width >= 360.0 {
+		return 0.0
+	}
+
+	offset := utils.NormalizeLongitude(x - min_x)
+
+	if offset < 0.0 {
+		offset += 360.0
+	}
+
+	if offset <= width {
+		return 0.0
+	}
+
+	return math.Min(offset-width, 360.0-offset)
+}
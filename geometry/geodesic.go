@@ -0,0 +1,190 @@
+package geometry
+
+import (
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/utils"
+	"math"
+)
+
+// The mean radius of the Earth, in metres, as defined by the IUGG.
+
+const EARTH_RADIUS float64 = 6371008.8
+
+// HaversineDistance returns the great-circle distance, in metres, between a
+// and b whose X and Y values are longitude and latitude.
+
+func HaversineDistance(a geom.Coord, b geom.Coord) float64 {
+
+	lat1 := radians(a.Y)
+	lat2 := radians(b.Y)
+
+	dlat := lat2 - lat1
+	dlon := radians(b.X - a.X)
+
+	h := math.Pow(math.Sin(dlat/2.0), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dlon/2.0), 2)
+
+	return 2.0 * EARTH_RADIUS * math.Asin(math.Min(1.0, math.Sqrt(h)))
+}
+
+// DistanceToSegment returns the distance, in metres, between c and the closest
+// point on the segment from a to b. The closest point is found in a local
+// equirectangular projection centered on c which is accurate for the short
+// distances that tolerances are measured in.
+
+func DistanceToSegment(c geom.Coord, a geom.Coord, b geom.Coord) float64 {
+
+	p := ClosestPointOnSegment(c, a, b)
+	return HaversineDistance(c, p)
+}
+
+// ClosestPointOnSegment returns the point on the segment from a to b that is
+// closest to c. See DistanceToSegment for details.
+
+func ClosestPointOnSegment(c geom.Coord, a geom.Coord, b geom.Coord) geom.Coord {
+
+	k := math.Cos(radians(c.Y))
+
+	// longitudes are relative to c, and b is relative to a, so segments that
+	// cross the antimeridian are treated as going the short way round
+
+	ax := utils.NormalizeLongitude(a.X-c.X) * k
+	ay := a.Y - c.Y
+	bx := ax + utils.NormalizeLongitude(b.X-a.X)*k
+	by := b.Y - c.Y
+
+	dx := bx - ax
+	dy := by - ay
+
+	len2 := dx*dx + dy*dy
+
+	if len2 == 0.0 || k == 0.0 {
+		return a
+	}
+
+	t := -(ax*dx + ay*dy) / len2
+	t = math.Max(0.0, math.Min(1.0, t))
+
+	p := geom.Coord{
+		X: utils.NormalizeLongitude(c.X + (ax+t*dx)/k),
+		Y: c.Y + ay + t*dy,
+	}
+
+	return p
+}
+
+func radians(d float64) float64 {
+	return d * math.Pi / 180.0
+}
+
+// The WGS84 ellipsoid.
+
+const WGS84_SEMI_MAJOR_AXIS float64 = 6378137.0
+const WGS84_FLATTENING float64 = 1.0 / 298.257223563
+
+var wgs84_semi_minor_axis = WGS84_SEMI_MAJOR_AXIS * (1.0 - WGS84_FLATTENING)
+var wgs84_eccentricity_sq = WGS84_FLATTENING * (2.0 - WGS84_FLATTENING)
+var wgs84_eccentricity = math.Sqrt(wgs84_eccentricity_sq)
+
+var authalic_qp = authalicQ(math.Pi / 2.0)
+var authalic_radius = WGS84_SEMI_MAJOR_AXIS * math.Sqrt(authalic_qp/2.0)
+
+// VincentyDistance returns the distance, in metres, between a and b on the
+// WGS84 ellipsoid using Vincenty's inverse formula. For nearly antipodal
+// points, where the formula fails to converge, it falls back to the
+// HaversineDistance.
+
+func VincentyDistance(a geom.Coord, b geom.Coord) float64 {
+
+	if a == b {
+		return 0.0
+	}
+
+	f := WGS84_FLATTENING
+	major := WGS84_SEMI_MAJOR_AXIS
+	minor := wgs84_semi_minor_axis
+
+	L := radians(utils.NormalizeLongitude(b.X - a.X))
+
+	U1 := math.Atan((1.0 - f) * math.Tan(radians(a.Y)))
+	U2 := math.Atan((1.0 - f) * math.Tan(radians(b.Y)))
+
+	sinU1, cosU1 := math.Sin(U1), math.Cos(U1)
+	sinU2, cosU2 := math.Sin(U2), math.Cos(U2)
+
+	lambda := L
+
+	var sin_sigma, cos_sigma, sigma, cos_sq_alpha, cos_2sigma_m float64
+
+	converged := false
+
+	for i := 0; i < 200; i++ {
+
+		sin_lambda, cos_lambda := math.Sin(lambda), math.Cos(lambda)
+
+		sin_sigma = math.Sqrt(math.Pow(cosU2*sin_lambda, 2) + math.Pow(cosU1*sinU2-sinU1*cosU2*cos_lambda, 2))
+
+		if sin_sigma == 0.0 {
+			return 0.0
+		}
+
+		cos_sigma = sinU1*sinU2 + cosU1*cosU2*cos_lambda
+		sigma = math.Atan2(sin_sigma, cos_sigma)
+
+		sin_alpha := cosU1 * cosU2 * sin_lambda / sin_sigma
+		cos_sq_alpha = 1.0 - sin_alpha*sin_alpha
+
+		// equatorial lines have cos_sq_alpha == 0
+
+		cos_2sigma_m = 0.0
+
+		if cos_sq_alpha != 0.0 {
+			cos_2sigma_m = cos_sigma - 2.0*sinU1*sinU2/cos_sq_alpha
+		}
+
+		C := f / 16.0 * cos_sq_alpha * (4.0 + f*(4.0-3.0*cos_sq_alpha))
+
+		prev := lambda
+		lambda = L + (1.0-C)*f*sin_alpha*(sigma+C*sin_sigma*(cos_2sigma_m+C*cos_sigma*(-1.0+2.0*cos_2sigma_m*cos_2sigma_m)))
+
+		if math.Abs(lambda-prev) < 1e-12 {
+			converged = true
+			break
+		}
+	}
+
+	if !converged {
+		return HaversineDistance(a, b)
+	}
+
+	u_sq := cos_sq_alpha * (major*major - minor*minor) / (minor * minor)
+
+	A := 1.0 + u_sq/16384.0*(4096.0+u_sq*(-768.0+u_sq*(320.0-175.0*u_sq)))
+	B := u_sq / 1024.0 * (256.0 + u_sq*(-128.0+u_sq*(74.0-47.0*u_sq)))
+
+	delta_sigma := B * sin_sigma * (cos_2sigma_m + B/4.0*(cos_sigma*(-1.0+2.0*cos_2sigma_m*cos_2sigma_m)-B/6.0*cos_2sigma_m*(-3.0+4.0*sin_sigma*sin_sigma)*(-3.0+4.0*cos_2sigma_m*cos_2sigma_m)))
+
+	return minor * A * (sigma - delta_sigma)
+}
+
+// authalicLatitude converts a geodetic latitude (in radians) on the WGS84
+// ellipsoid to the latitude on a sphere of equal surface area that preserves
+// area.
+
+func authalicLatitude(phi float64) float64 {
+
+	ratio := authalicQ(phi) / authalic_qp
+	ratio = math.Max(-1.0, math.Min(1.0, ratio))
+
+	return math.Asin(ratio)
+}
+
+func authalicQ(phi float64) float64 {
+
+	e := wgs84_eccentricity
+	e2 := wgs84_eccentricity_sq
+
+	sin_phi := math.Sin(phi)
+	es := e * sin_phi
+
+	return (1.0 - e2) * (sin_phi/(1.0-e2*sin_phi*sin_phi) - (1.0/(2.0*e))*math.Log((1.0-es)/(1.0+es)))
+}
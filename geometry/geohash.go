@@ -0,0 +1,323 @@
+package geometry
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mmcloughlin/geohash"
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/utils"
+	"math"
+)
+
+// The maximum number of characters in a geohash
+
+const GEOHASH_MAX_PRECISION int = 12
+
+// The characters a geohash is made of, in ascending order
+
+const geohash_alphabet string = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// GeohashCoverOptions describes the geohash cells used to cover a feature.
+// Cells entirely inside the feature are as large as possible, but no larger
+// than MinPrecision characters, and cells on its boundary are MaxPrecision
+// characters. If MinPrecision and MaxPrecision are the same every cell in
+// the cover has that precision.
+
+type GeohashCoverOptions struct {
+	// The precision, in characters, of the largest cells in the cover
+	MinPrecision int
+	// The precision, in characters, of the smallest cells in the cover
+	MaxPrecision int
+	// The maximum number of cells in the cover, or 0 for no limit. Covering a
+	// feature that needs more cells than this is an error.
+	MaxCells int
+}
+
+// GeohashCell is one of the cells in a geohash cover. Location is
+// LOCATION_INTERIOR if the cell is entirely inside the feature or
+// LOCATION_BOUNDARY if the boundary of the feature passes through (or
+// touches) the cell.
+
+type GeohashCell struct {
+	Hash     string
+	Location int
+}
+
+// coverEdge is an edge of one of the rings of the polygons being covered.
+
+type coverEdge struct {
+	a geom.Coord
+	b geom.Coord
+}
+
+// DefaultGeohashCoverOptions returns the options used when none are passed to
+// GeohashCover.
+
+func DefaultGeohashCoverOptions() *GeohashCoverOptions {
+
+	opts := &GeohashCoverOptions{
+		MinPrecision: 1,
+		MaxPrecision: 6,
+		MaxCells:     10000,
+	}
+
+	return opts
+}
+
+func (opts *GeohashCoverOptions) validate() error {
+
+	if opts.MinPrecision < 1 || opts.MinPrecision > GEOHASH_MAX_PRECISION {
+		return fmt.Errorf("Invalid minimum geohash precision %d", opts.MinPrecision)
+	}
+
+	if opts.MaxPrecision < opts.MinPrecision || opts.MaxPrecision > GEOHASH_MAX_PRECISION {
+		return fmt.Errorf("Invalid maximum geohash precision %d", opts.MaxPrecision)
+	}
+
+	if opts.MaxCells < 0 {
+		return errors.New("Invalid maximum number of geohash cells")
+	}
+
+	return nil
+}
+
+// GeohashCoverForFeature returns the geohash cells that cover the polygons in
+// f, sorted by hash. No cell in the cover is inside another one. Points and
+// lines are not covered.
+
+func GeohashCoverForFeature(f geojson.Feature, opts *GeohashCoverOptions) ([]*GeohashCell, error) {
+
+	polys, err := f.Polygons()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return GeohashCover(polys, opts)
+}
+
+// GeohashCover returns the geohash cells that cover polys. See
+// GeohashCoverForFeature for details.
+
+func GeohashCover(polys []geojson.Polygon, opts *GeohashCoverOptions) ([]*GeohashCell, error) {
+
+	if opts == nil {
+		opts = DefaultGeohashCoverOptions()
+	}
+
+	err := opts.validate()
+
+	if err != nil {
+		return nil, err
+	}
+
+	edges := make([]coverEdge, 0)
+
+	for _, p := range polys {
+
+		rings, unwrapped := locationRings(p)
+
+		// the rings of a polygon that crosses the antimeridian extend past
+		// +/-180 degrees so they are also tested shifted back in to range
+
+		offsets := []float64{0.0}
+
+		if unwrapped {
+			offsets = []float64{0.0, 360.0, -360.0}
+		}
+
+		for _, ring := range rings {
+
+			count := len(ring)
+
+			for i := 0; i < count; i++ {
+
+				a := ring[i]
+				b := ring[(i+1)%count]
+
+				for _, offset := range offsets {
+
+					e := coverEdge{
+						a: geom.Coord{X: a.X + offset, Y: a.Y},
+						b: geom.Coord{X: b.X + offset, Y: b.Y},
+					}
+
+					edges = append(edges, e)
+				}
+			}
+		}
+	}
+
+	c := &geohashCoverer{
+		opts:     opts,
+		prepared: PreparePolygons(polys),
+		cells:    make([]*GeohashCell, 0),
+	}
+
+	world := geohash.Box{MinLat: -90.0, MaxLat: 90.0, MinLng: -180.0, MaxLng: 180.0}
+
+	err = c.cover("", world, edges)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.cells, nil
+}
+
+// GeohashesForCoord returns the geohashes, from 1 to precision characters
+// long, of the cells that contain c. Looking up each of them in an index of
+// geohash covers will find every feature whose cover contains c.
+
+func GeohashesForCoord(c geom.Coord, precision int) []string {
+
+	if precision < 1 {
+		return []string{}
+	}
+
+	if precision > GEOHASH_MAX_PRECISION {
+		precision = GEOHASH_MAX_PRECISION
+	}
+
+	// geohashes are only defined for longitudes from -180 (inclusive) to 180
+	// (exclusive) and latitudes from -90 (inclusive) to 90 (exclusive) and
+	// the geohash package wraps around for values that are very close to the
+	// upper limits, so they are clamped to a value that is still inside the
+	// last cell at the maximum precision
+
+	lon := utils.NormalizeLongitude(c.X)
+
+	if lon >= 180.0 {
+		lon -= 360.0
+	}
+
+	lon = math.Max(-180.0, math.Min(lon, 180.0-1e-9))
+	lat := math.Max(-90.0, math.Min(c.Y, 90.0-1e-9))
+
+	hash := geohash.EncodeWithPrecision(lat, lon, uint(precision))
+	hashes := make([]string, precision)
+
+	for i := 0; i < precision; i++ {
+		hashes[i] = hash[:i+1]
+	}
+
+	return hashes
+}
+
+type geohashCoverer struct {
+	opts     *GeohashCoverOptions
+	prepared []geojson.Polygon
+	cells    []*GeohashCell
+}
+
+// cover adds the cells inside the cell hash (which is "" for the whole world)
+// to the cover. edges are the edges that intersect the cell; cells that none
+// of them intersect are either entirely inside or entirely outside the
+// polygons which can be decided by testing any point in them.
+
+func (c *geohashCoverer) cover(hash string, box geohash.Box, edges []coverEdge) error {
+
+	precision := len(hash)
+
+	rect := geom.Rect{
+		Min: geom.Coord{X: box.MinLng, Y: box.MinLat},
+		Max: geom.Coord{X: box.MaxLng, Y: box.MaxLat},
+	}
+
+	crossing := make([]coverEdge, 0)
+
+	for _, e := range edges {
+
+		if segmentIntersectsRect(e.a, e.b, rect) {
+			crossing = append(crossing, e)
+		}
+	}
+
+	if len(crossing) == 0 {
+
+		center := geom.Coord{X: (box.MinLng + box.MaxLng) / 2.0, Y: (box.MinLat + box.MaxLat) / 2.0}
+
+		contained, _ := PolygonsContainsCoord(c.prepared, center)
+
+		if !contained {
+			return nil
+		}
+
+		if precision >= c.opts.MinPrecision {
+			return c.add(hash, LOCATION_INTERIOR)
+		}
+
+	} else if precision == c.opts.MaxPrecision {
+		return c.add(hash, LOCATION_BOUNDARY)
+	}
+
+	for _, r := range geohash_alphabet {
+
+		child := hash + string(r)
+
+		err := c.cover(child, geohash.BoundingBox(child), crossing)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *geohashCoverer) add(hash string, location int) error {
+
+	if c.opts.MaxCells > 0 && len(c.cells) >= c.opts.MaxCells {
+		return fmt.Errorf("Geohash cover needs more than %d cells", c.opts.MaxCells)
+	}
+
+	cell := &GeohashCell{
+		Hash:     hash,
+		Location: location,
+	}
+
+	c.cells = append(c.cells, cell)
+	return nil
+}
+
+// segmentIntersectsRect reports whether any part of the segment from a to b,
+// including its end points, is inside or on the edge of r.
+
+func segmentIntersectsRect(a geom.Coord, b geom.Coord, r geom.Rect) bool {
+
+	if math.Max(a.X, b.X) < r.Min.X || math.Min(a.X, b.X) > r.Max.X {
+		return false
+	}
+
+	if math.Max(a.Y, b.Y) < r.Min.Y || math.Min(a.Y, b.Y) > r.Max.Y {
+		return false
+	}
+
+	// the bounding boxes overlap so the segment intersects r unless all of
+	// r's corners are on the same side of it
+
+	corners := []geom.Coord{
+		r.Min,
+		{X: r.Max.X, Y: r.Min.Y},
+		r.Max,
+		{X: r.Min.X, Y: r.Max.Y},
+	}
+
+	left := false
+	right := false
+
+	for _, corner := range corners {
+
+		switch orientation(a, b, corner) {
+		case 1:
+			left = true
+		case -1:
+			right = true
+		default:
+			return true
+		}
+	}
+
+	return left && right
+}
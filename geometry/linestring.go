@@ -0,0 +1,61 @@
+package geometry
+
+import (
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"math"
+)
+
+type LineString struct {
+	geojson.LineString `json:",omitempty"`
+	Line               geom.Path `json:"line"`
+}
+
+func (l LineString) Path() geom.Path {
+	return l.Line
+}
+
+// DistanceToCoord returns the shortest distance, in metres, between any of the
+// segments of l and c.
+
+func (l LineString) DistanceToCoord(c geom.Coord) float64 {
+
+	vertices := l.Line.Vertices()
+
+	switch len(vertices) {
+	case 0:
+		return math.Inf(1)
+	case 1:
+		return HaversineDistance(vertices[0], c)
+	}
+
+	d := math.Inf(1)
+
+	for i := 1; i < len(vertices); i++ {
+		d = math.Min(d, DistanceToSegment(c, vertices[i-1], vertices[i]))
+	}
+
+	return d
+}
+
+func newLineString(coords [][]float64) (LineString, error) {
+
+	line := geom.Path{}
+
+	for _, pt := range coords {
+
+		c, err := newCoord(pt)
+
+		if err != nil {
+			return LineString{}, err
+		}
+
+		line.AddVertex(c)
+	}
+
+	l := LineString{
+		Line: line,
+	}
+
+	return l, nil
+}
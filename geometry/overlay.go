@@ -0,0 +1,640 @@
+package geometry
+
+import (
+	pm_geojson "github.com/paulmach/go.geojson"
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/utils"
+	"math"
+	"sort"
+)
+
+// Overlay operations
+
+const (
+	OVERLAY_INTERSECTION int = iota
+	OVERLAY_UNION
+	OVERLAY_DIFFERENCE
+	OVERLAY_SYMMETRIC_DIFFERENCE
+)
+
+// Intersection returns the parts of a that are also in b.
+
+func Intersection(a []geojson.Polygon, b []geojson.Polygon) []geojson.Polygon {
+	return Overlay(a, b, OVERLAY_INTERSECTION)
+}
+
+// Union returns the parts of a or b, merging polygons that overlap or share
+// edges.
+
+func Union(a []geojson.Polygon, b []geojson.Polygon) []geojson.Polygon {
+	return Overlay(a, b, OVERLAY_UNION)
+}
+
+// UnionAll merges any number of lists of polygons, for example the polygons of
+// each of a feature's children.
+
+func UnionAll(polys ...[]geojson.Polygon) []geojson.Polygon {
+
+	result := make([]geojson.Polygon, 0)
+
+	for _, p := range polys {
+		result = Union(result, p)
+	}
+
+	return result
+}
+
+// Difference returns the parts of a that are not in b.
+
+func Difference(a []geojson.Polygon, b []geojson.Polygon) []geojson.Polygon {
+	return Overlay(a, b, OVERLAY_DIFFERENCE)
+}
+
+// SymmetricDifference returns the parts of a or b that are not in both.
+
+func SymmetricDifference(a []geojson.Polygon, b []geojson.Polygon) []geojson.Polygon {
+	return Overlay(a, b, OVERLAY_SYMMETRIC_DIFFERENCE)
+}
+
+// Overlay performs one of the OVERLAY_ operations on a and b, each of which is
+// treated as a single (valid) polygonal geometry. The polygons returned have
+// counter-clockwise exterior rings and clockwise interior rings, as RFC 7946
+// recommends, and may be passed to GeometryForPolygons. RFC 7946 also
+// recommends splitting polygons at the antimeridian but these are not split:
+// a ring that crosses it jumps from 180 to -180 degrees (or the other way),
+// which is how the rings this package reads cross it too.
+//
+// Rings are split wherever they touch or cross the other geometry and each
+// piece is kept, dropped or reversed depending on whether it is inside,
+// outside or along the boundary of the other geometry. The pieces that are
+// kept are then linked back together in to rings.
+
+func Overlay(a []geojson.Polygon, b []geojson.Polygon, op int) []geojson.Polygon {
+
+	bounds := BoundingBoxesForPolygons(a).Bounds()
+	bounds = append(bounds, BoundingBoxesForPolygons(b).Bounds()...)
+
+	frame := newRelateFrame(bounds)
+
+	ga := newOverlayGeometry(a, frame)
+	gb := newOverlayGeometry(b, frame)
+
+	edges := make([]overlayEdge, 0)
+
+	for _, e := range ga.classify(gb) {
+
+		switch e.location {
+		case overlayInside:
+
+			switch op {
+			case OVERLAY_INTERSECTION:
+				edges = append(edges, e)
+			case OVERLAY_SYMMETRIC_DIFFERENCE:
+				edges = append(edges, e.reverse())
+			}
+
+		case overlayOutside:
+
+			switch op {
+			case OVERLAY_UNION, OVERLAY_DIFFERENCE, OVERLAY_SYMMETRIC_DIFFERENCE:
+				edges = append(edges, e)
+			}
+
+		case overlaySharedSame:
+
+			// only a's copy of a shared edge is used
+
+			switch op {
+			case OVERLAY_INTERSECTION, OVERLAY_UNION:
+				edges = append(edges, e)
+			}
+
+		case overlaySharedOpposite:
+
+			switch op {
+			case OVERLAY_DIFFERENCE:
+				edges = append(edges, e)
+			}
+		}
+	}
+
+	for _, e := range gb.classify(ga) {
+
+		switch e.location {
+		case overlayInside:
+
+			switch op {
+			case OVERLAY_INTERSECTION:
+				edges = append(edges, e)
+			case OVERLAY_DIFFERENCE, OVERLAY_SYMMETRIC_DIFFERENCE:
+				edges = append(edges, e.reverse())
+			}
+
+		case overlayOutside:
+
+			switch op {
+			case OVERLAY_UNION, OVERLAY_SYMMETRIC_DIFFERENCE:
+				edges = append(edges, e)
+			}
+		}
+	}
+
+	return buildOverlayPolygons(edges)
+}
+
+// GeometryForPolygons returns polys as a GeoJSON Polygon, if there is only
+// one, or a MultiPolygon.
+
+func GeometryForPolygons(polys []geojson.Polygon) *pm_geojson.Geometry {
+
+	coords := make([][][][]float64, 0)
+
+	for _, p := range polys {
+
+		rings := [][][]float64{
+			ringCoordinates(p.ExteriorRing()),
+		}
+
+		for _, r := range p.InteriorRings() {
+			rings = append(rings, ringCoordinates(r))
+		}
+
+		coords = append(coords, rings)
+	}
+
+	if len(coords) == 1 {
+		return pm_geojson.NewPolygonGeometry(coords[0])
+	}
+
+	return pm_geojson.NewMultiPolygonGeometry(coords...)
+}
+
+func ringCoordinates(ring geom.Polygon) [][]float64 {
+
+	coords := make([][]float64, 0)
+
+	for _, v := range ring.Path.Vertices() {
+		coords = append(coords, []float64{v.X, v.Y})
+	}
+
+	return coords
+}
+
+const (
+	overlayInside int = iota
+	overlayOutside
+	overlaySharedSame
+	overlaySharedOpposite
+)
+
+type overlayEdge struct {
+	a        geom.Coord
+	b        geom.Coord
+	location int
+}
+
+func (e overlayEdge) reverse() overlayEdge {
+	return overlayEdge{a: e.b, b: e.a, location: e.location}
+}
+
+// overlayGeometry is a relateGeometry whose rings are closed and oriented so
+// that the interior of the geometry is always to the left of an edge.
+
+type overlayGeometry struct {
+	*relateGeometry
+}
+
+func newOverlayGeometry(polys []geojson.Polygon, frame *relateFrame) *overlayGeometry {
+
+	g := emptyRelateGeometry()
+
+	for _, p := range polys {
+
+		rings := frame.rings(p)
+
+		for i, ring := range rings {
+
+			ring = closeRing(ring)
+
+			if len(ring) < 4 {
+				continue
+			}
+
+			area := planarRingArea(ring)
+
+			if (i == 0 && area < 0.0) || (i > 0 && area > 0.0) {
+				ring = reverseVertices(ring)
+			}
+
+			rings[i] = ring
+		}
+
+		if len(rings[0]) < 4 {
+			continue
+		}
+
+		g.addPolygon(rings)
+	}
+
+	g.index = newSegmentIndex(g.segments)
+
+	return &overlayGeometry{g}
+}
+
+// classify splits g's edges wherever they meet other and works out where each
+// piece lies relative to other.
+
+func (g *overlayGeometry) classify(other *overlayGeometry) []overlayEdge {
+
+	edges := make([]overlayEdge, 0)
+
+	for _, s := range g.segments {
+
+		nodes := make([]geom.Coord, 0)
+
+		for _, o := range other.index.queryRange(s.a, s.b) {
+			nodes = append(nodes, overlayNodes(s.a, s.b, o.a, o.b)...)
+		}
+
+		vertices := splitSegment(s.a, s.b, nodes)
+
+		for i := 1; i < len(vertices); i++ {
+
+			a := vertices[i-1]
+			b := vertices[i]
+
+			mid := geom.Coord{X: (a.X + b.X) / 2.0, Y: (a.Y + b.Y) / 2.0}
+			loc, _ := other.locate(mid)
+
+			e := overlayEdge{a: a, b: b}
+
+			switch loc {
+			case LOCATION_INTERIOR:
+				e.location = overlayInside
+			case LOCATION_EXTERIOR:
+				e.location = overlayOutside
+			default:
+
+				// the edge runs along one of other's edges; if
+				// other's interior is on the left it runs in the
+				// same direction
+
+				left := sidePoints(a, b)[0]
+				left_loc, _ := other.locate(left)
+
+				if left_loc == LOCATION_INTERIOR {
+					e.location = overlaySharedSame
+				} else {
+					e.location = overlaySharedOpposite
+				}
+			}
+
+			edges = append(edges, e)
+		}
+	}
+
+	return edges
+}
+
+// splitSegment returns the vertices of the segment a -> b once it has been split
+// at nodes, in order from a to b.
+
+func splitSegment(a geom.Coord, b geom.Coord, nodes []geom.Coord) []geom.Coord {
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return projectOnSegment(nodes[i], a, b) < projectOnSegment(nodes[j], a, b)
+	})
+
+	vertices := []geom.Coord{a}
+
+	for _, n := range append(nodes, b) {
+
+		if n == a || n == vertices[len(vertices)-1] {
+			continue
+		}
+
+		vertices = append(vertices, n)
+	}
+
+	return vertices
+}
+
+// overlayNodes returns the points where the segments a0 -> a1 and b0 -> b1
+// touch or cross. The result doesn't depend on the order the segments are
+// passed in, so splitting either of them produces exactly the same vertices,
+// and points within RELATE_TOLERANCE of an endpoint are snapped to it.
+
+func overlayNodes(a0 geom.Coord, a1 geom.Coord, b0 geom.Coord, b1 geom.Coord) []geom.Coord {
+
+	if coordLess(b0, a0) || (b0 == a0 && coordLess(b1, a1)) {
+		a0, a1, b0, b1 = b0, b1, a0, a1
+	}
+
+	nodes := make([]geom.Coord, 0)
+	endpoints := []geom.Coord{a0, a1, b0, b1}
+
+	dx := a1.X - a0.X
+	dy := a1.Y - a0.Y
+	ex := b1.X - b0.X
+	ey := b1.Y - b0.Y
+
+	len_a := math.Hypot(dx, dy)
+	len_b := math.Hypot(ex, ey)
+
+	if len_a == 0.0 || len_b == 0.0 {
+		return nodes
+	}
+
+	denom := dx*ey - dy*ex
+
+	if math.Abs(denom) <= 1e-12*len_a*len_b {
+
+		// parallel, and possibly collinear
+
+		for _, c := range endpoints[0:2] {
+
+			if onSegment(c, b0, b1) {
+				nodes = append(nodes, c)
+			}
+		}
+
+		for _, c := range endpoints[2:4] {
+
+			if onSegment(c, a0, a1) {
+				nodes = append(nodes, c)
+			}
+		}
+
+		return nodes
+	}
+
+	for _, t := range segmentIntersections(a0, a1, b0, b1) {
+
+		c := pointAlong(a0, a1, t)
+
+		for _, e := range endpoints {
+
+			if closeTo(c, e) {
+				c = e
+				break
+			}
+		}
+
+		nodes = append(nodes, c)
+	}
+
+	return nodes
+}
+
+func coordLess(a geom.Coord, b geom.Coord) bool {
+
+	if a.X != b.X {
+		return a.X < b.X
+	}
+
+	return a.Y < b.Y
+}
+
+// buildOverlayPolygons links edges in to rings and then groups those rings in
+// to polygons.
+
+func buildOverlayPolygons(edges []overlayEdge) []geojson.Polygon {
+
+	outgoing := make(map[geom.Coord][]int)
+
+	for i, e := range edges {
+		outgoing[e.a] = append(outgoing[e.a], i)
+	}
+
+	used := make([]bool, len(edges))
+
+	shells := make([][]geom.Coord, 0)
+	holes := make([][]geom.Coord, 0)
+
+	for i := range edges {
+
+		if used[i] {
+			continue
+		}
+
+		ring := []geom.Coord{edges[i].a}
+		current := i
+		ok := true
+
+		for {
+
+			used[current] = true
+
+			e := edges[current]
+			ring = append(ring, e.b)
+
+			next := nextOverlayEdge(edges, outgoing[e.b], e, used, i)
+
+			if next == i {
+				break
+			}
+
+			if next == -1 {
+				ok = false
+				break
+			}
+
+			current = next
+		}
+
+		if !ok {
+			continue
+		}
+
+		ring = removeCollinearVertices(ring)
+
+		if len(ring) < 4 {
+			continue
+		}
+
+		area := planarRingArea(ring)
+
+		if area > 0.0 {
+			shells = append(shells, ring)
+		} else if area < 0.0 {
+			holes = append(holes, ring)
+		}
+	}
+
+	// assign each hole to the smallest shell that contains it
+
+	shell_holes := make([][][]geom.Coord, len(shells))
+
+	for _, h := range holes {
+
+		// a point just inside the polygon, next to the hole
+
+		test := sidePoints(h[0], h[1])[0]
+
+		best := -1
+		best_area := 0.0
+
+		for i, s := range shells {
+
+			if !ringContainsCoord(s, test) {
+				continue
+			}
+
+			area := planarRingArea(s)
+
+			if best == -1 || area < best_area {
+				best = i
+				best_area = area
+			}
+		}
+
+		if best != -1 {
+			shell_holes[best] = append(shell_holes[best], h)
+		}
+	}
+
+	polys := make([]geojson.Polygon, 0)
+
+	for i, s := range shells {
+
+		interior := make([]geom.Polygon, 0)
+
+		for _, h := range shell_holes[i] {
+			interior = append(interior, overlayRing(h))
+		}
+
+		polys = append(polys, NewPolygon(overlayRing(s), interior...))
+	}
+
+	return polys
+}
+
+// nextOverlayEdge picks which of the unused edges leaving the end of e to
+// follow. To keep the interior on the left, and to split rings that touch at a
+// single vertex, it chooses the first edge found turning clockwise from the
+// reverse of e. If the edge that started the ring is a candidate it is
+// returned as soon as it is the best choice.
+
+func nextOverlayEdge(edges []overlayEdge, candidates []int, e overlayEdge, used []bool, start int) int {
+
+	back := math.Atan2(e.a.Y-e.b.Y, e.a.X-e.b.X)
+
+	best := -1
+	best_angle := 0.0
+
+	for _, i := range candidates {
+
+		if used[i] && i != start {
+			continue
+		}
+
+		c := edges[i]
+		angle := back - math.Atan2(c.b.Y-c.a.Y, c.b.X-c.a.X)
+
+		for angle <= 0.0 {
+			angle += 2.0 * math.Pi
+		}
+
+		for angle > 2.0*math.Pi {
+			angle -= 2.0 * math.Pi
+		}
+
+		if best == -1 || angle < best_angle {
+			best = i
+			best_angle = angle
+		}
+	}
+
+	return best
+}
+
+// removeCollinearVertices removes vertices, from a closed ring, that were only
+// added when splitting edges and sit on a straight line between their
+// neighbours.
+
+func removeCollinearVertices(ring []geom.Coord) []geom.Coord {
+
+	vertices := ring[0 : len(ring)-1]
+	count := len(vertices)
+
+	if count < 3 {
+		return ring
+	}
+
+	kept := make([]geom.Coord, 0)
+
+	for i, v := range vertices {
+
+		prev := vertices[(i+count-1)%count]
+		next := vertices[(i+1)%count]
+
+		cross := (v.X-prev.X)*(next.Y-prev.Y) - (v.Y-prev.Y)*(next.X-prev.X)
+		dot := (v.X-prev.X)*(next.X-v.X) + (v.Y-prev.Y)*(next.Y-v.Y)
+
+		if math.Abs(cross) <= 1e-18 && dot > 0.0 {
+			continue
+		}
+
+		kept = append(kept, v)
+	}
+
+	if len(kept) < 3 {
+		return ring
+	}
+
+	return append(kept, kept[0])
+}
+
+func ringContainsCoord(ring []geom.Coord, c geom.Coord) bool {
+
+	inside := false
+	count := len(ring)
+
+	for i, j := 0, count-1; i < count; j, i = i, i+1 {
+
+		a := ring[i]
+		b := ring[j]
+
+		if (a.Y > c.Y) != (b.Y > c.Y) && c.X < (b.X-a.X)*(c.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+func closeRing(vertices []geom.Coord) []geom.Coord {
+
+	if len(vertices) == 0 || vertices[0] == vertices[len(vertices)-1] {
+		return vertices
+	}
+
+	return append(vertices, vertices[0])
+}
+
+func reverseVertices(vertices []geom.Coord) []geom.Coord {
+
+	reversed := make([]geom.Coord, len(vertices))
+
+	for i, v := range vertices {
+		reversed[len(vertices)-1-i] = v
+	}
+
+	return reversed
+}
+
+// overlayRing converts a ring back from the frame it was compared in to
+// regular longitudes. A ring that crosses the antimeridian is not split so
+// consecutive vertices on either side of it are more than 180 degrees apart.
+
+func overlayRing(vertices []geom.Coord) geom.Polygon {
+
+	ring := geom.Polygon{}
+
+	for _, v := range vertices {
+		ring.AddVertex(geom.Coord{X: utils.NormalizeLongitude(v.X), Y: v.Y})
+	}
+
+	return ring
+}
@@ -0,0 +1,49 @@
+package geometry
+
+import (
+	"fmt"
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+)
+
+type Point struct {
+	geojson.Point `json:",omitempty"`
+	Coordinate    geom.Coord `json:"coordinate"`
+}
+
+func (p Point) Coord() geom.Coord {
+	return p.Coordinate
+}
+
+// DistanceToCoord returns the distance, in metres, between p and c.
+
+func (p Point) DistanceToCoord(c geom.Coord) float64 {
+	return HaversineDistance(p.Coordinate, c)
+}
+
+func newPoint(coords []float64) (Point, error) {
+
+	c, err := newCoord(coords)
+
+	if err != nil {
+		return Point{}, err
+	}
+
+	pt := Point{
+		Coordinate: c,
+	}
+
+	return pt, nil
+}
+
+// newCoord returns the coordinate for a GeoJSON position, which must have at
+// least two values. Any values after the first two are ignored.
+
+func newCoord(coords []float64) (geom.Coord, error) {
+
+	if len(coords) < 2 {
+		return geom.Coord{}, fmt.Errorf("Position %v has fewer than two values", coords)
+	}
+
+	return geom.Coord{X: coords[0], Y: coords[1]}, nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/skelterjohn/geom"
 	"github.com/tidwall/gjson"
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/utils"
 	_ "log"
 	_ "time"
 )
@@ -15,6 +16,46 @@ type Polygon struct {
 	geojson.Polygon `json:",omitempty"`
 	Exterior        geom.Polygon   `json:"exterior"`
 	Interior        []geom.Polygon `json:"interior"`
+	// set by NewPolygon if the exterior ring crosses the antimeridian
+	unwrapped []geom.Polygon
+}
+
+// NewPolygon returns a Polygon for exterior and interior. Unlike a Polygon
+// literal, it will also check whether the exterior ring crosses the
+// antimeridian and if so prepare the rings so that ContainsCoord works.
+
+func NewPolygon(exterior geom.Polygon, interior ...geom.Polygon) Polygon {
+
+	polygon := Polygon{
+		Exterior: exterior,
+		Interior: interior,
+	}
+
+	ext_vertices := exterior.Path.Vertices()
+
+	if !crossesAntimeridian(ext_vertices) {
+		return polygon
+	}
+
+	// every ring is unwrapped relative to the start of the exterior ring so
+	// that they all share the same frame of reference
+
+	ref := ext_vertices[0].X
+	unwrapped := make([]geom.Polygon, 0)
+
+	for _, ring := range append([]geom.Polygon{exterior}, interior...) {
+
+		poly := geom.Polygon{}
+
+		for _, v := range unwrapVertices(ring.Path.Vertices(), ref) {
+			poly.AddVertex(v)
+		}
+
+		unwrapped = append(unwrapped, poly)
+	}
+
+	polygon.unwrapped = unwrapped
+	return polygon
 }
 
 func (p Polygon) ExteriorRing() geom.Polygon {
@@ -27,6 +68,10 @@ func (p Polygon) InteriorRings() []geom.Polygon {
 
 func (p Polygon) ContainsCoord(c geom.Coord) bool {
 
+	if p.unwrapped != nil {
+		return p.containsUnwrappedCoord(c)
+	}
+
 	ext := p.ExteriorRing()
 
 	if !ext.ContainsCoord(c) {
@@ -43,9 +88,72 @@ func (p Polygon) ContainsCoord(c geom.Coord) bool {
 	return true
 }
 
+// containsUnwrappedCoord tests c, and c shifted east and west by 360 degrees,
+// against the unwrapped rings of a polygon that crosses the antimeridian.
+
+func (p Polygon) containsUnwrappedCoord(c geom.Coord) bool {
+
+	for _, offset := range []float64{0.0, 360.0, -360.0} {
+
+		shifted := geom.Coord{X: c.X + offset, Y: c.Y}
+
+		if !p.unwrapped[0].ContainsCoord(shifted) {
+			continue
+		}
+
+		contained := true
+
+		for _, int := range p.unwrapped[1:] {
+
+			if int.ContainsCoord(shifted) {
+				contained = false
+				break
+			}
+		}
+
+		if contained {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasGeometry reports whether f has a non-null geometry. RFC 7946 allows a
+// feature's geometry to be null, for example for unlocated places.
+
+func HasGeometry(f geojson.Feature) bool {
+
+	geom_rsp := gjson.GetBytes(f.Bytes(), "geometry")
+	return geom_rsp.Exists() && geom_rsp.Type != gjson.Null
+}
+
+// GeometryForFeature parses the geometry of f. It returns nil, and no error,
+// if the geometry is null.
+
 func GeometryForFeature(f geojson.Feature) (*pm_geojson.Geometry, error) {
+
 	geom_rsp := gjson.GetBytes(f.Bytes(), "geometry")
-	return pm_geojson.UnmarshalGeometry([]byte(geom_rsp.String()))
+
+	if geom_rsp.Exists() && geom_rsp.Type == gjson.Null {
+		return nil, nil
+	}
+
+	g, err := pm_geojson.UnmarshalGeometry([]byte(geom_rsp.String()))
+
+	if err != nil {
+
+		geom_err := &geojson.InvalidGeometryError{
+			Path:      "geometry",
+			FeatureId: utils.FeatureId(f.Bytes()),
+			Message:   "Failed to parse geometry",
+			Err:       err,
+		}
+
+		return nil, geom_err
+	}
+
+	return g, nil
 }
 
 func PolygonsForFeature(f geojson.Feature) ([]geojson.Polygon, error) {
@@ -56,110 +164,267 @@ func PolygonsForFeature(f geojson.Feature) ([]geojson.Polygon, error) {
 		return nil, err
 	}
 
-	polys := make([]geojson.Polygon, 0)
+	return PolygonsForGeometry(f, g)
+}
+
+// PolygonsForGeometry returns the polygons in a geometry that has already been
+// parsed, for example by GeometryForFeature. Points and lines are not
+// polygons and are excluded; use MembersForGeometry to get at them.
+
+func PolygonsForGeometry(f geojson.Feature, g *pm_geojson.Geometry) ([]geojson.Polygon, error) {
+
+	members, err := MembersForGeometry(f, g)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return PolygonsForMembers(members), nil
+}
+
+func MembersForFeature(f geojson.Feature) ([]geojson.Geometry, error) {
+
+	g, err := GeometryForFeature(f)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return MembersForGeometry(f, g)
+}
+
+// MembersForGeometry splits a geometry in to its individual members, in the
+// order they appear in the document. Each member is a geojson.Point, a
+// geojson.LineString or a geojson.Polygon: a MultiPolygon yields one Polygon
+// for each of its polygons, and so on, and the members of a GeometryCollection
+// are flattened in to a single list. f is only used to annotate errors. A nil
+// (null) geometry yields an empty list. Points and lines with no positions,
+// polygons with no rings or an empty exterior ring and empty interior rings
+// are skipped. Positions with fewer than two values are an error.
+
+func MembersForGeometry(f geojson.Feature, g *pm_geojson.Geometry) ([]geojson.Geometry, error) {
+
+	members := make([]geojson.Geometry, 0)
+
+	if g == nil {
+		return members, nil
+	}
 
 	switch g.Type {
 
-	case "LineString":
+	case "Point":
 
-		exterior_ring := newRing(g.LineString)
+		// RFC 7946 allows empty coordinates, which are treated as an empty
+		// geometry, but not empty positions
 
-		polygon := Polygon{
-			Exterior: exterior_ring,
+		if len(g.Point) == 0 {
+			break
 		}
 
-		polys = []geojson.Polygon{polygon}
+		pt, err := newPoint(g.Point)
 
-	case "Polygon":
+		if err != nil {
+			return nil, invalidCoordinatesError(f, err)
+		}
 
-		polygon := newPolygon(g.Polygon)
-		polys = []geojson.Polygon{polygon}
+		members = append(members, pt)
 
-	case "MultiPolygon":
+	case "MultiPoint":
 
-		for _, poly := range g.MultiPolygon {
-			polygon := newPolygon(poly)
-			polys = append(polys, polygon)
-		}
+		for _, coords := range g.MultiPoint {
 
-	case "Point":
+			pt, err := newPoint(coords)
 
-		lat := g.Point[1]
-		lon := g.Point[0]
+			if err != nil {
+				return nil, invalidCoordinatesError(f, err)
+			}
 
-		pt := []float64{
-			lon,
-			lat,
+			members = append(members, pt)
 		}
 
-		coords := [][]float64{
-			pt, pt,
-			pt, pt,
-			pt,
+	case "LineString":
+
+		if len(g.LineString) == 0 {
+			break
 		}
 
-		exterior_ring := newRing(coords)
+		line, err := newLineString(g.LineString)
 
 		if err != nil {
-			return nil, err
+			return nil, invalidCoordinatesError(f, err)
 		}
 
-		interior_rings := make([]geom.Polygon, 0)
+		members = append(members, line)
+
+	case "MultiLineString":
+
+		for _, coords := range g.MultiLineString {
 
-		polygon := Polygon{
-			Exterior: exterior_ring,
-			Interior: interior_rings,
+			if len(coords) == 0 {
+				continue
+			}
+
+			line, err := newLineString(coords)
+
+			if err != nil {
+				return nil, invalidCoordinatesError(f, err)
+			}
+
+			members = append(members, line)
 		}
 
-		polys = []geojson.Polygon{polygon}
-		return polys, nil
+	case "Polygon":
 
-	case "MultiPoint":
+		if len(g.Polygon) == 0 || len(g.Polygon[0]) == 0 {
+			break
+		}
 
-		exterior_ring := newRing(g.MultiPoint)
+		poly, err := newPolygon(g.Polygon)
 
-		polygon := Polygon{
-			Exterior: exterior_ring,
+		if err != nil {
+			return nil, invalidCoordinatesError(f, err)
 		}
 
-		polys = []geojson.Polygon{polygon}
+		members = append(members, poly)
+
+	case "MultiPolygon":
+
+		for _, rings := range g.MultiPolygon {
+
+			if len(rings) == 0 || len(rings[0]) == 0 {
+				continue
+			}
+
+			poly, err := newPolygon(rings)
+
+			if err != nil {
+				return nil, invalidCoordinatesError(f, err)
+			}
+
+			members = append(members, poly)
+		}
+
+	case "GeometryCollection":
+
+		for _, member := range g.Geometries {
+
+			member_members, err := MembersForGeometry(f, member)
+
+			if err != nil {
+				return nil, err
+			}
+
+			members = append(members, member_members...)
+		}
 
 	default:
 
-		msg := fmt.Sprintf("Invalid geometry type '%s'", g.Type)
-		return nil, errors.New(msg)
+		geom_err := &geojson.InvalidGeometryError{
+			Path:      "geometry.type",
+			FeatureId: utils.FeatureId(f.Bytes()),
+			Message:   fmt.Sprintf("Invalid geometry type '%s'", g.Type),
+		}
+
+		return nil, geom_err
+	}
+
+	return members, nil
+}
+
+func PolygonsForMembers(members []geojson.Geometry) []geojson.Polygon {
+
+	polys := make([]geojson.Polygon, 0)
+
+	for _, m := range members {
+
+		poly, ok := m.(geojson.Polygon)
+
+		if ok {
+			polys = append(polys, poly)
+		}
 	}
 
-	return polys, nil
+	return polys
+}
+
+// MemberType returns "Point", "LineString" or "Polygon" depending on the type
+// of m or "" if it is none of those.
+
+func MemberType(m geojson.Geometry) string {
+
+	switch m.(type) {
+	case geojson.Point:
+		return "Point"
+	case geojson.LineString:
+		return "LineString"
+	case geojson.Polygon:
+		return "Polygon"
+	default:
+		return ""
+	}
 }
 
-func newRing(coords [][]float64) geom.Polygon {
+func newRing(coords [][]float64) (geom.Polygon, error) {
 
 	poly := geom.Polygon{}
 
 	for _, pt := range coords {
-		poly.AddVertex(geom.Coord{X: pt[0], Y: pt[1]})
+
+		c, err := newCoord(pt)
+
+		if err != nil {
+			return poly, err
+		}
+
+		poly.AddVertex(c)
 	}
 
-	return poly
+	return poly, nil
 }
 
-func newPolygon(rings [][][]float64) Polygon {
+func newPolygon(rings [][][]float64) (Polygon, error) {
+
+	if len(rings) == 0 {
+		return Polygon{}, errors.New("Polygon has no rings")
+	}
+
+	exterior, err := newRing(rings[0])
+
+	if err != nil {
+		return Polygon{}, err
+	}
 
-	exterior := newRing(rings[0])
 	interior := make([]geom.Polygon, 0)
 
-	if len(rings) > 1 {
+	for _, coords := range rings[1:] {
+
+		if len(coords) == 0 {
+			continue
+		}
 
-		for _, coords := range rings[1:] {
-			interior = append(interior, newRing(coords))
+		ring, err := newRing(coords)
+
+		if err != nil {
+			return Polygon{}, err
 		}
+
+		interior = append(interior, ring)
 	}
 
-	polygon := Polygon{
-		Exterior: exterior,
-		Interior: interior,
+	return NewPolygon(exterior, interior...), nil
+}
+
+// invalidCoordinatesError wraps an error returned by one of the functions
+// that build members from GeoJSON coordinates.
+
+func invalidCoordinatesError(f geojson.Feature, err error) error {
+
+	geom_err := &geojson.InvalidGeometryError{
+		Path:      "geometry.coordinates",
+		FeatureId: utils.FeatureId(f.Bytes()),
+		Message:   "Invalid coordinates",
+		Err:       err,
 	}
 
-	return polygon
+	return geom_err
 }
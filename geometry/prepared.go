@@ -0,0 +1,338 @@
+package geometry
+
+import (
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"math"
+)
+
+// PREPARED_EDGES_PER_BUCKET is the average number of ring edges assigned to
+// each of the vertical strips a prepared ring is divided in to.
+
+const PREPARED_EDGES_PER_BUCKET = 4
+
+// PREPARED_MAX_BUCKETS is the maximum number of strips a prepared ring is
+// divided in to.
+
+const PREPARED_MAX_BUCKETS = 65536
+
+// PREPARED_MAX_ENTRIES is the maximum number of times, on average, that an edge
+// may be assigned to a strip. Rings whose edges are long relative to the width
+// of the ring use fewer, wider strips so that preparing them doesn't consume
+// quadratic amounts of memory.
+
+const PREPARED_MAX_ENTRIES = 8
+
+// PreparedPolygon wraps a geojson.Polygon with an index of the edges of its
+// rings so that repeated calls to ContainsCoord are fast. It gives exactly the
+// same answers as the ContainsCoord method of the Polygon it was prepared from
+// (including for coordinates on the edge of a ring, and for polygons that
+// cross the antimeridian) and, once prepared, is safe to use from multiple
+// goroutines.
+
+type PreparedPolygon struct {
+	geojson.Polygon
+	// the exterior ring followed by the interior rings, unwrapped if the
+	// polygon crosses the antimeridian
+	rings     []*preparedRing
+	unwrapped bool
+}
+
+// preparedRing divides the x extent of a ring in to vertical strips and
+// records which edges span each strip. geom.Polygon.ContainsCoord casts a ray
+// upwards from a coordinate and counts the edges it crosses; only the edges
+// that span the coordinate's longitude can be crossed, which are exactly the
+// edges recorded for the strip the coordinate falls in.
+
+type preparedRing struct {
+	min_x    float64
+	max_x    float64
+	max_y    float64
+	width    float64
+	segments []geom.Segment
+	// the edges in strip i are index[offsets[i]:offsets[i+1]]
+	offsets []int32
+	index   []int32
+}
+
+// NewPreparedPolygon indexes the rings of p. If p is already a
+// *PreparedPolygon it is returned as-is.
+
+func NewPreparedPolygon(p geojson.Polygon) *PreparedPolygon {
+
+	prepared, ok := p.(*PreparedPolygon)
+
+	if ok {
+		return prepared
+	}
+
+	var unwrapped []geom.Polygon
+
+	switch poly := p.(type) {
+	case Polygon:
+		unwrapped = poly.unwrapped
+	case *Polygon:
+		unwrapped = poly.unwrapped
+	}
+
+	rings := unwrapped
+
+	if rings == nil {
+		rings = append([]geom.Polygon{p.ExteriorRing()}, p.InteriorRings()...)
+	}
+
+	prepared_rings := make([]*preparedRing, len(rings))
+
+	for i, ring := range rings {
+		prepared_rings[i] = newPreparedRing(ring.Path.Vertices())
+	}
+
+	prepared = &PreparedPolygon{
+		Polygon:   p,
+		rings:     prepared_rings,
+		unwrapped: unwrapped != nil,
+	}
+
+	return prepared
+}
+
+// PreparePolygons returns a *PreparedPolygon for each of polys.
+
+func PreparePolygons(polys []geojson.Polygon) []geojson.Polygon {
+
+	prepared := make([]geojson.Polygon, len(polys))
+
+	for i, p := range polys {
+		prepared[i] = NewPreparedPolygon(p)
+	}
+
+	return prepared
+}
+
+func (p *PreparedPolygon) ContainsCoord(c geom.Coord) bool {
+
+	if !p.unwrapped {
+		return p.containsCoord(c)
+	}
+
+	// see Polygon.containsUnwrappedCoord
+
+	for _, offset := range []float64{0.0, 360.0, -360.0} {
+
+		shifted := geom.Coord{X: c.X + offset, Y: c.Y}
+
+		if p.containsCoord(shifted) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *PreparedPolygon) containsCoord(c geom.Coord) bool {
+
+	if !p.rings[0].containsCoord(c) {
+		return false
+	}
+
+	for _, int := range p.rings[1:] {
+
+		if int.containsCoord(c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newPreparedRing(vertices []geom.Coord) *preparedRing {
+
+	r := &preparedRing{
+		min_x: math.Inf(1),
+		max_x: math.Inf(-1),
+		max_y: math.Inf(-1),
+	}
+
+	count := len(vertices)
+	segments := make([]geom.Segment, 0, count)
+
+	max_abs_y := 0.0
+
+	for i := 0; i < count; i++ {
+
+		// the same edges, in the same order, as geom.Polygon.Segment
+
+		a := vertices[i]
+		b := vertices[(i+1)%count]
+
+		// edges that are vertical, or whose longitudes aren't finite, are
+		// never counted as crossings by geom.Polygon.ContainsCoord because
+		// their intersection parameters are infinite or NaN
+
+		if a.X == b.X || !isFinite(a.X) || !isFinite(b.X) {
+			continue
+		}
+
+		segments = append(segments, geom.Segment{A: a, B: b})
+
+		r.min_x = math.Min(r.min_x, math.Min(a.X, b.X))
+		r.max_x = math.Max(r.max_x, math.Max(a.X, b.X))
+
+		for _, y := range []float64{a.Y, b.Y} {
+
+			// NaN latitudes are never counted either
+
+			if math.IsNaN(y) {
+				continue
+			}
+
+			r.max_y = math.Max(r.max_y, y)
+			max_abs_y = math.Max(max_abs_y, math.Abs(y))
+		}
+	}
+
+	r.segments = segments
+
+	if len(segments) == 0 {
+		return r
+	}
+
+	// the ray from a coordinate north of a ring can't cross any of its edges
+	// but the crossing test is subject to rounding so the ring's northern
+	// edge is padded by more than any error it could introduce
+
+	r.max_y += 1e-9 * (1.0 + 3.0*max_abs_y)
+
+	buckets := len(segments) / PREPARED_EDGES_PER_BUCKET
+
+	if buckets < 1 {
+		buckets = 1
+	}
+
+	if buckets > PREPARED_MAX_BUCKETS {
+		buckets = PREPARED_MAX_BUCKETS
+	}
+
+	for {
+
+		r.width = (r.max_x - r.min_x) / float64(buckets)
+
+		if buckets == 1 || r.countEntries(buckets) <= PREPARED_MAX_ENTRIES*len(segments) {
+			break
+		}
+
+		buckets = buckets / 2
+	}
+
+	offsets := make([]int32, buckets+1)
+
+	for _, s := range segments {
+
+		lo, hi := r.bucketRange(s, buckets)
+
+		for b := lo; b <= hi; b++ {
+			offsets[b+1] += 1
+		}
+	}
+
+	for b := 0; b < buckets; b++ {
+		offsets[b+1] += offsets[b]
+	}
+
+	index := make([]int32, offsets[buckets])
+	next := make([]int32, buckets)
+	copy(next, offsets[:buckets])
+
+	for i, s := range segments {
+
+		lo, hi := r.bucketRange(s, buckets)
+
+		for b := lo; b <= hi; b++ {
+			index[next[b]] = int32(i)
+			next[b] += 1
+		}
+	}
+
+	r.offsets = offsets
+	r.index = index
+
+	return r
+}
+
+func (r *preparedRing) countEntries(buckets int) int {
+
+	entries := 0
+
+	for _, s := range r.segments {
+		lo, hi := r.bucketRange(s, buckets)
+		entries += hi - lo + 1
+	}
+
+	return entries
+}
+
+func (r *preparedRing) bucketRange(s geom.Segment, buckets int) (int, int) {
+
+	lo := r.bucket(math.Min(s.A.X, s.B.X), buckets)
+	hi := r.bucket(math.Max(s.A.X, s.B.X), buckets)
+
+	return lo, hi
+}
+
+// bucket returns the strip that x falls in. It is monotonic in x so an edge
+// that spans x is always recorded in x's strip.
+
+func (r *preparedRing) bucket(x float64, buckets int) int {
+
+	b := int((x - r.min_x) / r.width)
+
+	if r.width == 0.0 || b < 0 {
+		return 0
+	}
+
+	if b >= buckets {
+		return buckets - 1
+	}
+
+	return b
+}
+
+// containsCoord is geom.Polygon.ContainsCoord restricted to the edges in the
+// strip c falls in.
+
+func (r *preparedRing) containsCoord(c geom.Coord) bool {
+
+	if len(r.segments) == 0 {
+		return false
+	}
+
+	if !(c.X >= r.min_x && c.X <= r.max_x) || c.Y > r.max_y {
+		return false
+	}
+
+	buckets := len(r.offsets) - 1
+	b := r.bucket(c.X, buckets)
+
+	fake := &geom.Segment{A: c, B: geom.Coord{X: c.X, Y: c.Y + 1}}
+
+	above := 0
+
+	for _, i := range r.index[r.offsets[b]:r.offsets[b+1]] {
+
+		uh, uv := r.segments[i].IntersectParameters(fake)
+
+		if uh < 0 || uh >= 1 {
+			continue
+		}
+
+		if uv > 0 {
+			above++
+		}
+	}
+
+	return above%2 == 1
+}
+
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
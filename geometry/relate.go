@@ -0,0 +1,1071 @@
+package geometry
+
+import (
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Locations and dimensions for an IntersectionMatrix.
+
+const (
+	LOCATION_INTERIOR int = 0
+	LOCATION_BOUNDARY int = 1
+	LOCATION_EXTERIOR int = 2
+)
+
+const DIMENSION_EMPTY int = -1
+
+// The distance, in degrees, within which a point is considered to be on a
+// line or a ring when relating geometries.
+
+const RELATE_TOLERANCE float64 = 1e-9
+
+// IntersectionMatrix is a DE-9IM matrix describing how the interior, boundary
+// and exterior of one geometry (the rows) intersect those of another (the
+// columns). Each cell is the dimension of the intersection or DIMENSION_EMPTY.
+// Geometries are treated as planar, with longitude and latitude as X and Y.
+
+type IntersectionMatrix [3][3]int
+
+func newIntersectionMatrix() IntersectionMatrix {
+
+	var m IntersectionMatrix
+
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			m[i][j] = DIMENSION_EMPTY
+		}
+	}
+
+	m[LOCATION_EXTERIOR][LOCATION_EXTERIOR] = 2
+	return m
+}
+
+func (m *IntersectionMatrix) set(a int, b int, dim int) {
+
+	if dim > m[a][b] {
+		m[a][b] = dim
+	}
+}
+
+func (m IntersectionMatrix) transpose() IntersectionMatrix {
+
+	var t IntersectionMatrix
+
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			t[i][j] = m[j][i]
+		}
+	}
+
+	return t
+}
+
+// String returns the matrix in the usual nine character form, for example
+// "212101212", with "F" for empty intersections.
+
+func (m IntersectionMatrix) String() string {
+
+	var b strings.Builder
+
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+
+			switch m[i][j] {
+			case DIMENSION_EMPTY:
+				b.WriteString("F")
+			default:
+				b.WriteByte(byte('0' + m[i][j]))
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// Matches reports whether m matches a nine character DE-9IM pattern made up of
+// "T" (non-empty), "F" (empty), "*" (anything) or a specific dimension.
+
+func (m IntersectionMatrix) Matches(pattern string) bool {
+
+	if len(pattern) != 9 {
+		return false
+	}
+
+	for i, r := range pattern {
+
+		v := m[i/3][i%3]
+
+		switch r {
+		case '*':
+			continue
+		case 'T':
+			if v == DIMENSION_EMPTY {
+				return false
+			}
+		case 'F':
+			if v != DIMENSION_EMPTY {
+				return false
+			}
+		case '0', '1', '2':
+			if v != int(r-'0') {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m IntersectionMatrix) Disjoint() bool {
+	return m.Matches("FF*FF****")
+}
+
+func (m IntersectionMatrix) Intersects() bool {
+	return !m.Disjoint()
+}
+
+func (m IntersectionMatrix) Contains() bool {
+	return m.Matches("T*****FF*")
+}
+
+func (m IntersectionMatrix) Within() bool {
+	return m.Matches("T*F**F***")
+}
+
+// Touches reports whether the geometries only meet at their boundaries.
+
+func (m IntersectionMatrix) Touches() bool {
+
+	if m[LOCATION_INTERIOR][LOCATION_INTERIOR] != DIMENSION_EMPTY {
+		return false
+	}
+
+	return m.Matches("FT*******") || m.Matches("F**T*****") || m.Matches("F***T****")
+}
+
+// Overlaps reports whether the geometries have the same dimension, share some
+// but not all of their interiors and their shared interior has that same
+// dimension.
+
+func (m IntersectionMatrix) Overlaps(dim_a int, dim_b int) bool {
+
+	if dim_a != dim_b {
+		return false
+	}
+
+	ii := m[LOCATION_INTERIOR][LOCATION_INTERIOR]
+
+	if ii != dim_a {
+		return false
+	}
+
+	return m[LOCATION_INTERIOR][LOCATION_EXTERIOR] != DIMENSION_EMPTY && m[LOCATION_EXTERIOR][LOCATION_INTERIOR] != DIMENSION_EMPTY
+}
+
+// Relation is the result of relating two geometries.
+
+type Relation struct {
+	Matrix     IntersectionMatrix
+	DimensionA int
+	DimensionB int
+}
+
+func (r *Relation) Disjoint() bool {
+	return r.Matrix.Disjoint()
+}
+
+func (r *Relation) Intersects() bool {
+	return r.Matrix.Intersects()
+}
+
+func (r *Relation) Contains() bool {
+	return r.Matrix.Contains()
+}
+
+func (r *Relation) Within() bool {
+	return r.Matrix.Within()
+}
+
+func (r *Relation) Touches() bool {
+	return r.Matrix.Touches()
+}
+
+func (r *Relation) Overlaps() bool {
+	return r.Matrix.Overlaps(r.DimensionA, r.DimensionB)
+}
+
+// RelateFeatures relates the geometries of a and b. If their bounding boxes do
+// not intersect the (disjoint) result is returned without comparing their
+// geometries.
+
+func RelateFeatures(a geojson.Feature, b geojson.Feature) (*Relation, error) {
+
+	members_a, err := featureMembers(a)
+
+	if err != nil {
+		return nil, err
+	}
+
+	members_b, err := featureMembers(b)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return RelateMembers(members_a, members_b), nil
+}
+
+func RelatePolygons(a []geojson.Polygon, b []geojson.Polygon) *Relation {
+
+	members_a := make([]geojson.Geometry, len(a))
+	members_b := make([]geojson.Geometry, len(b))
+
+	for i, p := range a {
+		members_a[i] = p
+	}
+
+	for i, p := range b {
+		members_b[i] = p
+	}
+
+	return RelateMembers(members_a, members_b)
+}
+
+// RelateMembers relates two lists of members, as returned by MembersForFeature,
+// treating each list as a single geometry.
+
+func RelateMembers(a []geojson.Geometry, b []geojson.Geometry) *Relation {
+
+	bounds_a := BoundingBoxesForMembers(a).Bounds()
+	bounds_b := BoundingBoxesForMembers(b).Bounds()
+
+	frame := newRelateFrame(append(append([]*geom.Rect{}, bounds_a...), bounds_b...))
+
+	ga := newRelateGeometry(a, frame)
+	gb := newRelateGeometry(b, frame)
+
+	rel := &Relation{
+		DimensionA: ga.dim,
+		DimensionB: gb.dim,
+	}
+
+	if len(bounds_a) == 0 || len(bounds_b) == 0 || !rectsIntersect(mbrForRects(bounds_a), mbrForRects(bounds_b)) {
+		rel.Matrix = disjointMatrix(ga, gb)
+		return rel
+	}
+
+	m := newIntersectionMatrix()
+
+	ga.relate(gb, &m)
+
+	mt := newIntersectionMatrix()
+	gb.relate(ga, &mt)
+
+	mt = mt.transpose()
+
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			m.set(i, j, mt[i][j])
+		}
+	}
+
+	rel.Matrix = m
+	return rel
+}
+
+// Intersects, Contains, Within, Touches, Overlaps and Disjoint are shortcuts
+// for the corresponding methods of the Relation returned by RelateFeatures.
+// Contains and Within check bounding boxes first and return early if one can
+// not contain the other.
+
+func Intersects(a geojson.Feature, b geojson.Feature) (bool, error) {
+
+	rel, err := RelateFeatures(a, b)
+
+	if err != nil {
+		return false, err
+	}
+
+	return rel.Intersects(), nil
+}
+
+func Disjoint(a geojson.Feature, b geojson.Feature) (bool, error) {
+
+	ok, err := Intersects(a, b)
+
+	if err != nil {
+		return false, err
+	}
+
+	return !ok, nil
+}
+
+func Contains(a geojson.Feature, b geojson.Feature) (bool, error) {
+
+	ok, err := mbrContains(a, b)
+
+	if err != nil || !ok {
+		return false, err
+	}
+
+	rel, err := RelateFeatures(a, b)
+
+	if err != nil {
+		return false, err
+	}
+
+	return rel.Contains(), nil
+}
+
+func Within(a geojson.Feature, b geojson.Feature) (bool, error) {
+	return Contains(b, a)
+}
+
+func Touches(a geojson.Feature, b geojson.Feature) (bool, error) {
+
+	rel, err := RelateFeatures(a, b)
+
+	if err != nil {
+		return false, err
+	}
+
+	return rel.Touches(), nil
+}
+
+func Overlaps(a geojson.Feature, b geojson.Feature) (bool, error) {
+
+	rel, err := RelateFeatures(a, b)
+
+	if err != nil {
+		return false, err
+	}
+
+	return rel.Overlaps(), nil
+}
+
+func featureMembers(f geojson.Feature) ([]geojson.Geometry, error) {
+
+	// use the feature's own (cached) members if it has them
+
+	m, ok := f.(interface {
+		Members() ([]geojson.Geometry, error)
+	})
+
+	if ok {
+		return m.Members()
+	}
+
+	return MembersForFeature(f)
+}
+
+func mbrContains(a geojson.Feature, b geojson.Feature) (bool, error) {
+
+	bboxes_a, err := a.BoundingBoxes()
+
+	if err != nil {
+		return false, err
+	}
+
+	bboxes_b, err := b.BoundingBoxes()
+
+	if err != nil {
+		return false, err
+	}
+
+	if len(bboxes_a.Bounds()) == 0 || len(bboxes_b.Bounds()) == 0 {
+		return false, nil
+	}
+
+	return rectContainsRect(bboxes_a.MBR(), bboxes_b.MBR()), nil
+}
+
+func disjointMatrix(a *relateGeometry, b *relateGeometry) IntersectionMatrix {
+
+	m := newIntersectionMatrix()
+
+	if a.dim >= 0 {
+		m.set(LOCATION_INTERIOR, LOCATION_EXTERIOR, a.dim)
+	}
+
+	if a.boundaryDim() >= 0 {
+		m.set(LOCATION_BOUNDARY, LOCATION_EXTERIOR, a.boundaryDim())
+	}
+
+	if b.dim >= 0 {
+		m.set(LOCATION_EXTERIOR, LOCATION_INTERIOR, b.dim)
+	}
+
+	if b.boundaryDim() >= 0 {
+		m.set(LOCATION_EXTERIOR, LOCATION_BOUNDARY, b.boundaryDim())
+	}
+
+	return m
+}
+
+// relateFrame shifts longitudes so that geometries on either side of the
+// antimeridian can be compared as planar geometries. If the combined bounding
+// box of both geometries doesn't cross the antimeridian it does nothing.
+// Otherwise each ring or line is unwrapped and shifted 360 degrees east if it
+// lies entirely between -180 and the eastern edge of the combined box.
+
+type relateFrame struct {
+	crosses bool
+	east    float64
+}
+
+func newRelateFrame(bounds []*geom.Rect) *relateFrame {
+
+	f := &relateFrame{}
+
+	if len(bounds) == 0 {
+		return f
+	}
+
+	mbr := mbrForRects(bounds)
+
+	f.crosses = mbr.Min.X > mbr.Max.X
+	f.east = mbr.Max.X
+
+	return f
+}
+
+func (f *relateFrame) vertices(vertices []geom.Coord) []geom.Coord {
+
+	if !f.crosses || len(vertices) == 0 {
+		return vertices
+	}
+
+	vertices = unwrapVertices(vertices, vertices[0].X)
+
+	max_x := math.Inf(-1)
+
+	for _, v := range vertices {
+		max_x = math.Max(max_x, v.X)
+	}
+
+	if max_x > f.east {
+		return vertices
+	}
+
+	shifted := make([]geom.Coord, len(vertices))
+
+	for i, v := range vertices {
+		shifted[i] = geom.Coord{X: v.X + 360.0, Y: v.Y}
+	}
+
+	return shifted
+}
+
+func (f *relateFrame) rings(p geojson.Polygon) [][]geom.Coord {
+
+	rings := polygonRings(p)
+
+	if !f.crosses {
+		return rings
+	}
+
+	// shift all of a polygon's rings by the same amount as its exterior
+
+	ext := rings[0]
+
+	if len(ext) == 0 {
+		return rings
+	}
+
+	shifted := f.vertices(ext)
+	offset := shifted[0].X - ext[0].X
+
+	rings[0] = shifted
+
+	for i := 1; i < len(rings); i++ {
+
+		ring := unwrapVertices(rings[i], ext[0].X)
+
+		for j, v := range ring {
+			ring[j] = geom.Coord{X: v.X + offset, Y: v.Y}
+		}
+
+		rings[i] = ring
+	}
+
+	return rings
+}
+
+type relateSegment struct {
+	a       geom.Coord
+	b       geom.Coord
+	polygon int // index of the polygon the segment belongs to or -1 for lines
+}
+
+type relateGeometry struct {
+	polygons      [][][]geom.Coord
+	lines         [][]geom.Coord
+	points        []geom.Coord
+	line_boundary []geom.Coord
+	segments      []relateSegment
+	index         *segmentIndex
+	dim           int
+}
+
+func newRelateGeometry(members []geojson.Geometry, frame *relateFrame) *relateGeometry {
+
+	g := emptyRelateGeometry()
+
+	endpoints := make(map[geom.Coord]int)
+
+	for _, m := range members {
+
+		switch m := m.(type) {
+		case geojson.Polygon:
+
+			g.addPolygon(frame.rings(m))
+
+		case geojson.LineString:
+
+			path := m.Path()
+			vertices := frame.vertices(path.Vertices())
+
+			if len(vertices) == 0 {
+				continue
+			}
+
+			g.addSegments(vertices, -1)
+			g.lines = append(g.lines, vertices)
+
+			// the boundary of a line is its endpoints, using the "mod 2"
+			// rule so that closed lines have no boundary
+
+			endpoints[vertices[0]] += 1
+			endpoints[vertices[len(vertices)-1]] += 1
+
+			if g.dim < 1 {
+				g.dim = 1
+			}
+
+		case geojson.Point:
+
+			vertices := frame.vertices([]geom.Coord{m.Coord()})
+			g.points = append(g.points, vertices[0])
+
+			if g.dim < 0 {
+				g.dim = 0
+			}
+		}
+	}
+
+	for c, count := range endpoints {
+
+		if count%2 == 1 {
+			g.line_boundary = append(g.line_boundary, c)
+		}
+	}
+
+	g.index = newSegmentIndex(g.segments)
+	return g
+}
+
+func emptyRelateGeometry() *relateGeometry {
+
+	return &relateGeometry{
+		polygons:      make([][][]geom.Coord, 0),
+		lines:         make([][]geom.Coord, 0),
+		points:        make([]geom.Coord, 0),
+		line_boundary: make([]geom.Coord, 0),
+		segments:      make([]relateSegment, 0),
+		dim:           DIMENSION_EMPTY,
+	}
+}
+
+func (g *relateGeometry) addPolygon(rings [][]geom.Coord) {
+
+	id := len(g.polygons)
+
+	for _, ring := range rings {
+		g.addSegments(ring, id)
+	}
+
+	g.polygons = append(g.polygons, rings)
+	g.dim = 2
+}
+
+func (g *relateGeometry) addSegments(vertices []geom.Coord, polygon int) {
+
+	for i := 1; i < len(vertices); i++ {
+
+		if vertices[i-1] == vertices[i] {
+			continue
+		}
+
+		g.segments = append(g.segments, relateSegment{a: vertices[i-1], b: vertices[i], polygon: polygon})
+	}
+}
+
+func (g *relateGeometry) boundaryDim() int {
+
+	if len(g.polygons) > 0 {
+		return 1
+	}
+
+	if len(g.line_boundary) > 0 {
+		return 0
+	}
+
+	return DIMENSION_EMPTY
+}
+
+// locate returns the location of c relative to g and, for interior locations,
+// the dimension of the member it is inside of.
+
+func (g *relateGeometry) locate(c geom.Coord) (int, int) {
+
+	on_ring := false
+	on_line := false
+
+	crossings := make(map[int]int)
+
+	for _, s := range g.index.query(c.Y-RELATE_TOLERANCE, c.Y+RELATE_TOLERANCE) {
+
+		if onSegment(c, s.a, s.b) {
+
+			if s.polygon >= 0 {
+				on_ring = true
+			} else {
+				on_line = true
+			}
+		}
+	}
+
+	if !on_ring && len(g.polygons) > 0 {
+
+		for _, s := range g.index.query(c.Y, c.Y) {
+
+			if s.polygon < 0 {
+				continue
+			}
+
+			a := s.a
+			b := s.b
+
+			if (a.Y > c.Y) != (b.Y > c.Y) && c.X < (b.X-a.X)*(c.Y-a.Y)/(b.Y-a.Y)+a.X {
+				crossings[s.polygon] += 1
+			}
+		}
+
+		for _, count := range crossings {
+
+			if count%2 == 1 {
+				return LOCATION_INTERIOR, 2
+			}
+		}
+	}
+
+	if on_ring {
+		return LOCATION_BOUNDARY, 1
+	}
+
+	for _, b := range g.line_boundary {
+
+		if closeTo(c, b) {
+			return LOCATION_BOUNDARY, 0
+		}
+	}
+
+	if on_line {
+		return LOCATION_INTERIOR, 1
+	}
+
+	for _, p := range g.points {
+
+		if closeTo(c, p) {
+			return LOCATION_INTERIOR, 0
+		}
+	}
+
+	return LOCATION_EXTERIOR, DIMENSION_EMPTY
+}
+
+// relate records in m how the parts of g intersect other. It only fills in
+// what can be learned by walking g; relating other to g fills in the rest.
+
+func (g *relateGeometry) relate(other *relateGeometry, m *IntersectionMatrix) {
+
+	// a geometry always has interior points outside of a geometry of a
+	// lower dimension
+
+	if g.dim > other.dim {
+		m.set(LOCATION_INTERIOR, LOCATION_EXTERIOR, g.dim)
+	}
+
+	for _, s := range g.segments {
+
+		params := []float64{0.0, 1.0}
+
+		for _, o := range other.index.queryRange(s.a, s.b) {
+			params = append(params, segmentIntersections(s.a, s.b, o.a, o.b)...)
+		}
+
+		for _, c := range other.points {
+
+			if onSegment(c, s.a, s.b) {
+				params = append(params, projectOnSegment(c, s.a, s.b))
+			}
+		}
+
+		sort.Float64s(params)
+
+		is_ring := s.polygon >= 0
+
+		// the vertices along the segment, including where it is split
+
+		for _, t := range params {
+
+			c := pointAlong(s.a, s.b, t)
+			loc, _ := other.locate(c)
+
+			if is_ring {
+				m.set(LOCATION_BOUNDARY, loc, 0)
+			} else if g.isLineBoundary(c) {
+				m.set(LOCATION_BOUNDARY, loc, 0)
+			} else {
+				m.set(LOCATION_INTERIOR, loc, 0)
+			}
+		}
+
+		// the pieces of the segment between those vertices, each of which
+		// lies entirely in one location relative to other
+
+		for i := 1; i < len(params); i++ {
+
+			t0 := params[i-1]
+			t1 := params[i]
+
+			if t1-t0 < 1e-12 {
+				continue
+			}
+
+			mid := pointAlong(s.a, s.b, (t0+t1)/2.0)
+			loc, loc_dim := other.locate(mid)
+
+			if !is_ring {
+				m.set(LOCATION_INTERIOR, loc, 1)
+				continue
+			}
+
+			m.set(LOCATION_BOUNDARY, loc, 1)
+
+			// look at either side of the piece to see which parts of g's
+			// interior and exterior meet other there: a piece of an
+			// interior ring has g's exterior on one side and a piece of a
+			// ring that runs along other's ring can have anything on
+			// either side of it
+
+			p0 := pointAlong(s.a, s.b, t0)
+			p1 := pointAlong(s.a, s.b, t1)
+
+			for _, side := range sidePoints(p0, p1) {
+
+				loc_g, _ := g.locate(side)
+				loc_o := loc
+
+				// a piece inside another polygon or outside other
+				// has that same location on both sides but a piece
+				// along a line or a ring does not
+
+				if loc == LOCATION_BOUNDARY || (loc == LOCATION_INTERIOR && loc_dim != 2) {
+					loc_o, _ = other.locate(side)
+				}
+
+				if loc_g == LOCATION_BOUNDARY || loc_o == LOCATION_BOUNDARY {
+					continue
+				}
+
+				if loc_g == LOCATION_EXTERIOR && loc_o == LOCATION_EXTERIOR {
+					continue
+				}
+
+				m.set(loc_g, loc_o, 2)
+			}
+		}
+	}
+
+	for _, p := range g.points {
+
+		loc, _ := other.locate(p)
+		m.set(LOCATION_INTERIOR, loc, 0)
+	}
+}
+
+func (g *relateGeometry) isLineBoundary(c geom.Coord) bool {
+
+	for _, b := range g.line_boundary {
+
+		if closeTo(c, b) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// segmentIntersections returns the parameters, between 0 and 1, along the
+// segment a0 -> a1 where it touches or crosses the segment b0 -> b1. For
+// collinear segments these are the ends of the shared part.
+
+func segmentIntersections(a0 geom.Coord, a1 geom.Coord, b0 geom.Coord, b1 geom.Coord) []float64 {
+
+	params := make([]float64, 0)
+
+	dx := a1.X - a0.X
+	dy := a1.Y - a0.Y
+	ex := b1.X - b0.X
+	ey := b1.Y - b0.Y
+
+	len_a := math.Hypot(dx, dy)
+	len_b := math.Hypot(ex, ey)
+
+	if len_a == 0.0 || len_b == 0.0 {
+		return params
+	}
+
+	denom := dx*ey - dy*ex
+
+	fx := b0.X - a0.X
+	fy := b0.Y - a0.Y
+
+	if math.Abs(denom) > 1e-12*len_a*len_b {
+
+		t := (fx*ey - fy*ex) / denom
+		u := (fx*dy - fy*dx) / denom
+
+		tol_t := RELATE_TOLERANCE / len_a
+		tol_u := RELATE_TOLERANCE / len_b
+
+		if t >= -tol_t && t <= 1.0+tol_t && u >= -tol_u && u <= 1.0+tol_u {
+			params = append(params, math.Max(0.0, math.Min(1.0, t)))
+		}
+
+		return params
+	}
+
+	// parallel; are they collinear?
+
+	if math.Abs(fx*dy-fy*dx)/len_a > RELATE_TOLERANCE {
+		return params
+	}
+
+	for _, c := range []geom.Coord{b0, b1} {
+
+		t := projectOnSegment(c, a0, a1)
+
+		if t >= 0.0 && t <= 1.0 {
+			params = append(params, t)
+		}
+	}
+
+	return params
+}
+
+// projectOnSegment returns the parameter along the segment a -> b of the
+// point closest to c on the line through a and b.
+
+func projectOnSegment(c geom.Coord, a geom.Coord, b geom.Coord) float64 {
+
+	dx := b.X - a.X
+	dy := b.Y - a.Y
+
+	return ((c.X-a.X)*dx + (c.Y-a.Y)*dy) / (dx*dx + dy*dy)
+}
+
+func pointAlong(a geom.Coord, b geom.Coord, t float64) geom.Coord {
+
+	if t == 0.0 {
+		return a
+	}
+
+	if t == 1.0 {
+		return b
+	}
+
+	return geom.Coord{X: a.X + t*(b.X-a.X), Y: a.Y + t*(b.Y-a.Y)}
+}
+
+// sidePoints returns two points just to the left and right of the midpoint of
+// the segment a -> b.
+
+func sidePoints(a geom.Coord, b geom.Coord) []geom.Coord {
+
+	dx := b.X - a.X
+	dy := b.Y - a.Y
+
+	length := math.Hypot(dx, dy)
+
+	offset := math.Max(length*1e-3, RELATE_TOLERANCE*10.0)
+
+	nx := -dy / length * offset
+	ny := dx / length * offset
+
+	mid := geom.Coord{X: (a.X + b.X) / 2.0, Y: (a.Y + b.Y) / 2.0}
+
+	return []geom.Coord{
+		geom.Coord{X: mid.X + nx, Y: mid.Y + ny},
+		geom.Coord{X: mid.X - nx, Y: mid.Y - ny},
+	}
+}
+
+func onSegment(c geom.Coord, a geom.Coord, b geom.Coord) bool {
+	return segmentDistanceSq(c, a, b) <= RELATE_TOLERANCE*RELATE_TOLERANCE
+}
+
+func closeTo(a geom.Coord, b geom.Coord) bool {
+	return math.Abs(a.X-b.X) <= RELATE_TOLERANCE && math.Abs(a.Y-b.Y) <= RELATE_TOLERANCE
+}
+
+// segmentIndex buckets segments in to horizontal strips so that the segments
+// near a given latitude can be found without looking at all of them.
+
+type segmentIndex struct {
+	segments []relateSegment
+	strips   [][]int
+	min_y    float64
+	height   float64
+	min_x    float64
+	max_x    float64
+}
+
+func newSegmentIndex(segments []relateSegment) *segmentIndex {
+
+	idx := &segmentIndex{
+		segments: segments,
+	}
+
+	if len(segments) == 0 {
+		return idx
+	}
+
+	bounds := geom.NilRect()
+
+	for _, s := range segments {
+		bounds.ExpandToContainCoord(s.a)
+		bounds.ExpandToContainCoord(s.b)
+	}
+
+	count := int(math.Sqrt(float64(len(segments)))) + 1
+
+	idx.min_y = bounds.Min.Y
+	idx.height = (bounds.Max.Y - bounds.Min.Y) / float64(count)
+	idx.min_x = bounds.Min.X
+	idx.max_x = bounds.Max.X
+
+	if idx.height == 0.0 {
+		count = 1
+	}
+
+	idx.strips = make([][]int, count)
+
+	for i, s := range segments {
+
+		lo, hi := idx.stripRange(math.Min(s.a.Y, s.b.Y), math.Max(s.a.Y, s.b.Y))
+
+		for j := lo; j <= hi; j++ {
+			idx.strips[j] = append(idx.strips[j], i)
+		}
+	}
+
+	return idx
+}
+
+func (idx *segmentIndex) stripRange(min_y float64, max_y float64) (int, int) {
+
+	last := len(idx.strips) - 1
+
+	if idx.height == 0.0 {
+		return 0, last
+	}
+
+	clamp := func(i int) int {
+
+		if i < 0 {
+			return 0
+		}
+
+		if i > last {
+			return last
+		}
+
+		return i
+	}
+
+	lo := clamp(int(math.Floor((min_y - idx.min_y) / idx.height)))
+	hi := clamp(int(math.Floor((max_y - idx.min_y) / idx.height)))
+
+	return lo, hi
+}
+
+// query returns the segments whose latitude range overlaps min_y to max_y.
+
+func (idx *segmentIndex) query(min_y float64, max_y float64) []relateSegment {
+
+	results := make([]relateSegment, 0)
+
+	if len(idx.segments) == 0 {
+		return results
+	}
+
+	lo, hi := idx.stripRange(min_y, max_y)
+
+	seen := make(map[int]bool)
+
+	for i := lo; i <= hi; i++ {
+
+		for _, j := range idx.strips[i] {
+
+			if seen[j] {
+				continue
+			}
+
+			seen[j] = true
+
+			s := idx.segments[j]
+
+			if math.Max(s.a.Y, s.b.Y) < min_y || math.Min(s.a.Y, s.b.Y) > max_y {
+				continue
+			}
+
+			results = append(results, s)
+		}
+	}
+
+	return results
+}
+
+// queryRange returns the segments whose bounding box (padded by the relate
+// tolerance) overlaps that of the segment a -> b.
+
+func (idx *segmentIndex) queryRange(a geom.Coord, b geom.Coord) []relateSegment {
+
+	min_x := math.Min(a.X, b.X) - RELATE_TOLERANCE
+	max_x := math.Max(a.X, b.X) + RELATE_TOLERANCE
+
+	results := make([]relateSegment, 0)
+
+	if max_x < idx.min_x || min_x > idx.max_x {
+		return results
+	}
+
+	for _, s := range idx.query(math.Min(a.Y, b.Y)-RELATE_TOLERANCE, math.Max(a.Y, b.Y)+RELATE_TOLERANCE) {
+
+		if math.Max(s.a.X, s.b.X) < min_x || math.Min(s.a.X, s.b.X) > max_x {
+			continue
+		}
+
+		results = append(results, s)
+	}
+
+	return results
+}
@@ -0,0 +1,526 @@
+package geometry
+
+import (
+	"fmt"
+	pm_geojson "github.com/paulmach/go.geojson"
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"sort"
+)
+
+// The kinds of change RepairGeometry makes
+
+const (
+	REPAIR_REMOVE_DUPLICATES string = "remove-duplicates"
+	REPAIR_CLOSE_RING        string = "close-ring"
+	REPAIR_DROP_RING         string = "drop-ring"
+	REPAIR_DROP_PART         string = "drop-part"
+	REPAIR_REWIND            string = "rewind"
+	REPAIR_MAKE_VALID        string = "make-valid"
+)
+
+// RepairAction records a single change made by RepairGeometry. Path, Part and
+// Ring refer to the original geometry, using the same conventions as
+// ValidityError.
+
+type RepairAction struct {
+	Action  string
+	Path    string
+	Part    int
+	Ring    int
+	Message string
+}
+
+func (a *RepairAction) String() string {
+	return fmt.Sprintf("%s %s: %s", a.Action, a.Path, a.Message)
+}
+
+// RepairFeatureGeometry parses and repairs the geometry of f. See
+// feature.RepairFeature for a version that returns a new feature.
+
+func RepairFeatureGeometry(f geojson.Feature) (*pm_geojson.Geometry, []*RepairAction, error) {
+
+	g, err := GeometryForFeature(f)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return RepairGeometry(g)
+}
+
+// RepairGeometry returns a copy of g with the problems that can be fixed
+// automatically fixed, and a log of what was changed:
+//
+// * consecutive duplicate positions, and malformed positions, are removed
+// * rings that aren't closed are closed
+// * rings with fewer than four positions, or whose positions all lie on a
+//   line, are dropped (along with their polygon if they are an exterior
+//   ring) as are lines with fewer than two positions
+// * polygons whose rings intersect themselves or each other, like bow-ties,
+//   are rebuilt using the even-odd rule which may split them in to several
+//   polygons
+// * interior rings outside of their exterior ring, or inside another interior
+//   ring, are dropped
+// * rings are rewound to follow the RFC 7946 right-hand rule
+//
+// A Polygon that is split becomes a MultiPolygon. If nothing is left of g the
+// geometry returned is nil.
+
+func RepairGeometry(g *pm_geojson.Geometry) (*pm_geojson.Geometry, []*RepairAction, error) {
+
+	r := &geometryRepairer{
+		actions: make([]*RepairAction, 0),
+	}
+
+	repaired, err := r.repair(g, "geometry")
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return repaired, r.actions, nil
+}
+
+type geometryRepairer struct {
+	actions []*RepairAction
+}
+
+func (r *geometryRepairer) log(action string, path string, part int, ring int, msg string, args ...interface{}) {
+
+	a := &RepairAction{
+		Action:  action,
+		Path:    path,
+		Part:    part,
+		Ring:    ring,
+		Message: fmt.Sprintf(msg, args...),
+	}
+
+	r.actions = append(r.actions, a)
+}
+
+func (r *geometryRepairer) repair(g *pm_geojson.Geometry, path string) (*pm_geojson.Geometry, error) {
+
+	if g == nil {
+		return nil, nil
+	}
+
+	coords_path := path + ".coordinates"
+
+	switch g.Type {
+	case pm_geojson.GeometryPoint:
+		return pm_geojson.NewPointGeometry(g.Point), nil
+	case pm_geojson.GeometryMultiPoint:
+		return pm_geojson.NewMultiPointGeometry(g.MultiPoint...), nil
+	case pm_geojson.GeometryLineString:
+
+		line := r.repairLine(g.LineString, coords_path, -1)
+
+		if line == nil {
+			return nil, nil
+		}
+
+		return pm_geojson.NewLineStringGeometry(line), nil
+
+	case pm_geojson.GeometryMultiLineString:
+
+		lines := make([][][]float64, 0)
+
+		for i, l := range g.MultiLineString {
+
+			line := r.repairLine(l, fmt.Sprintf("%s.%d", coords_path, i), i)
+
+			if line != nil {
+				lines = append(lines, line)
+			}
+		}
+
+		if len(lines) == 0 {
+			return nil, nil
+		}
+
+		return pm_geojson.NewMultiLineStringGeometry(lines...), nil
+
+	case pm_geojson.GeometryPolygon:
+
+		polys := r.repairPolygon(g.Polygon, coords_path, -1)
+
+		switch len(polys) {
+		case 0:
+			return nil, nil
+		case 1:
+			return pm_geojson.NewPolygonGeometry(polys[0]), nil
+		default:
+			return pm_geojson.NewMultiPolygonGeometry(polys...), nil
+		}
+
+	case pm_geojson.GeometryMultiPolygon:
+
+		polys := make([][][][]float64, 0)
+
+		for i, p := range g.MultiPolygon {
+			polys = append(polys, r.repairPolygon(p, fmt.Sprintf("%s.%d", coords_path, i), i)...)
+		}
+
+		if len(polys) == 0 {
+			return nil, nil
+		}
+
+		return pm_geojson.NewMultiPolygonGeometry(polys...), nil
+
+	case pm_geojson.GeometryCollection:
+
+		geoms := make([]*pm_geojson.Geometry, 0)
+
+		for i, member := range g.Geometries {
+
+			repaired, err := r.repair(member, fmt.Sprintf("%s.geometries.%d", path, i))
+
+			if err != nil {
+				return nil, err
+			}
+
+			if repaired != nil {
+				geoms = append(geoms, repaired)
+			}
+		}
+
+		return pm_geojson.NewCollectionGeometry(geoms...), nil
+
+	default:
+		return nil, fmt.Errorf("Unsupported geometry type '%s'", g.Type)
+	}
+}
+
+func (r *geometryRepairer) repairLine(coords [][]float64, path string, part int) [][]float64 {
+
+	line, removed := removeDuplicatePositions(coords)
+
+	if removed > 0 {
+		r.log(REPAIR_REMOVE_DUPLICATES, path, part, -1, "Removed %d duplicate or malformed positions", removed)
+	}
+
+	if len(line) < 2 {
+		r.log(REPAIR_DROP_PART, path, part, -1, "Dropped line with fewer than two distinct positions")
+		return nil
+	}
+
+	return line
+}
+
+// repairPolygon returns the polygons that the rings of a single polygon are
+// repaired in to.
+
+func (r *geometryRepairer) repairPolygon(rings [][][]float64, path string, part int) [][][][]float64 {
+
+	cleaned := make([][][]float64, 0)
+
+	// the index of each cleaned ring in the original polygon
+
+	indices := make([]int, 0)
+
+	for i, ring := range rings {
+
+		ring_path := fmt.Sprintf("%s.%d", path, i)
+
+		ring, removed := removeDuplicatePositions(ring)
+
+		if removed > 0 {
+			r.log(REPAIR_REMOVE_DUPLICATES, ring_path, part, i, "Removed %d duplicate or malformed positions", removed)
+		}
+
+		count := len(ring)
+
+		if count > 0 && !samePosition(ring[0], ring[count-1]) {
+			ring = append(ring, ring[0])
+			r.log(REPAIR_CLOSE_RING, ring_path, part, i, "Closed ring")
+		}
+
+		if len(ring) < 4 || collinearVertices(coordsToVertices(ring)) {
+
+			if i == 0 {
+				r.log(REPAIR_DROP_PART, path, part, -1, "Dropped polygon whose exterior ring has no area")
+				return [][][][]float64{}
+			}
+
+			r.log(REPAIR_DROP_RING, ring_path, part, i, "Dropped interior ring with no area")
+			continue
+		}
+
+		cleaned = append(cleaned, ring)
+		indices = append(indices, i)
+	}
+
+	if len(cleaned) == 0 {
+		r.log(REPAIR_DROP_PART, path, part, -1, "Dropped polygon with no rings")
+		return [][][][]float64{}
+	}
+
+	vertices := make([][]geom.Coord, len(cleaned))
+
+	for i, ring := range cleaned {
+		vertices[i] = coordsToVertices(ring)
+	}
+
+	if crossesAntimeridian(vertices[0]) {
+
+		ref := vertices[0][0].X
+
+		for i, v := range vertices {
+			vertices[i] = unwrapVertices(v, ref)
+		}
+	}
+
+	if len(ringIntersections(vertices)) > 0 {
+
+		polys := makeValidPolygon(vertices)
+		r.log(REPAIR_MAKE_VALID, path, part, -1, "Rebuilt polygon with intersecting rings as %d polygon(s)", len(polys))
+
+		repaired := make([][][][]float64, len(polys))
+
+		for i, p := range polys {
+
+			poly_rings := [][][]float64{
+				ringCoordinates(p.ExteriorRing()),
+			}
+
+			for _, int_ring := range p.InteriorRings() {
+				poly_rings = append(poly_rings, ringCoordinates(int_ring))
+			}
+
+			repaired[i] = poly_rings
+		}
+
+		return repaired
+	}
+
+	repaired := [][][]float64{
+		cleaned[0],
+	}
+
+	if planarRingArea(vertices[0]) < 0.0 {
+		repaired[0] = reverseCoords(cleaned[0])
+		r.log(REPAIR_REWIND, fmt.Sprintf("%s.%d", path, indices[0]), part, indices[0], "Rewound exterior ring counter-clockwise")
+	}
+
+	holes := make([]int, 0)
+
+	for i := 1; i < len(cleaned); i++ {
+
+		if !ringContainsCoord(vertices[0], ringInteriorPoint(vertices[i])) {
+			r.log(REPAIR_DROP_RING, fmt.Sprintf("%s.%d", path, indices[i]), part, indices[i], "Dropped interior ring outside the exterior ring")
+			continue
+		}
+
+		holes = append(holes, i)
+	}
+
+	for _, i := range holes {
+
+		ring_path := fmt.Sprintf("%s.%d", path, indices[i])
+
+		// the rings don't cross so an interior ring inside another one
+		// only removes area that has already been removed
+
+		nested := false
+
+		for _, j := range holes {
+
+			if j != i && polygonContainsRing([][]geom.Coord{vertices[j]}, vertices[i]) {
+				r.log(REPAIR_DROP_RING, ring_path, part, indices[i], "Dropped interior ring inside interior ring %d", indices[j])
+				nested = true
+				break
+			}
+		}
+
+		if nested {
+			continue
+		}
+
+		ring := cleaned[i]
+
+		if planarRingArea(vertices[i]) > 0.0 {
+			ring = reverseCoords(ring)
+			r.log(REPAIR_REWIND, ring_path, part, indices[i], "Rewound interior ring clockwise")
+		}
+
+		repaired = append(repaired, ring)
+	}
+
+	return [][][][]float64{repaired}
+}
+
+// makeValidPolygon rebuilds the (closed) rings of a polygon whose rings
+// intersect themselves or each other. The rings are split wherever they meet
+// and every piece that separates the inside of the polygon from the outside,
+// according to the even-odd rule, is kept. Pieces that are traced an even
+// number of times, like spikes, separate nothing and are dropped. The pieces
+// are then linked back together in to valid polygons.
+
+func makeValidPolygon(rings [][]geom.Coord) []geojson.Polygon {
+
+	g := emptyRelateGeometry()
+	g.addPolygon(rings)
+
+	idx := newSegmentIndex(g.segments)
+
+	counts := make(map[[2]geom.Coord]int)
+
+	for _, s := range g.segments {
+
+		nodes := make([]geom.Coord, 0)
+
+		for _, o := range idx.queryRange(s.a, s.b) {
+
+			if o == s {
+				continue
+			}
+
+			nodes = append(nodes, overlayNodes(s.a, s.b, o.a, o.b)...)
+		}
+
+		vertices := splitSegment(s.a, s.b, nodes)
+
+		for i := 1; i < len(vertices); i++ {
+
+			a := vertices[i-1]
+			b := vertices[i]
+
+			if coordLess(b, a) {
+				a, b = b, a
+			}
+
+			counts[[2]geom.Coord{a, b}] += 1
+		}
+	}
+
+	keys := make([][2]geom.Coord, 0)
+
+	for k, count := range counts {
+
+		if count%2 == 1 {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+
+		if keys[i][0] != keys[j][0] {
+			return coordLess(keys[i][0], keys[j][0])
+		}
+
+		return coordLess(keys[i][1], keys[j][1])
+	})
+
+	edges := make([]overlayEdge, 0)
+
+	for _, k := range keys {
+
+		e := overlayEdge{a: k[0], b: k[1]}
+
+		// orient each edge so that the inside of the polygon is on its
+		// left, as buildOverlayPolygons expects
+
+		left := sidePoints(e.a, e.b)[0]
+
+		if !evenOddContains(rings, left) {
+			e = e.reverse()
+		}
+
+		edges = append(edges, e)
+	}
+
+	return buildOverlayPolygons(edges)
+}
+
+// collinearVertices reports whether all of vertices lie on a single line, in
+// which case a ring made from them has no area. Note that a ring with no
+// (signed) area, like a bow-tie, isn't necessarily collinear.
+
+func collinearVertices(vertices []geom.Coord) bool {
+
+	for i := 1; i < len(vertices); i++ {
+
+		if vertices[i] == vertices[0] {
+			continue
+		}
+
+		for j := i + 1; j < len(vertices); j++ {
+
+			if orientation(vertices[0], vertices[i], vertices[j]) != 0 {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return true
+}
+
+func evenOddContains(rings [][]geom.Coord, c geom.Coord) bool {
+
+	inside := false
+
+	for _, ring := range rings {
+
+		if ringContainsCoord(ring, c) {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+// ringInteriorPoint returns a point just inside a closed ring, whichever way
+// it is wound.
+
+func ringInteriorPoint(ring []geom.Coord) geom.Coord {
+
+	sides := sidePoints(ring[0], ring[1])
+
+	if planarRingArea(ring) > 0.0 {
+		return sides[0]
+	}
+
+	return sides[1]
+}
+
+// removeDuplicatePositions returns coords without any positions that are the
+// same as the one before them, or that have fewer than two values, and how
+// many were removed.
+
+func removeDuplicatePositions(coords [][]float64) ([][]float64, int) {
+
+	cleaned := make([][]float64, 0)
+
+	for _, c := range coords {
+
+		if len(c) < 2 {
+			continue
+		}
+
+		if len(cleaned) > 0 && samePosition(c, cleaned[len(cleaned)-1]) {
+			continue
+		}
+
+		cleaned = append(cleaned, c)
+	}
+
+	return cleaned, len(coords) - len(cleaned)
+}
+
+func samePosition(a []float64, b []float64) bool {
+	return a[0] == b[0] && a[1] == b[1]
+}
+
+func reverseCoords(coords [][]float64) [][]float64 {
+
+	reversed := make([][]float64, len(coords))
+
+	for i, c := range coords {
+		reversed[len(coords)-1-i] = c
+	}
+
+	return reversed
+}
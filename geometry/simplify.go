@@ -0,0 +1,691 @@
+package geometry
+
+import (
+	"errors"
+	"fmt"
+	pm_geojson "github.com/paulmach/go.geojson"
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"math"
+)
+
+// Simplification algorithms
+
+const (
+	SIMPLIFY_DOUGLAS_PEUCKER    string = "douglas-peucker"
+	SIMPLIFY_VISVALINGAM_WHYATT string = "visvalingam-whyatt"
+)
+
+// The number of times a polygon is simplified again, with half the tolerance
+// each time, if simplifying it makes it invalid before its original rings are
+// used instead.
+
+const SIMPLIFY_MAX_ATTEMPTS int = 8
+
+// Units for SimplifyOptions.Tolerance
+
+const (
+	SIMPLIFY_UNITS_DEGREES string = "degrees"
+	SIMPLIFY_UNITS_METRES  string = "metres"
+)
+
+// SimplifyOptions describes how to simplify a geometry. For Douglas-Peucker
+// Tolerance is the maximum distance a simplified line may stray from the
+// original. For Visvalingam-Whyatt vertices are removed while the triangle
+// they form with their neighbours has an area smaller than Tolerance squared.
+// Tolerances in metres are measured on a local equirectangular projection of
+// each ring or line.
+
+type SimplifyOptions struct {
+	Algorithm string
+	Tolerance float64
+	Units     string
+}
+
+func DefaultSimplifyOptions() *SimplifyOptions {
+
+	opts := &SimplifyOptions{
+		Algorithm: SIMPLIFY_DOUGLAS_PEUCKER,
+		Tolerance: 0.0001,
+		Units:     SIMPLIFY_UNITS_DEGREES,
+	}
+
+	return opts
+}
+
+func (opts *SimplifyOptions) validate() error {
+
+	switch opts.Algorithm {
+	case SIMPLIFY_DOUGLAS_PEUCKER, SIMPLIFY_VISVALINGAM_WHYATT:
+		// pass
+	default:
+		return fmt.Errorf("Invalid simplification algorithm '%s'", opts.Algorithm)
+	}
+
+	switch opts.Units {
+	case SIMPLIFY_UNITS_DEGREES, SIMPLIFY_UNITS_METRES:
+		// pass
+	default:
+		return fmt.Errorf("Invalid simplification units '%s'", opts.Units)
+	}
+
+	if opts.Tolerance < 0.0 || math.IsNaN(opts.Tolerance) {
+		return errors.New("Invalid simplification tolerance")
+	}
+
+	return nil
+}
+
+// SimplifyGeometry returns a simplified copy of g, which may be of any type.
+// Points are left as-is, lines keep at least two vertices and rings stay
+// closed with at least four. Valid polygons stay valid: rings that would
+// intersect themselves or each other, interior rings that would end up outside
+// their exterior ring and parts of a MultiPolygon that would overlap are
+// simplified with a smaller tolerance or, failing that, not at all.
+
+func SimplifyGeometry(g *pm_geojson.Geometry, opts *SimplifyOptions) (*pm_geojson.Geometry, error) {
+
+	if g == nil {
+		return nil, nil
+	}
+
+	err := opts.validate()
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch g.Type {
+	case pm_geojson.GeometryPoint:
+		return pm_geojson.NewPointGeometry(g.Point), nil
+	case pm_geojson.GeometryMultiPoint:
+		return pm_geojson.NewMultiPointGeometry(g.MultiPoint...), nil
+	case pm_geojson.GeometryLineString:
+
+		line, err := simplifyLine(g.LineString, opts)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return pm_geojson.NewLineStringGeometry(line), nil
+
+	case pm_geojson.GeometryMultiLineString:
+
+		lines := make([][][]float64, len(g.MultiLineString))
+
+		for i, l := range g.MultiLineString {
+
+			line, err := simplifyLine(l, opts)
+
+			if err != nil {
+				return nil, err
+			}
+
+			lines[i] = line
+		}
+
+		return pm_geojson.NewMultiLineStringGeometry(lines...), nil
+
+	case pm_geojson.GeometryPolygon:
+
+		polys, err := simplifyPolygons([][][][]float64{g.Polygon}, opts)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return pm_geojson.NewPolygonGeometry(polys[0]), nil
+
+	case pm_geojson.GeometryMultiPolygon:
+
+		polys, err := simplifyPolygons(g.MultiPolygon, opts)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return pm_geojson.NewMultiPolygonGeometry(polys...), nil
+
+	case pm_geojson.GeometryCollection:
+
+		geoms := make([]*pm_geojson.Geometry, len(g.Geometries))
+
+		for i, member := range g.Geometries {
+
+			simplified, err := SimplifyGeometry(member, opts)
+
+			if err != nil {
+				return nil, err
+			}
+
+			geoms[i] = simplified
+		}
+
+		return pm_geojson.NewCollectionGeometry(geoms...), nil
+
+	default:
+		return nil, fmt.Errorf("Unsupported geometry type '%s'", g.Type)
+	}
+}
+
+// SimplifyFeatureGeometry parses and simplifies the geometry of f. See
+// feature.SimplifyFeature for a version that returns a new feature.
+
+func SimplifyFeatureGeometry(f geojson.Feature, opts *SimplifyOptions) (*pm_geojson.Geometry, error) {
+
+	g, err := GeometryForFeature(f)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return SimplifyGeometry(g, opts)
+}
+
+func simplifyLine(coords [][]float64, opts *SimplifyOptions) ([][]float64, error) {
+
+	vertices, err := positionsToVertices(coords)
+
+	if err != nil {
+		return nil, err
+	}
+
+	vertices = continuousVertices(vertices)
+
+	if len(vertices) <= 2 {
+		return coords, nil
+	}
+
+	projected := projectForSimplify(vertices, opts.Units)
+	keep := simplifyVertices(projected, opts, 2)
+
+	return keptCoords(coords, keep), nil
+}
+
+// simplifyPolygons simplifies each of polys, the parts of a MultiPolygon or a
+// single Polygon, using simplifyPolygon. A simplified part that overlaps
+// another one, when the original parts don't, is simplified again with half
+// the tolerance until it doesn't. After SIMPLIFY_MAX_ATTEMPTS the original
+// part is used instead.
+
+func simplifyPolygons(polys [][][][]float64, opts *SimplifyOptions) ([][][][]float64, error) {
+
+	simplified := make([][][][]float64, len(polys))
+
+	for i, p := range polys {
+
+		rings, err := simplifyPolygon(p, opts)
+
+		if err != nil {
+			return nil, err
+		}
+
+		simplified[i] = rings
+	}
+
+	if len(polys) < 2 {
+		return simplified, nil
+	}
+
+	// positions have all been checked by simplifyPolygon by now
+
+	original := make([][][]geom.Coord, len(polys))
+	current := make([][][]geom.Coord, len(polys))
+	bounds := make([]*geom.Rect, len(polys))
+
+	for i := range polys {
+		original[i], _ = polygonToVertices(polys[i])
+		current[i], _ = polygonToVertices(simplified[i])
+		bounds[i] = polygonBounds(current[i])
+	}
+
+	attempts := make([]int, len(polys))
+
+	for {
+
+		changed := false
+
+		for i := range polys {
+
+			for j := 0; j < i; j++ {
+
+				if !rectsIntersect(*bounds[i], *bounds[j]) {
+					continue
+				}
+
+				if !polygonsOverlap(current[i], current[j]) || polygonsOverlap(original[i], original[j]) {
+					continue
+				}
+
+				// simplify i again unless it is already back to its
+				// original rings, in which case j must be at fault
+
+				k := i
+
+				if attempts[i] > SIMPLIFY_MAX_ATTEMPTS {
+					k = j
+				}
+
+				attempts[k] += 1
+
+				if attempts[k] > SIMPLIFY_MAX_ATTEMPTS {
+					simplified[k] = polys[k]
+				} else {
+
+					retry := *opts
+					retry.Tolerance = opts.Tolerance / math.Pow(2.0, float64(attempts[k]))
+
+					rings, err := simplifyPolygon(polys[k], &retry)
+
+					if err != nil {
+						return nil, err
+					}
+
+					simplified[k] = rings
+				}
+
+				current[k], _ = polygonToVertices(simplified[k])
+				bounds[k] = polygonBounds(current[k])
+
+				changed = true
+			}
+		}
+
+		if !changed {
+			break
+		}
+	}
+
+	return simplified, nil
+}
+
+// simplifyPolygon simplifies the rings of a polygon. If the simplified rings
+// intersect themselves or each other, or an interior ring ends up outside the
+// exterior ring, the interior rings at fault are left as they were and if that
+// isn't enough the polygon is simplified again with half the tolerance. After
+// SIMPLIFY_MAX_ATTEMPTS the original rings are used instead. Polygons that
+// aren't valid to start with are simplified without any of these checks.
+
+func simplifyPolygon(rings [][][]float64, opts *SimplifyOptions) ([][][]float64, error) {
+
+	if len(rings) == 0 {
+		return rings, nil
+	}
+
+	original, err := polygonToVertices(rings)
+
+	if err != nil {
+		return nil, err
+	}
+
+	valid := len(invalidPolygonRings(original)) == 0
+
+	retry := *opts
+
+	for attempt := 0; attempt < SIMPLIFY_MAX_ATTEMPTS; attempt++ {
+
+		simplified := make([][][]float64, len(rings))
+
+		for i, r := range rings {
+			simplified[i] = simplifyRing(r, original[i], &retry)
+		}
+
+		if !valid {
+			return simplified, nil
+		}
+
+		vertices, _ := polygonToVertices(simplified)
+		invalid := invalidPolygonRings(vertices)
+
+		if len(invalid) == 0 {
+			return simplified, nil
+		}
+
+		for _, i := range invalid {
+
+			if i > 0 {
+				simplified[i] = rings[i]
+				vertices[i] = original[i]
+			}
+		}
+
+		if len(invalidPolygonRings(vertices)) == 0 {
+			return simplified, nil
+		}
+
+		retry.Tolerance = retry.Tolerance / 2.0
+	}
+
+	return rings, nil
+}
+
+// simplifyRing simplifies a ring whose positions have already been converted
+// to vertices.
+
+func simplifyRing(coords [][]float64, vertices []geom.Coord, opts *SimplifyOptions) [][]float64 {
+
+	vertices = continuousVertices(vertices)
+	count := len(vertices)
+
+	if count <= 4 || vertices[0] != vertices[count-1] {
+		return coords
+	}
+
+	// a closed ring has three distinct vertices at the very least
+
+	projected := projectForSimplify(vertices, opts.Units)
+	keep := simplifyVertices(projected, opts, 4)
+
+	return keptCoords(coords, keep)
+}
+
+// simplifyVertices returns which of vertices to keep, never keeping fewer
+// than min_count (including the first and last vertices).
+
+func simplifyVertices(vertices []geom.Coord, opts *SimplifyOptions, min_count int) []bool {
+
+	switch opts.Algorithm {
+	case SIMPLIFY_VISVALINGAM_WHYATT:
+		return visvalingamWhyatt(vertices, opts.Tolerance*opts.Tolerance, min_count)
+	default:
+		return douglasPeucker(vertices, opts.Tolerance, min_count)
+	}
+}
+
+func douglasPeucker(vertices []geom.Coord, tolerance float64, min_count int) []bool {
+
+	count := len(vertices)
+
+	keep := make([]bool, count)
+	keep[0] = true
+	keep[count-1] = true
+
+	// the distance from each vertex to the segment it was simplified in to,
+	// used to put back vertices if too few are left
+
+	distances := make([]float64, count)
+
+	type span struct {
+		start int
+		end   int
+	}
+
+	// the first and last vertices of a ring are the same so split it at the
+	// vertex furthest from the start instead
+
+	stack := make([]span, 0)
+
+	if vertices[0] == vertices[count-1] {
+
+		split := 1
+		max_d := 0.0
+
+		for i := 1; i < count-1; i++ {
+
+			d := planarDistanceSq(vertices[i], vertices[0])
+
+			if d > max_d {
+				split = i
+				max_d = d
+			}
+		}
+
+		keep[split] = true
+		stack = append(stack, span{0, split}, span{split, count - 1})
+
+	} else {
+		stack = append(stack, span{0, count - 1})
+	}
+
+	tolerance_sq := tolerance * tolerance
+
+	for len(stack) > 0 {
+
+		s := stack[len(stack)-1]
+		stack = stack[0 : len(stack)-1]
+
+		if s.end-s.start < 2 {
+			continue
+		}
+
+		idx := -1
+		max_d := -1.0
+
+		for i := s.start + 1; i < s.end; i++ {
+
+			d := segmentDistanceSq(vertices[i], vertices[s.start], vertices[s.end])
+
+			if d > max_d {
+				idx = i
+				max_d = d
+			}
+		}
+
+		distances[idx] = max_d
+
+		if max_d > tolerance_sq {
+			keep[idx] = true
+			stack = append(stack, span{s.start, idx}, span{idx, s.end})
+		}
+	}
+
+	restoreVertices(keep, distances, min_count)
+	return keep
+}
+
+func visvalingamWhyatt(vertices []geom.Coord, tolerance float64, min_count int) []bool {
+
+	count := len(vertices)
+
+	keep := make([]bool, count)
+
+	for i := range keep {
+		keep[i] = true
+	}
+
+	prev := make([]int, count)
+	next := make([]int, count)
+
+	for i := 0; i < count; i++ {
+		prev[i] = i - 1
+		next[i] = i + 1
+	}
+
+	area := func(i int) float64 {
+
+		a := vertices[prev[i]]
+		b := vertices[i]
+		c := vertices[next[i]]
+
+		return math.Abs((b.X-a.X)*(c.Y-a.Y)-(c.X-a.X)*(b.Y-a.Y)) / 2.0
+	}
+
+	remaining := count
+
+	// this is the simple quadratic version of the algorithm, which is fine
+	// for the size of rings and lines WOF deals with
+
+	for remaining > min_count {
+
+		idx := -1
+		min_area := 0.0
+
+		for i := next[0]; i < count-1; i = next[i] {
+
+			a := area(i)
+
+			if idx == -1 || a < min_area {
+				idx = i
+				min_area = a
+			}
+		}
+
+		if idx == -1 || min_area >= tolerance {
+			break
+		}
+
+		keep[idx] = false
+		next[prev[idx]] = next[idx]
+		prev[next[idx]] = prev[idx]
+
+		remaining -= 1
+	}
+
+	return keep
+}
+
+// restoreVertices puts back the most significant vertices that were dropped
+// until at least min_count are kept.
+
+func restoreVertices(keep []bool, distances []float64, min_count int) {
+
+	for {
+
+		kept := 0
+
+		for _, k := range keep {
+
+			if k {
+				kept += 1
+			}
+		}
+
+		if kept >= min_count || kept == len(keep) {
+			return
+		}
+
+		idx := -1
+
+		for i, k := range keep {
+
+			if k {
+				continue
+			}
+
+			if idx == -1 || distances[i] > distances[idx] {
+				idx = i
+			}
+		}
+
+		keep[idx] = true
+	}
+}
+
+// projectForSimplify returns vertices in the units tolerances are measured in.
+
+func projectForSimplify(vertices []geom.Coord, units string) []geom.Coord {
+
+	if units != SIMPLIFY_UNITS_METRES {
+		return vertices
+	}
+
+	lat := 0.0
+
+	for _, v := range vertices {
+		lat += v.Y
+	}
+
+	lat = lat / float64(len(vertices))
+
+	scale := EARTH_RADIUS * math.Pi / 180.0
+	cos_lat := math.Cos(radians(lat))
+
+	projected := make([]geom.Coord, len(vertices))
+
+	for i, v := range vertices {
+		projected[i] = geom.Coord{X: v.X * scale * cos_lat, Y: v.Y * scale}
+	}
+
+	return projected
+}
+
+func planarDistanceSq(a geom.Coord, b geom.Coord) float64 {
+
+	dx := a.X - b.X
+	dy := a.Y - b.Y
+
+	return dx*dx + dy*dy
+}
+
+// positionsToVertices returns the vertices for a list of GeoJSON positions or
+// an error if any of them has fewer than two values.
+
+func positionsToVertices(coords [][]float64) ([]geom.Coord, error) {
+
+	vertices := make([]geom.Coord, len(coords))
+
+	for i, pt := range coords {
+
+		c, err := newCoord(pt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		vertices[i] = c
+	}
+
+	return vertices, nil
+}
+
+func polygonToVertices(rings [][][]float64) ([][]geom.Coord, error) {
+
+	vertices := make([][]geom.Coord, len(rings))
+
+	for i, r := range rings {
+
+		v, err := positionsToVertices(r)
+
+		if err != nil {
+			return nil, err
+		}
+
+		vertices[i] = v
+	}
+
+	return vertices, nil
+}
+
+func polygonBounds(rings [][]geom.Coord) *geom.Rect {
+
+	if len(rings) == 0 {
+		r := geom.NilRect()
+		return &r
+	}
+
+	return boundsForVertices(rings[0])
+}
+
+func coordsToVertices(coords [][]float64) []geom.Coord {
+
+	vertices := make([]geom.Coord, len(coords))
+
+	for i, c := range coords {
+		vertices[i] = geom.Coord{X: c[0], Y: c[1]}
+	}
+
+	return vertices
+}
+
+// keptCoords returns the original coordinates (including any extra
+// dimensions) that were kept.
+
+func keptCoords(coords [][]float64, keep []bool) [][]float64 {
+
+	kept := make([][]float64, 0)
+
+	for i, k := range keep {
+
+		if !k {
+			continue
+		}
+
+		c := make([]float64, len(coords[i]))
+		copy(c, coords[i])
+
+		kept = append(kept, c)
+	}
+
+	return kept
+}
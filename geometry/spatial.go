@@ -3,17 +3,79 @@ package geometry
 import (
 	"github.com/skelterjohn/geom"
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"math"
 )
 
+// The default distances, in metres, within which a coordinate is considered to
+// be "contained" by a point or a line.
+
+const DEFAULT_POINT_TOLERANCE float64 = 1.0
+const DEFAULT_LINE_TOLERANCE float64 = 1.0
+
+type ContainsCoordOptions struct {
+	// The distance, in metres, within which a coordinate matches a point
+	PointTolerance float64
+	// The distance, in metres, within which a coordinate matches a line
+	LineTolerance float64
+	// How coordinates on the boundary of a polygon are treated, one of the
+	// BOUNDARY_ constants
+	Boundary int
+}
+
+func DefaultContainsCoordOptions() *ContainsCoordOptions {
+
+	opts := &ContainsCoordOptions{
+		PointTolerance: DEFAULT_POINT_TOLERANCE,
+		LineTolerance:  DEFAULT_LINE_TOLERANCE,
+	}
+
+	return opts
+}
+
+// ContainsCoordResult describes which member, if any, of a feature's geometry
+// (as returned by MembersForFeature) contains a coordinate. Polygons are tested
+// before lines and lines before points. The first polygon containing the
+// coordinate wins; for lines and points it is the closest one within the
+// tolerance.
+//
+// If ContainsCoordOptions.Boundary is BOUNDARY_SEPARATE a coordinate that is
+// not in the interior of any polygon, but is on the boundary of one of them,
+// matches that polygon with Contains set to false and Boundary set to true.
+// Lines and points are not tested in that case.
+
+type ContainsCoordResult struct {
+	Contains bool
+	// True if the coordinate is on the boundary of the matching polygon and
+	// ContainsCoordOptions.Boundary is BOUNDARY_SEPARATE
+	Boundary bool
+	// The index of the matching member or -1
+	Index int
+	// "Point", "LineString" or "Polygon" or "" if nothing matched
+	Type string
+	// The distance in metres to the matching point or line, or 0 for polygons
+	Distance float64
+}
+
 func FeatureContainsCoord(f geojson.Feature, c geom.Coord) (bool, error) {
 
-	polys, err := PolygonsForFeature(f)
+	rsp, err := FeatureContainsCoordWithOptions(f, c, DefaultContainsCoordOptions())
 
 	if err != nil {
 		return false, err
 	}
 
-	return PolygonsContainsCoord(polys, c)
+	return rsp.Contains, nil
+}
+
+func FeatureContainsCoordWithOptions(f geojson.Feature, c geom.Coord, opts *ContainsCoordOptions) (*ContainsCoordResult, error) {
+
+	members, err := MembersForFeature(f)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return MembersContainsCoord(members, c, opts), nil
 }
 
 func PolygonsContainsCoord(polys []geojson.Polygon, c geom.Coord) (bool, error) {
@@ -30,3 +92,94 @@ func PolygonsContainsCoord(polys []geojson.Polygon, c geom.Coord) (bool, error)
 
 	return contains, nil
 }
+
+func MembersContainsCoord(members []geojson.Geometry, c geom.Coord, opts *ContainsCoordOptions) *ContainsCoordResult {
+
+	if opts == nil {
+		opts = DefaultContainsCoordOptions()
+	}
+
+	var boundary *ContainsCoordResult
+
+	for i, m := range members {
+
+		poly, ok := m.(geojson.Polygon)
+
+		if !ok {
+			continue
+		}
+
+		if opts.Boundary != BOUNDARY_SEPARATE {
+
+			if PolygonContainsCoordWithBoundary(poly, c, opts.Boundary) {
+				return &ContainsCoordResult{Contains: true, Index: i, Type: "Polygon"}
+			}
+
+			continue
+		}
+
+		switch LocateCoord(poly, c) {
+		case LOCATION_INTERIOR:
+			return &ContainsCoordResult{Contains: true, Index: i, Type: "Polygon"}
+		case LOCATION_BOUNDARY:
+
+			if boundary == nil {
+				boundary = &ContainsCoordResult{Boundary: true, Index: i, Type: "Polygon"}
+			}
+		}
+	}
+
+	if boundary != nil {
+		return boundary
+	}
+
+	rsp := &ContainsCoordResult{
+		Index: -1,
+	}
+
+	// for points and lines prefer the closest match of each type
+
+	for _, t := range []string{"LineString", "Point"} {
+
+		best := math.Inf(1)
+
+		for i, m := range members {
+
+			if MemberType(m) != t {
+				continue
+			}
+
+			var d float64
+			var tolerance float64
+
+			switch m := m.(type) {
+			case geojson.LineString:
+				d = lineDistance(m, c)
+				tolerance = opts.LineTolerance
+			case geojson.Point:
+				d = HaversineDistance(m.Coord(), c)
+				tolerance = opts.PointTolerance
+			}
+
+			if d <= tolerance && d < best {
+				best = d
+				rsp = &ContainsCoordResult{Contains: true, Index: i, Type: t, Distance: d}
+			}
+		}
+
+		if rsp.Contains {
+			return rsp
+		}
+	}
+
+	return rsp
+}
+
+func lineDistance(l geojson.LineString, c geom.Coord) float64 {
+
+	line := LineString{
+		Line: l.Path(),
+	}
+
+	return line.DistanceToCoord(c)
+}
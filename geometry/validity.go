@@ -0,0 +1,829 @@
+package geometry
+
+import (
+	"fmt"
+	"github.com/skelterjohn/geom"
+	"github.com/tidwall/gjson"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/utils"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/validation"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Rule names for the findings recorded by ValidateGeometry
+
+const (
+	VALIDITY_TYPE               string = "geometry:type"
+	VALIDITY_POSITION           string = "geometry:position"
+	VALIDITY_RANGE              string = "geometry:range"
+	VALIDITY_LINE_LENGTH        string = "geometry:line_length"
+	VALIDITY_RING_LENGTH        string = "geometry:ring_length"
+	VALIDITY_RING_CLOSED        string = "geometry:ring_closed"
+	VALIDITY_SELF_INTERSECTION  string = "geometry:self_intersection"
+	VALIDITY_RING_INTERSECTION  string = "geometry:ring_intersection"
+	VALIDITY_HOLE_OUTSIDE_SHELL string = "geometry:hole_outside_shell"
+	VALIDITY_NESTED_HOLE        string = "geometry:nested_hole"
+	VALIDITY_OVERLAPPING_PARTS  string = "geometry:overlapping_parts"
+	VALIDITY_WINDING_ORDER      string = "geometry:winding_order"
+)
+
+// ValidityError describes a single problem with a geometry. Part is the index
+// of a polygon or line in a MultiPolygon or MultiLineString, Ring is the index
+// of a ring in a polygon and Vertex is the index of a position in a ring, line
+// or MultiPoint. Each of them is -1 if it doesn't apply. Path is the (gjson)
+// path of the offending value.
+
+type ValidityError struct {
+	Rule      string
+	Path      string
+	FeatureId string
+	Part      int
+	Ring      int
+	Vertex    int
+	Message   string
+	Warning   bool
+}
+
+func (e *ValidityError) Error() string {
+
+	where := make([]string, 0)
+
+	if e.Part >= 0 {
+		where = append(where, fmt.Sprintf("part %d", e.Part))
+	}
+
+	if e.Ring >= 0 {
+		where = append(where, fmt.Sprintf("ring %d", e.Ring))
+	}
+
+	if e.Vertex >= 0 {
+		where = append(where, fmt.Sprintf("vertex %d", e.Vertex))
+	}
+
+	if len(where) == 0 {
+		return e.Message
+	}
+
+	return fmt.Sprintf("%s (%s)", e.Message, strings.Join(where, ", "))
+}
+
+func (e *ValidityError) Is(target error) bool {
+	return target == geojson.ErrInvalidGeometry
+}
+
+// IsWarning reports whether the problem is a warning, like a ring that doesn't
+// follow the RFC 7946 right-hand rule, rather than an invalid geometry.
+
+func (e *ValidityError) IsWarning() bool {
+	return e.Warning
+}
+
+// ValidateFeatureGeometry checks the geometry of f. See ValidateGeometry for
+// details.
+
+func ValidateFeatureGeometry(f geojson.Feature) *validation.Report {
+	return ValidateGeometry(f.Bytes())
+}
+
+// ValidateGeometry checks the geometry of a GeoJSON feature and reports every
+// problem it finds: malformed or out of range positions, lines with fewer than
+// two positions, rings with fewer than four positions or that aren't closed,
+// rings that intersect themselves or each other, interior rings outside of
+// their exterior ring or inside another interior ring and the parts of a
+// MultiPolygon that overlap each other. Rings that don't follow the right-hand rule (exterior
+// rings counter-clockwise, interior rings clockwise) are reported as warnings.
+// A null geometry is valid.
+
+func ValidateGeometry(body []byte) *validation.Report {
+
+	report := validation.NewReport()
+	ValidateGeometryWithReport(body, report)
+
+	return report
+}
+
+// ValidateGeometryWithReport is like ValidateGeometry but appends its findings
+// to report.
+
+func ValidateGeometryWithReport(body []byte, report *validation.Report) {
+
+	v := &validityChecker{
+		report:     report,
+		feature_id: utils.FeatureId(body),
+	}
+
+	geom_rsp := gjson.GetBytes(body, "geometry")
+
+	if !geom_rsp.Exists() {
+		v.add(VALIDITY_TYPE, "geometry", -1, -1, -1, false, "Missing geometry")
+		return
+	}
+
+	v.checkGeometry(geom_rsp, "geometry")
+}
+
+type validityChecker struct {
+	report     *validation.Report
+	feature_id string
+}
+
+func (v *validityChecker) add(rule string, path string, part int, ring int, vertex int, warning bool, msg string, args ...interface{}) {
+
+	err := &ValidityError{
+		Rule:      rule,
+		Path:      path,
+		FeatureId: v.feature_id,
+		Part:      part,
+		Ring:      ring,
+		Vertex:    vertex,
+		Message:   fmt.Sprintf(msg, args...),
+		Warning:   warning,
+	}
+
+	severity := validation.SEVERITY_ERROR
+
+	if warning {
+		severity = validation.SEVERITY_WARNING
+	}
+
+	f := &validation.Finding{
+		Severity: severity,
+		Path:     path,
+		Rule:     rule,
+		Err:      err,
+	}
+
+	v.report.AddFinding(f)
+}
+
+func (v *validityChecker) checkGeometry(g gjson.Result, path string) {
+
+	if g.Type == gjson.Null {
+		return
+	}
+
+	coords := g.Get("coordinates")
+	coords_path := path + ".coordinates"
+
+	geom_type := g.Get("type").String()
+
+	switch geom_type {
+	case "Point":
+
+		v.checkPosition(coords, coords_path, -1, -1, -1)
+
+	case "MultiPoint":
+
+		if !v.checkArray(coords, coords_path, -1, -1) {
+			return
+		}
+
+		for i, pt := range coords.Array() {
+			v.checkPosition(pt, fmt.Sprintf("%s.%d", coords_path, i), -1, -1, i)
+		}
+
+	case "LineString":
+
+		v.checkLine(coords, coords_path, -1)
+
+	case "MultiLineString":
+
+		if !v.checkArray(coords, coords_path, -1, -1) {
+			return
+		}
+
+		for i, l := range coords.Array() {
+			v.checkLine(l, fmt.Sprintf("%s.%d", coords_path, i), i)
+		}
+
+	case "Polygon":
+
+		v.checkPolygon(coords, coords_path, -1)
+
+	case "MultiPolygon":
+
+		if !v.checkArray(coords, coords_path, -1, -1) {
+			return
+		}
+
+		parts := make([][][]geom.Coord, 0)
+
+		for i, p := range coords.Array() {
+			parts = append(parts, v.checkPolygon(p, fmt.Sprintf("%s.%d", coords_path, i), i))
+		}
+
+		// parts that aren't well-formed are nil and skipped
+
+		for i := 1; i < len(parts); i++ {
+
+			for j := 0; j < i; j++ {
+
+				if parts[i] == nil || parts[j] == nil {
+					continue
+				}
+
+				if polygonsOverlap(parts[j], parts[i]) {
+					v.add(VALIDITY_OVERLAPPING_PARTS, fmt.Sprintf("%s.%d", coords_path, i), i, -1, -1, false, "Polygon overlaps part %d", j)
+				}
+			}
+		}
+
+	case "GeometryCollection":
+
+		geoms := g.Get("geometries")
+
+		if !geoms.IsArray() {
+			v.add(VALIDITY_TYPE, path+".geometries", -1, -1, -1, false, "GeometryCollection is missing a list of geometries")
+			return
+		}
+
+		for i, member := range geoms.Array() {
+			v.checkGeometry(member, fmt.Sprintf("%s.geometries.%d", path, i))
+		}
+
+	default:
+		v.add(VALIDITY_TYPE, path+".type", -1, -1, -1, false, "Invalid geometry type '%s'", geom_type)
+	}
+}
+
+func (v *validityChecker) checkArray(r gjson.Result, path string, part int, ring int) bool {
+
+	if !r.IsArray() {
+		v.add(VALIDITY_POSITION, path, part, ring, -1, false, "Coordinates are not a list")
+		return false
+	}
+
+	return true
+}
+
+// checkPosition returns the position at path and whether it is well-formed.
+// Positions that are well-formed but out of range are reported but still
+// count as well-formed.
+
+func (v *validityChecker) checkPosition(r gjson.Result, path string, part int, ring int, vertex int) (geom.Coord, bool) {
+
+	var c geom.Coord
+
+	if !r.IsArray() {
+		v.add(VALIDITY_POSITION, path, part, ring, vertex, false, "Position is not a list")
+		return c, false
+	}
+
+	values := r.Array()
+
+	if len(values) < 2 {
+		v.add(VALIDITY_POSITION, path, part, ring, vertex, false, "Position has fewer than two values")
+		return c, false
+	}
+
+	for _, n := range values {
+
+		f := n.Float()
+
+		if n.Type != gjson.Number || math.IsNaN(f) || math.IsInf(f, 0) {
+			v.add(VALIDITY_POSITION, path, part, ring, vertex, false, "Position has a value that is not a finite number '%s'", n.Raw)
+			return c, false
+		}
+	}
+
+	c = geom.Coord{X: values[0].Float(), Y: values[1].Float()}
+
+	if c.X < -180.0 || c.X > 180.0 {
+		v.add(VALIDITY_RANGE, path, part, ring, vertex, false, "Longitude %f is out of range", c.X)
+	}
+
+	if c.Y < -90.0 || c.Y > 90.0 {
+		v.add(VALIDITY_RANGE, path, part, ring, vertex, false, "Latitude %f is out of range", c.Y)
+	}
+
+	return c, true
+}
+
+func (v *validityChecker) checkPositions(r gjson.Result, path string, part int, ring int) ([]geom.Coord, bool) {
+
+	if !v.checkArray(r, path, part, ring) {
+		return nil, false
+	}
+
+	vertices := make([]geom.Coord, 0)
+	ok := true
+
+	for i, pt := range r.Array() {
+
+		c, pt_ok := v.checkPosition(pt, fmt.Sprintf("%s.%d", path, i), part, ring, i)
+
+		if !pt_ok {
+			ok = false
+			continue
+		}
+
+		vertices = append(vertices, c)
+	}
+
+	return vertices, ok
+}
+
+func (v *validityChecker) checkLine(r gjson.Result, path string, part int) {
+
+	vertices, ok := v.checkPositions(r, path, part, -1)
+
+	if ok && len(vertices) < 2 {
+		v.add(VALIDITY_LINE_LENGTH, path, part, -1, -1, false, "Line has fewer than two positions")
+	}
+}
+
+// checkPolygon returns the rings of the polygon at path, or nil if any of them
+// aren't well-formed.
+
+func (v *validityChecker) checkPolygon(r gjson.Result, path string, part int) [][]geom.Coord {
+
+	if !v.checkArray(r, path, part, -1) {
+		return nil
+	}
+
+	ring_results := r.Array()
+
+	if len(ring_results) == 0 {
+		v.add(VALIDITY_RING_LENGTH, path, part, -1, -1, false, "Polygon has no rings")
+		return nil
+	}
+
+	rings := make([][]geom.Coord, len(ring_results))
+	ok := true
+
+	for i, ring_rsp := range ring_results {
+
+		ring_path := fmt.Sprintf("%s.%d", path, i)
+		vertices, ring_ok := v.checkPositions(ring_rsp, ring_path, part, i)
+
+		if !ring_ok {
+			ok = false
+			continue
+		}
+
+		count := len(vertices)
+
+		if count < 4 {
+			v.add(VALIDITY_RING_LENGTH, ring_path, part, i, -1, false, "Ring has fewer than four positions")
+			ok = false
+			continue
+		}
+
+		if vertices[0] != vertices[count-1] {
+			v.add(VALIDITY_RING_CLOSED, fmt.Sprintf("%s.%d", ring_path, count-1), part, i, count-1, false, "Ring is not closed")
+			ok = false
+			continue
+		}
+
+		rings[i] = vertices
+	}
+
+	// the remaining checks only make sense for well-formed rings
+
+	if !ok {
+		return nil
+	}
+
+	original := rings
+
+	rings = unwrapRings(rings)
+	ext := rings[0]
+
+	for i, ring := range rings {
+
+		area := planarRingArea(ring)
+
+		if i == 0 && area < 0.0 {
+			v.add(VALIDITY_WINDING_ORDER, fmt.Sprintf("%s.%d", path, i), part, i, -1, true, "Exterior ring is clockwise")
+		}
+
+		if i > 0 && area > 0.0 {
+			v.add(VALIDITY_WINDING_ORDER, fmt.Sprintf("%s.%d", path, i), part, i, -1, true, "Interior ring is counter-clockwise")
+		}
+	}
+
+	for _, x := range ringIntersections(rings) {
+
+		vertex_path := fmt.Sprintf("%s.%d.%d", path, x.ring, x.vertex)
+
+		if x.ring == x.other_ring {
+			v.add(VALIDITY_SELF_INTERSECTION, vertex_path, part, x.ring, x.vertex, false, "Ring intersects itself at segment %d", x.other_vertex)
+		} else {
+			v.add(VALIDITY_RING_INTERSECTION, vertex_path, part, x.ring, x.vertex, false, "Ring crosses ring %d at segment %d", x.other_ring, x.other_vertex)
+		}
+	}
+
+	for i := 1; i < len(rings); i++ {
+
+		j := vertexOutsideRing(rings[i], ext)
+
+		if j != -1 {
+			v.add(VALIDITY_HOLE_OUTSIDE_SHELL, fmt.Sprintf("%s.%d.%d", path, i, j), part, i, j, false, "Interior ring is outside the exterior ring")
+			continue
+		}
+
+		outer := holeContainingHole(rings, i)
+
+		if outer != -1 {
+			v.add(VALIDITY_NESTED_HOLE, fmt.Sprintf("%s.%d", path, i), part, i, -1, false, "Interior ring is inside interior ring %d", outer)
+		}
+	}
+
+	return original
+}
+
+// unwrapRings returns rings unchanged unless the first of them crosses the
+// antimeridian in which case they are all unwrapped relative to its first
+// vertex.
+
+func unwrapRings(rings [][]geom.Coord) [][]geom.Coord {
+
+	if len(rings) == 0 || len(rings[0]) == 0 || !crossesAntimeridian(rings[0]) {
+		return rings
+	}
+
+	return unwrapRingsFrom(rings, rings[0][0].X)
+}
+
+func unwrapRingsFrom(rings [][]geom.Coord, ref float64) [][]geom.Coord {
+
+	unwrapped := make([][]geom.Coord, len(rings))
+
+	for i, ring := range rings {
+		unwrapped[i] = unwrapVertices(ring, ref)
+	}
+
+	return unwrapped
+}
+
+// vertexOutsideRing returns the index of the first vertex of ring that is
+// neither inside nor on other or -1 if there isn't one.
+
+func vertexOutsideRing(ring []geom.Coord, other []geom.Coord) int {
+
+	for i, c := range ring {
+
+		if ringContainsCoord(other, c) || onRing(c, other) {
+			continue
+		}
+
+		return i
+	}
+
+	return -1
+}
+
+// holeContainingHole returns the index of the first interior ring, other than
+// the one at index i, that the interior ring at index i is inside or -1 if
+// there isn't one.
+
+func holeContainingHole(rings [][]geom.Coord, i int) int {
+
+	for j := 1; j < len(rings); j++ {
+
+		if j == i {
+			continue
+		}
+
+		if polygonContainsRing([][]geom.Coord{rings[j]}, rings[i]) {
+			return j
+		}
+	}
+
+	return -1
+}
+
+// invalidPolygonRings returns the indices, in ascending order, of the closed
+// rings of a polygon (exterior ring first) that intersect themselves or each
+// other and of the interior rings that are outside the exterior ring or inside
+// another interior ring.
+
+func invalidPolygonRings(rings [][]geom.Coord) []int {
+
+	indices := make([]int, 0)
+
+	if len(rings) == 0 {
+		return indices
+	}
+
+	rings = unwrapRings(rings)
+	invalid := make(map[int]bool)
+
+	for _, x := range ringIntersections(rings) {
+		invalid[x.ring] = true
+		invalid[x.other_ring] = true
+	}
+
+	for i := 1; i < len(rings); i++ {
+
+		if vertexOutsideRing(rings[i], rings[0]) != -1 || holeContainingHole(rings, i) != -1 {
+			invalid[i] = true
+		}
+	}
+
+	for i := range invalid {
+		indices = append(indices, i)
+	}
+
+	sort.Ints(indices)
+	return indices
+}
+
+// polygonsOverlap reports whether two polygons, each a list of closed rings
+// with the exterior ring first, have any part of their interiors in common or
+// share an edge. Polygons that only touch at points don't overlap.
+
+func polygonsOverlap(a [][]geom.Coord, b [][]geom.Coord) bool {
+
+	if len(a) == 0 || len(b) == 0 || len(a[0]) == 0 || len(b[0]) == 0 {
+		return false
+	}
+
+	if !rectsIntersect(*boundsForVertices(a[0]), *boundsForVertices(b[0])) {
+		return false
+	}
+
+	// both polygons are unwrapped relative to the same longitude so that
+	// parts on either side of the antimeridian can be compared
+
+	if crossesAntimeridian(a[0]) || crossesAntimeridian(b[0]) {
+		ref := a[0][0].X
+		a = unwrapRingsFrom(a, ref)
+		b = unwrapRingsFrom(b, ref)
+	}
+
+	rings := append(append([][]geom.Coord{}, a...), b...)
+
+	for _, x := range ringIntersections(rings) {
+
+		if (x.ring < len(a)) != (x.other_ring < len(a)) {
+			return true
+		}
+	}
+
+	// the rings of one polygon don't cross those of the other so either one
+	// polygon is inside the other or they are apart
+
+	return polygonContainsRing(a, b[0]) || polygonContainsRing(b, a[0])
+}
+
+// polygonContainsRing reports whether the first vertex of ring that isn't on
+// one of the rings of a polygon is inside that polygon.
+
+func polygonContainsRing(rings [][]geom.Coord, ring []geom.Coord) bool {
+
+	for _, c := range ring {
+
+		on_ring := false
+
+		for _, other := range rings {
+
+			if onRing(c, other) {
+				on_ring = true
+				break
+			}
+		}
+
+		if !on_ring {
+			return evenOddContains(rings, c)
+		}
+	}
+
+	return false
+}
+
+func onRing(c geom.Coord, ring []geom.Coord) bool {
+
+	for i := 1; i < len(ring); i++ {
+
+		if onSegment(c, ring[i-1], ring[i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type ringIntersection struct {
+	ring         int
+	vertex       int
+	other_ring   int
+	other_vertex int
+}
+
+type validitySegment struct {
+	a        geom.Coord
+	b        geom.Coord
+	ring     int
+	vertex   int // the index of a in the original ring
+	position int // the index of the segment once zero-length segments are skipped
+	min_x    float64
+	max_x    float64
+}
+
+// ringIntersections returns the places where closed rings intersect each other
+// or themselves. Rings may touch each other at a single point but not cross
+// or share an edge. A ring may only touch itself where consecutive segments
+// meet. Segments are swept from west to east so that only those whose
+// longitudes overlap are compared.
+
+func ringIntersections(rings [][]geom.Coord) []ringIntersection {
+
+	segments := make([]validitySegment, 0)
+	counts := make([]int, len(rings))
+
+	for r, ring := range rings {
+
+		position := 0
+
+		for i := 1; i < len(ring); i++ {
+
+			a := ring[i-1]
+			b := ring[i]
+
+			if a == b {
+				continue
+			}
+
+			s := validitySegment{
+				a:        a,
+				b:        b,
+				ring:     r,
+				vertex:   i - 1,
+				position: position,
+				min_x:    math.Min(a.X, b.X),
+				max_x:    math.Max(a.X, b.X),
+			}
+
+			segments = append(segments, s)
+			position += 1
+		}
+
+		counts[r] = position
+	}
+
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].min_x < segments[j].min_x
+	})
+
+	results := make([]ringIntersection, 0)
+
+	for i, s := range segments {
+
+		for j := i + 1; j < len(segments) && segments[j].min_x <= s.max_x; j++ {
+
+			o := segments[j]
+
+			if math.Max(s.a.Y, s.b.Y) < math.Min(o.a.Y, o.b.Y) || math.Min(s.a.Y, s.b.Y) > math.Max(o.a.Y, o.b.Y) {
+				continue
+			}
+
+			if !segmentsConflict(s, o, counts) {
+				continue
+			}
+
+			first := s
+			second := o
+
+			if o.ring < s.ring || (o.ring == s.ring && o.vertex < s.vertex) {
+				first, second = o, s
+			}
+
+			x := ringIntersection{
+				ring:         second.ring,
+				vertex:       second.vertex,
+				other_ring:   first.ring,
+				other_vertex: first.vertex,
+			}
+
+			results = append(results, x)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+
+		if results[i].ring != results[j].ring {
+			return results[i].ring < results[j].ring
+		}
+
+		return results[i].vertex < results[j].vertex
+	})
+
+	return results
+}
+
+// segmentsConflict reports whether two ring segments intersect in a way that
+// makes a polygon invalid.
+
+func segmentsConflict(s validitySegment, o validitySegment, counts []int) bool {
+
+	if s.ring == o.ring {
+
+		count := counts[s.ring]
+		diff := s.position - o.position
+
+		if diff < 0 {
+			diff = -diff
+		}
+
+		adjacent := diff == 1 || diff == count-1
+
+		if adjacent {
+
+			// consecutive segments share a vertex but must not fold
+			// back over each other
+
+			shared, p, q := s.a, s.b, o.b
+
+			switch {
+			case s.a == o.b:
+				shared, p, q = s.a, s.b, o.a
+			case s.b == o.a:
+				shared, p, q = s.b, s.a, o.b
+			case s.b == o.b:
+				shared, p, q = s.b, s.a, o.a
+			}
+
+			return orientation(p, shared, q) == 0 && (p.X-shared.X)*(q.X-shared.X)+(p.Y-shared.Y)*(q.Y-shared.Y) > 0
+		}
+
+		return segmentsIntersect(s.a, s.b, o.a, o.b)
+	}
+
+	// different rings may touch at a point but not cross or overlap
+
+	if !segmentsIntersect(s.a, s.b, o.a, o.b) {
+		return false
+	}
+
+	d1 := orientation(s.a, s.b, o.a)
+	d2 := orientation(s.a, s.b, o.b)
+	d3 := orientation(o.a, o.b, s.a)
+	d4 := orientation(o.a, o.b, s.b)
+
+	if d1*d2 < 0 && d3*d4 < 0 {
+		return true
+	}
+
+	if d1 == 0 && d2 == 0 {
+		return collinearOverlap(s.a, s.b, o.a, o.b)
+	}
+
+	return false
+}
+
+func orientation(a geom.Coord, b geom.Coord, c geom.Coord) int {
+
+	v := (b.X-a.X)*(c.Y-a.Y) - (b.Y-a.Y)*(c.X-a.X)
+
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func segmentsIntersect(a0 geom.Coord, a1 geom.Coord, b0 geom.Coord, b1 geom.Coord) bool {
+
+	d1 := orientation(a0, a1, b0)
+	d2 := orientation(a0, a1, b1)
+	d3 := orientation(b0, b1, a0)
+	d4 := orientation(b0, b1, a1)
+
+	if d1*d2 < 0 && d3*d4 < 0 {
+		return true
+	}
+
+	within := func(a geom.Coord, b geom.Coord, c geom.Coord) bool {
+		return math.Min(a.X, b.X) <= c.X && c.X <= math.Max(a.X, b.X) && math.Min(a.Y, b.Y) <= c.Y && c.Y <= math.Max(a.Y, b.Y)
+	}
+
+	return (d1 == 0 && within(a0, a1, b0)) ||
+		(d2 == 0 && within(a0, a1, b1)) ||
+		(d3 == 0 && within(b0, b1, a0)) ||
+		(d4 == 0 && within(b0, b1, a1))
+}
+
+// collinearOverlap reports whether two collinear segments share more than a
+// single point.
+
+func collinearOverlap(a0 geom.Coord, a1 geom.Coord, b0 geom.Coord, b1 geom.Coord) bool {
+
+	dx := a1.X - a0.X
+	dy := a1.Y - a0.Y
+
+	t0 := (b0.X-a0.X)*dx + (b0.Y-a0.Y)*dy
+	t1 := (b1.X-a0.X)*dx + (b1.Y-a0.Y)*dy
+
+	length := dx*dx + dy*dy
+
+	lo := math.Max(0.0, math.Min(t0, t1))
+	hi := math.Min(length, math.Max(t0, t1))
+
+	return hi > lo
+}
@@ -3,6 +3,7 @@ module github.com/whosonfirst/go-whosonfirst-geojson-v2
 go 1.12
 
 require (
+	github.com/hashicorp/go-multierror v0.0.0-20171204182908-b7773ae21874
 	github.com/mmcloughlin/geohash v0.10.0
 	github.com/paulmach/go.geojson v1.4.0
 	github.com/sfomuseum/go-edtf v0.3.1
@@ -0,0 +1,187 @@
+package index
+
+import (
+	"fmt"
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/geometry"
+	"github.com/whosonfirst/go-whosonfirst-spr/v2"
+	"sort"
+	"sync"
+)
+
+// GeohashIndex is an in-memory index of features keyed by the cells of their
+// geohash covers (see geometry.GeohashCoverForFeature). It is the same scheme a
+// key-value store would use: a coordinate's candidates are found by looking up
+// each prefix of its geohash (see geometry.GeohashesForCoord). Features whose
+// cover has an interior cell containing a coordinate are known to contain it
+// without testing their geometry. It is safe to query an index while features
+// are being added to it.
+
+type GeohashIndex struct {
+	mu       *sync.RWMutex
+	opts     *geometry.GeohashCoverOptions
+	cells    map[string][]*GeohashCandidate
+	features map[string]*indexRecord
+}
+
+// GeohashCandidate is a feature whose geohash cover contains a coordinate.
+// Location is geometry.LOCATION_INTERIOR if the feature definitely contains
+// the coordinate and geometry.LOCATION_BOUNDARY if it might.
+
+type GeohashCandidate struct {
+	Id       string
+	Hash     string
+	Location int
+}
+
+// NewGeohashIndex returns a GeohashIndex that covers features using opts, or
+// geometry.DefaultGeohashCoverOptions if opts is nil.
+
+func NewGeohashIndex(opts *geometry.GeohashCoverOptions) *GeohashIndex {
+
+	if opts == nil {
+		opts = geometry.DefaultGeohashCoverOptions()
+	}
+
+	idx := &GeohashIndex{
+		mu:       new(sync.RWMutex),
+		opts:     opts,
+		cells:    make(map[string][]*GeohashCandidate),
+		features: make(map[string]*indexRecord),
+	}
+
+	return idx
+}
+
+// Len returns the number of features in the index.
+
+func (idx *GeohashIndex) Len() int {
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return len(idx.features)
+}
+
+// Add indexes f. Features are keyed by ID and it is an error to add the same
+// one twice. Only the polygons in a feature are covered so features without
+// any are accepted but will never be returned by a query.
+
+func (idx *GeohashIndex) Add(f geojson.Feature) error {
+
+	id := f.Id()
+
+	s, err := f.SPR()
+
+	if err != nil {
+		return err
+	}
+
+	cells, err := geometry.GeohashCoverForFeature(f, idx.opts)
+
+	if err != nil {
+		return err
+	}
+
+	record := &indexRecord{
+		feature: f,
+		spr:     s,
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	_, exists := idx.features[id]
+
+	if exists {
+		return fmt.Errorf("Feature %s has already been indexed", id)
+	}
+
+	idx.features[id] = record
+
+	for _, cell := range cells {
+
+		candidate := &GeohashCandidate{
+			Id:       id,
+			Hash:     cell.Hash,
+			Location: cell.Location,
+		}
+
+		idx.cells[cell.Hash] = append(idx.cells[cell.Hash], candidate)
+	}
+
+	return nil
+}
+
+// Candidates returns the features whose geohash covers contain c, sorted by
+// ID.
+
+func (idx *GeohashIndex) Candidates(c geom.Coord) []*GeohashCandidate {
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	candidates := make([]*GeohashCandidate, 0)
+
+	for _, hash := range geometry.GeohashesForCoord(c, idx.opts.MaxPrecision) {
+		candidates = append(candidates, idx.cells[hash]...)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Id < candidates[j].Id
+	})
+
+	return candidates
+}
+
+// Query returns the SPRs of every indexed feature that contains c and matches
+// filters (which may be nil), sorted by ID. Only candidates on the boundary of
+// their covers are tested using the feature's ContainsCoord method.
+
+func (idx *GeohashIndex) Query(c geom.Coord, filters *QueryFilters) ([]spr.StandardPlacesResult, error) {
+
+	candidates := idx.Candidates(c)
+
+	idx.mu.RLock()
+
+	records := make([]*indexRecord, len(candidates))
+
+	for i, candidate := range candidates {
+		records[i] = idx.features[candidate.Id]
+	}
+
+	idx.mu.RUnlock()
+
+	results := make([]spr.StandardPlacesResult, 0)
+
+	for i, r := range records {
+
+		ok, err := matchesQueryFilters(r.spr, filters)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			continue
+		}
+
+		if candidates[i].Location == geometry.LOCATION_BOUNDARY {
+
+			ok, err = r.feature.ContainsCoord(c)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if !ok {
+				continue
+			}
+		}
+
+		results = append(results, r.spr)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,324 @@
+package index
+
+import (
+	"fmt"
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-flags"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/geometry"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/utils"
+	"github.com/whosonfirst/go-whosonfirst-spr/v2"
+	"math"
+	"sort"
+	"sync"
+)
+
+// Index is an in-memory spatial index of features. The bounding box of each
+// member of a feature's geometry, padded by the default point and line
+// tolerances (see geometry.DefaultContainsCoordOptions), is stored in an RTree
+// and candidates whose bounding boxes contain a coordinate are then tested
+// using the feature's ContainsCoord method. It is safe to query an index
+// while features are being added to it.
+
+type Index struct {
+	mu       *sync.RWMutex
+	tree     *RTree
+	features map[string]*indexRecord
+}
+
+type indexRecord struct {
+	feature geojson.Feature
+	spr     spr.StandardPlacesResult
+}
+
+// QueryFilters limits the results of a query, using the same conventions as
+// walk.Filters, and are tested against each feature's SPR.
+
+type QueryFilters struct {
+	IncludePlacetypes []string
+	ExcludePlacetypes []string
+	// Existential flags ("is_current", "is_ceased", "is_deprecated", "is_superseded", "is_superseding") that must all be true
+	IncludeExistential []string
+	// Existential flags that, if any are true, will cause a feature to be excluded
+	ExcludeExistential []string
+}
+
+func NewIndex() *Index {
+
+	idx := &Index{
+		mu:       new(sync.RWMutex),
+		tree:     NewRTree(),
+		features: make(map[string]*indexRecord),
+	}
+
+	return idx
+}
+
+// Len returns the number of features in the index.
+
+func (idx *Index) Len() int {
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return len(idx.features)
+}
+
+// Add indexes f. Features are keyed by ID and it is an error to add the same
+// one twice. Features without a geometry are accepted but will never be
+// returned by a query.
+
+func (idx *Index) Add(f geojson.Feature) error {
+
+	id := f.Id()
+
+	s, err := f.SPR()
+
+	if err != nil {
+		return err
+	}
+
+	bboxes, err := f.BoundingBoxes()
+
+	if err != nil {
+		return err
+	}
+
+	record := &indexRecord{
+		feature: f,
+		spr:     s,
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	_, exists := idx.features[id]
+
+	if exists {
+		return fmt.Errorf("Feature %s has already been indexed", id)
+	}
+
+	idx.features[id] = record
+
+	// ContainsCoord matches coordinates near, rather than on, points and
+	// lines so their (often zero-sized) bounds are padded by the same
+	// distance for them to be found
+
+	tolerance := math.Max(geometry.DEFAULT_POINT_TOLERANCE, geometry.DEFAULT_LINE_TOLERANCE)
+
+	for _, r := range bboxes.Bounds() {
+
+		for _, part := range splitAntimeridian(padRect(*r, tolerance)) {
+			idx.tree.Insert(part, id)
+		}
+	}
+
+	return nil
+}
+
+// Feature returns the indexed feature with ID id.
+
+func (idx *Index) Feature(id string) (geojson.Feature, bool) {
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	record, ok := idx.features[id]
+
+	if !ok {
+		return nil, false
+	}
+
+	return record.feature, true
+}
+
+// Query returns the SPRs of every indexed feature that contains c and matches
+// filters (which may be nil), sorted by ID.
+
+func (idx *Index) Query(c geom.Coord, filters *QueryFilters) ([]spr.StandardPlacesResult, error) {
+
+	idx.mu.RLock()
+
+	candidates := make([]*indexRecord, 0)
+	seen := make(map[string]bool)
+
+	for _, v := range idx.tree.SearchCoord(c) {
+
+		id := v.(string)
+
+		if seen[id] {
+			continue
+		}
+
+		seen[id] = true
+		candidates = append(candidates, idx.features[id])
+	}
+
+	idx.mu.RUnlock()
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].feature.Id() < candidates[j].feature.Id()
+	})
+
+	results := make([]spr.StandardPlacesResult, 0)
+
+	for _, r := range candidates {
+
+		ok, err := matchesQueryFilters(r.spr, filters)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			continue
+		}
+
+		ok, err = r.feature.ContainsCoord(c)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			results = append(results, r.spr)
+		}
+	}
+
+	return results, nil
+}
+
+// padRect returns r grown by metres in every direction. Longitudes are
+// normalized so the result may cross the antimeridian.
+
+func padRect(r geom.Rect, metres float64) geom.Rect {
+
+	dy := metres / (geometry.EARTH_RADIUS * math.Pi / 180.0)
+
+	min_y := math.Max(-90.0, r.Min.Y-dy)
+	max_y := math.Min(90.0, r.Max.Y+dy)
+
+	// a degree of longitude is shortest at the latitude furthest from the
+	// equator
+
+	cos_lat := math.Cos(math.Max(math.Abs(min_y), math.Abs(max_y)) * math.Pi / 180.0)
+
+	width := r.Max.X - r.Min.X
+
+	if utils.RectCrossesAntimeridian(r) {
+		width += 360.0
+	}
+
+	padded := geom.Rect{
+		Min: geom.Coord{X: -180.0, Y: min_y},
+		Max: geom.Coord{X: 180.0, Y: max_y},
+	}
+
+	if cos_lat <= 0.0 || width+2.0*dy/cos_lat >= 360.0 {
+		return padded
+	}
+
+	dx := dy / cos_lat
+
+	padded.Min.X = utils.NormalizeLongitude(r.Min.X - dx)
+	padded.Max.X = utils.NormalizeLongitude(r.Max.X + dx)
+
+	return padded
+}
+
+// splitAntimeridian returns r, or the two halves of r if it crosses the
+// antimeridian, since the RTree only deals in planar rectangles.
+
+func splitAntimeridian(r geom.Rect) []geom.Rect {
+
+	if !utils.RectCrossesAntimeridian(r) {
+		return []geom.Rect{r}
+	}
+
+	west := geom.Rect{
+		Min: geom.Coord{X: r.Min.X, Y: r.Min.Y},
+		Max: geom.Coord{X: 180.0, Y: r.Max.Y},
+	}
+
+	east := geom.Rect{
+		Min: geom.Coord{X: -180.0, Y: r.Min.Y},
+		Max: geom.Coord{X: r.Max.X, Y: r.Max.Y},
+	}
+
+	return []geom.Rect{west, east}
+}
+
+func matchesQueryFilters(s spr.StandardPlacesResult, filters *QueryFilters) (bool, error) {
+
+	if filters == nil {
+		return true, nil
+	}
+
+	pt := s.Placetype()
+
+	if len(filters.IncludePlacetypes) > 0 && !stringInSlice(pt, filters.IncludePlacetypes) {
+		return false, nil
+	}
+
+	if stringInSlice(pt, filters.ExcludePlacetypes) {
+		return false, nil
+	}
+
+	for _, name := range filters.IncludeExistential {
+
+		fl, err := existentialFlag(s, name)
+
+		if err != nil {
+			return false, err
+		}
+
+		if !fl.IsTrue() || !fl.IsKnown() {
+			return false, nil
+		}
+	}
+
+	for _, name := range filters.ExcludeExistential {
+
+		fl, err := existentialFlag(s, name)
+
+		if err != nil {
+			return false, err
+		}
+
+		if fl.IsTrue() && fl.IsKnown() {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func existentialFlag(s spr.StandardPlacesResult, name string) (flags.ExistentialFlag, error) {
+
+	switch name {
+	case "is_current":
+		return s.IsCurrent(), nil
+	case "is_ceased":
+		return s.IsCeased(), nil
+	case "is_deprecated":
+		return s.IsDeprecated(), nil
+	case "is_superseded":
+		return s.IsSuperseded(), nil
+	case "is_superseding":
+		return s.IsSuperseding(), nil
+	default:
+		return nil, fmt.Errorf("Invalid existential flag '%s'", name)
+	}
+}
+
+func stringInSlice(value string, candidates []string) bool {
+
+	for _, c := range candidates {
+
+		if c == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,115 @@
+package index
+
+import (
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/geometry"
+	"github.com/whosonfirst/go-whosonfirst-spr/v2"
+	"sort"
+)
+
+// NearestResult is one of the features returned by Index.Nearest.
+
+type NearestResult struct {
+	SPR spr.StandardPlacesResult
+	// The distance, in metres, from the query coordinate to the closest point
+	// of the feature's geometry, see geometry.DistanceToFeature
+	Distance float64
+	// The closest point of the feature's geometry
+	Coord geom.Coord
+}
+
+// Nearest returns up to k of the indexed features that match filters (which
+// may be nil) sorted by their distance from c, closest first, and then by ID.
+// Features that contain c have a distance of 0. Features are visited in order
+// of the distance to their bounding boxes (see geometry.MinDistanceToRect) and
+// the search stops as soon as no remaining bounding box could hold a feature
+// closer than the k closest found so far.
+
+func (idx *Index) Nearest(c geom.Coord, k int, filters *QueryFilters) ([]*NearestResult, error) {
+
+	results := make([]*NearestResult, 0)
+
+	if k < 1 {
+		return results, nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	seen := make(map[string]bool)
+
+	var search_err error
+
+	dist := func(r geom.Rect) float64 {
+		return geometry.MinDistanceToRect(c, r)
+	}
+
+	visit := func(v interface{}, d float64) bool {
+
+		if len(results) == k && d > results[k-1].Distance {
+			return false
+		}
+
+		id := v.(string)
+
+		if seen[id] {
+			return true
+		}
+
+		seen[id] = true
+		record := idx.features[id]
+
+		ok, err := matchesQueryFilters(record.spr, filters)
+
+		if err != nil {
+			search_err = err
+			return false
+		}
+
+		if !ok {
+			return true
+		}
+
+		rsp, err := geometry.DistanceToFeature(record.feature, c)
+
+		if err != nil {
+			search_err = err
+			return false
+		}
+
+		if rsp.Index == -1 {
+			return true
+		}
+
+		r := &NearestResult{
+			SPR:      record.spr,
+			Distance: rsp.Distance,
+			Coord:    rsp.Coord,
+		}
+
+		results = append(results, r)
+
+		sort.Slice(results, func(i, j int) bool {
+
+			if results[i].Distance != results[j].Distance {
+				return results[i].Distance < results[j].Distance
+			}
+
+			return results[i].SPR.Id() < results[j].SPR.Id()
+		})
+
+		if len(results) > k {
+			results = results[:k]
+		}
+
+		return true
+	}
+
+	idx.tree.SearchNearest(dist, visit)
+
+	if search_err != nil {
+		return nil, search_err
+	}
+
+	return results, nil
+}
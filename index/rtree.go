@@ -0,0 +1,372 @@
+package index
+
+import (
+	"container/heap"
+	"github.com/skelterjohn/geom"
+	"math"
+)
+
+// The maximum and minimum number of entries in an RTree node
+
+const (
+	RTREE_MAX_ENTRIES int = 16
+	RTREE_MIN_ENTRIES int = 6
+)
+
+// RTree is an R-tree, using Guttman's quadratic split, that stores arbitrary
+// values keyed by rectangle. It is not safe for concurrent use; see Index for
+// a wrapper that is. Rectangles are treated as planar so those that cross the
+// antimeridian should be split before they are inserted.
+
+type RTree struct {
+	root *rtreeNode
+	size int
+}
+
+type rtreeNode struct {
+	leaf    bool
+	entries []*rtreeEntry
+}
+
+type rtreeEntry struct {
+	rect  geom.Rect
+	child *rtreeNode
+	value interface{}
+}
+
+func NewRTree() *RTree {
+
+	t := &RTree{
+		root: &rtreeNode{leaf: true},
+	}
+
+	return t
+}
+
+// Len returns the number of values in the tree.
+
+func (t *RTree) Len() int {
+	return t.size
+}
+
+func (t *RTree) Insert(r geom.Rect, value interface{}) {
+
+	e := &rtreeEntry{
+		rect:  r,
+		value: value,
+	}
+
+	sibling := t.insert(t.root, e)
+
+	if sibling != nil {
+
+		root := &rtreeNode{
+			leaf: false,
+			entries: []*rtreeEntry{
+				&rtreeEntry{rect: nodeBounds(t.root), child: t.root},
+				&rtreeEntry{rect: nodeBounds(sibling), child: sibling},
+			},
+		}
+
+		t.root = root
+	}
+
+	t.size += 1
+}
+
+// SearchCoord returns the values whose rectangles contain c.
+
+func (t *RTree) SearchCoord(c geom.Coord) []interface{} {
+
+	r := geom.Rect{Min: c, Max: c}
+	return t.Search(r)
+}
+
+// Search returns the values whose rectangles intersect r.
+
+func (t *RTree) Search(r geom.Rect) []interface{} {
+
+	results := make([]interface{}, 0)
+	t.search(t.root, r, &results)
+
+	return results
+}
+
+func (t *RTree) search(n *rtreeNode, r geom.Rect, results *[]interface{}) {
+
+	for _, e := range n.entries {
+
+		if !rectsOverlap(e.rect, r) {
+			continue
+		}
+
+		if n.leaf {
+			*results = append(*results, e.value)
+		} else {
+			t.search(e.child, r, results)
+		}
+	}
+}
+
+// SearchNearest calls visit with each value in the tree in order of the
+// distance of its rectangle, as measured by dist, until visit returns false.
+// dist must not return a smaller distance for a rectangle than for any larger
+// rectangle that contains it.
+
+func (t *RTree) SearchNearest(dist func(geom.Rect) float64, visit func(value interface{}, d float64) bool) {
+
+	queue := &rtreeQueue{}
+
+	for _, e := range t.root.entries {
+		heap.Push(queue, &rtreeQueueItem{entry: e, leaf: t.root.leaf, distance: dist(e.rect)})
+	}
+
+	for queue.Len() > 0 {
+
+		item := heap.Pop(queue).(*rtreeQueueItem)
+
+		if item.leaf {
+
+			if !visit(item.entry.value, item.distance) {
+				return
+			}
+
+			continue
+		}
+
+		n := item.entry.child
+
+		for _, e := range n.entries {
+			heap.Push(queue, &rtreeQueueItem{entry: e, leaf: n.leaf, distance: dist(e.rect)})
+		}
+	}
+}
+
+// insert adds e to the subtree rooted at n and returns the new sibling of n if
+// n had to be split.
+
+func (t *RTree) insert(n *rtreeNode, e *rtreeEntry) *rtreeNode {
+
+	if n.leaf {
+		n.entries = append(n.entries, e)
+	} else {
+
+		best := chooseSubtree(n, e.rect)
+		sibling := t.insert(best.child, e)
+
+		best.rect = nodeBounds(best.child)
+
+		if sibling != nil {
+			n.entries = append(n.entries, &rtreeEntry{rect: nodeBounds(sibling), child: sibling})
+		}
+	}
+
+	if len(n.entries) > RTREE_MAX_ENTRIES {
+		return splitNode(n)
+	}
+
+	return nil
+}
+
+// chooseSubtree returns the entry of n whose rectangle needs the least
+// enlargement to include r, preferring smaller rectangles in case of a tie.
+
+func chooseSubtree(n *rtreeNode, r geom.Rect) *rtreeEntry {
+
+	var best *rtreeEntry
+
+	best_enlargement := 0.0
+	best_area := 0.0
+
+	for _, e := range n.entries {
+
+		area := rectArea(e.rect)
+		enlargement := rectArea(unionRects(e.rect, r)) - area
+
+		if best == nil || enlargement < best_enlargement || (enlargement == best_enlargement && area < best_area) {
+			best = e
+			best_enlargement = enlargement
+			best_area = area
+		}
+	}
+
+	return best
+}
+
+// splitNode divides the entries of n in two, using Guttman's quadratic
+// algorithm, leaving one group in n and returning a new node with the other.
+
+func splitNode(n *rtreeNode) *rtreeNode {
+
+	entries := n.entries
+
+	// pick the two entries that would waste the most space if they were
+	// in the same group
+
+	seed_a := 0
+	seed_b := 1
+	worst := math.Inf(-1)
+
+	for i := 0; i < len(entries); i++ {
+
+		for j := i + 1; j < len(entries); j++ {
+
+			d := rectArea(unionRects(entries[i].rect, entries[j].rect)) - rectArea(entries[i].rect) - rectArea(entries[j].rect)
+
+			if d > worst {
+				seed_a = i
+				seed_b = j
+				worst = d
+			}
+		}
+	}
+
+	group_a := []*rtreeEntry{entries[seed_a]}
+	group_b := []*rtreeEntry{entries[seed_b]}
+
+	bounds_a := entries[seed_a].rect
+	bounds_b := entries[seed_b].rect
+
+	remaining := make([]*rtreeEntry, 0)
+
+	for i, e := range entries {
+
+		if i != seed_a && i != seed_b {
+			remaining = append(remaining, e)
+		}
+	}
+
+	for len(remaining) > 0 {
+
+		// make sure each group ends up with the minimum number of entries
+
+		if len(group_a)+len(remaining) <= RTREE_MIN_ENTRIES {
+			group_a = append(group_a, remaining...)
+			break
+		}
+
+		if len(group_b)+len(remaining) <= RTREE_MIN_ENTRIES {
+			group_b = append(group_b, remaining...)
+			break
+		}
+
+		// assign the entry with the strongest preference for one group
+		// over the other next
+
+		idx := 0
+		max_diff := -1.0
+
+		for i, e := range remaining {
+
+			d_a := rectArea(unionRects(bounds_a, e.rect)) - rectArea(bounds_a)
+			d_b := rectArea(unionRects(bounds_b, e.rect)) - rectArea(bounds_b)
+
+			diff := math.Abs(d_a - d_b)
+
+			if diff > max_diff {
+				idx = i
+				max_diff = diff
+			}
+		}
+
+		e := remaining[idx]
+		remaining = append(remaining[0:idx], remaining[idx+1:]...)
+
+		d_a := rectArea(unionRects(bounds_a, e.rect)) - rectArea(bounds_a)
+		d_b := rectArea(unionRects(bounds_b, e.rect)) - rectArea(bounds_b)
+
+		to_a := d_a < d_b
+
+		if d_a == d_b {
+
+			area_a := rectArea(bounds_a)
+			area_b := rectArea(bounds_b)
+
+			to_a = area_a < area_b || (area_a == area_b && len(group_a) <= len(group_b))
+		}
+
+		if to_a {
+			group_a = append(group_a, e)
+			bounds_a = unionRects(bounds_a, e.rect)
+		} else {
+			group_b = append(group_b, e)
+			bounds_b = unionRects(bounds_b, e.rect)
+		}
+	}
+
+	n.entries = group_a
+
+	sibling := &rtreeNode{
+		leaf:    n.leaf,
+		entries: group_b,
+	}
+
+	return sibling
+}
+
+func nodeBounds(n *rtreeNode) geom.Rect {
+
+	r := n.entries[0].rect
+
+	for _, e := range n.entries[1:] {
+		r = unionRects(r, e.rect)
+	}
+
+	return r
+}
+
+func unionRects(a geom.Rect, b geom.Rect) geom.Rect {
+
+	r := geom.Rect{
+		Min: geom.Coord{X: math.Min(a.Min.X, b.Min.X), Y: math.Min(a.Min.Y, b.Min.Y)},
+		Max: geom.Coord{X: math.Max(a.Max.X, b.Max.X), Y: math.Max(a.Max.Y, b.Max.Y)},
+	}
+
+	return r
+}
+
+func rectArea(r geom.Rect) float64 {
+	return (r.Max.X - r.Min.X) * (r.Max.Y - r.Min.Y)
+}
+
+func rectsOverlap(a geom.Rect, b geom.Rect) bool {
+	return a.Min.X <= b.Max.X && a.Max.X >= b.Min.X && a.Min.Y <= b.Max.Y && a.Max.Y >= b.Min.Y
+}
+
+// rtreeQueue is a priority queue, closest first, of the entries waiting to be
+// visited by SearchNearest.
+
+type rtreeQueue []*rtreeQueueItem
+
+type rtreeQueueItem struct {
+	entry    *rtreeEntry
+	leaf     bool
+	distance float64
+}
+
+func (q rtreeQueue) Len() int {
+	return len(q)
+}
+
+func (q rtreeQueue) Less(i, j int) bool {
+	return q[i].distance < q[j].distance
+}
+
+func (q rtreeQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *rtreeQueue) Push(x interface{}) {
+	*q = append(*q, x.(*rtreeQueueItem))
+}
+
+func (q *rtreeQueue) Pop() interface{} {
+
+	old := *q
+	count := len(old)
+
+	item := old[count-1]
+	*q = old[:count-1]
+
+	return item
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/whosonfirst/go-whosonfirst-flags"
 	"github.com/whosonfirst/go-whosonfirst-flags/existential"
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/geometry"
 	"github.com/whosonfirst/go-whosonfirst-geojson-v2/utils"
 	"github.com/whosonfirst/go-whosonfirst-placetypes"
 	"strings"
@@ -95,6 +96,16 @@ func Centroid(f geojson.Feature) (geojson.Centroid, error) {
 		return NewWOFCentroid(lat.Float(), lon.Float(), "geom")
 	}
 
+	// none of the usual properties are present (which is common for
+	// features that are being imported or edited) so derive a label
+	// point from the geometry itself before giving up on it entirely
+
+	c, err := geometry.LabelCentroidForFeature(f)
+
+	if err == nil {
+		return c, nil
+	}
+
 	return NewWOFCentroid(0.0, 0.0, "nullisland")
 }
 
@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/feature"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/geometry"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/utils"
+	"math"
+	"testing"
+)
+
+func loadAntimeridianFixture(t *testing.T, path string) geojson.Feature {
+
+	f, err := feature.LoadFeatureFromFile(path)
+
+	if err != nil {
+		t.Fatalf("Failed to load %s, %v", path, err)
+	}
+
+	return f
+}
+
+func testContains(t *testing.T, f geojson.Feature, coords map[geom.Coord]bool) {
+
+	for c, expected := range coords {
+
+		ok, err := f.ContainsCoord(c)
+
+		if err != nil {
+			t.Fatalf("Failed to test %v, %v", c, err)
+		}
+
+		if ok != expected {
+			t.Fatalf("Expected ContainsCoord(%v) to be %t for %s", c, expected, f.Id())
+		}
+	}
+}
+
+func TestAntimeridianMultiPolygon(t *testing.T) {
+
+	f := loadAntimeridianFixture(t, "../fixtures/antimeridian-multipolygon.geojson")
+
+	bboxes, err := f.BoundingBoxes()
+
+	if err != nil {
+		t.Fatalf("Failed to derive bounding boxes, %v", err)
+	}
+
+	mbr := bboxes.MBR()
+
+	if mbr.Min.X != 177.0 || mbr.Max.X != -178.0 || mbr.Min.Y != -18.0 || mbr.Max.Y != -16.0 {
+		t.Fatalf("Unexpected MBR %v", mbr)
+	}
+
+	if !utils.RectCrossesAntimeridian(mbr) {
+		t.Fatalf("Expected MBR to cross the antimeridian")
+	}
+
+	if !utils.RectContainsCoord(mbr, geom.Coord{X: -179.0, Y: -17.0}) || utils.RectContainsCoord(mbr, geom.Coord{X: 0.0, Y: -17.0}) {
+		t.Fatalf("Unexpected RectContainsCoord results for %v", mbr)
+	}
+
+	testContains(t, f, map[geom.Coord]bool{
+		geom.Coord{X: 179.0, Y: -17.0}:  true,
+		geom.Coord{X: -179.0, Y: -17.0}: true,
+		geom.Coord{X: 0.0, Y: -17.0}:    false,
+		geom.Coord{X: -177.0, Y: -17.0}: false,
+	})
+
+	s, err := f.SPR()
+
+	if err != nil {
+		t.Fatalf("Failed to create SPR, %v", err)
+	}
+
+	if s.MinLongitude() != 177.0 || s.MaxLongitude() != -178.0 || s.MaxLatitude() != -16.0 {
+		t.Fatalf("Unexpected SPR extents %f, %f, %f", s.MinLongitude(), s.MaxLongitude(), s.MaxLatitude())
+	}
+
+	if s.Longitude() != 179.5 || s.Latitude() != -17.0 {
+		t.Fatalf("Unexpected SPR center %f, %f", s.Latitude(), s.Longitude())
+	}
+
+	c, err := geometry.CentroidForFeature(f)
+
+	if err != nil {
+		t.Fatalf("Failed to derive centroid, %v", err)
+	}
+
+	// 6 square degrees centered on 178.5 and 4 centered on -179 (or 181)
+
+	if math.Abs(c.Coord().X-179.5) > 1e-9 {
+		t.Fatalf("Unexpected centroid %v", c.Coord())
+	}
+}
+
+func TestAntimeridianPolygon(t *testing.T) {
+
+	f := loadAntimeridianFixture(t, "../fixtures/antimeridian-polygon.geojson")
+
+	bboxes, err := f.BoundingBoxes()
+
+	if err != nil {
+		t.Fatalf("Failed to derive bounding boxes, %v", err)
+	}
+
+	mbr := bboxes.MBR()
+
+	if mbr.Min.X != 170.0 || mbr.Max.X != -170.0 || mbr.Min.Y != 60.0 || mbr.Max.Y != 70.0 {
+		t.Fatalf("Unexpected MBR %v", mbr)
+	}
+
+	testContains(t, f, map[geom.Coord]bool{
+		geom.Coord{X: 175.0, Y: 62.0}:  true,
+		geom.Coord{X: -175.0, Y: 62.0}: true,
+		geom.Coord{X: 180.0, Y: 65.0}:  false,
+		geom.Coord{X: -179.0, Y: 65.0}: false,
+		geom.Coord{X: 0.0, Y: 65.0}:    false,
+		geom.Coord{X: 160.0, Y: 65.0}:  false,
+	})
+
+	area, _ := geometry.PlanarAreaForFeature(f)
+
+	if area != 192.0 {
+		t.Fatalf("Unexpected planar area %f", area)
+	}
+
+	lbl, err := geometry.LabelCentroidForFeature(f)
+
+	if err != nil {
+		t.Fatalf("Failed to derive label centroid, %v", err)
+	}
+
+	ok, _ := f.ContainsCoord(lbl.Coord())
+
+	if !ok {
+		t.Fatalf("Expected label centroid %v to be inside the polygon", lbl.Coord())
+	}
+}
+
+func TestAntimeridianLineString(t *testing.T) {
+
+	body := []byte(`{"type":"Feature","properties":{},"geometry":{"type":"LineString","coordinates":[[179.5,0],[-179.5,0]]}}`)
+
+	f, err := feature.LoadFeature(body)
+
+	if err != nil {
+		t.Fatalf("Failed to load feature, %v", err)
+	}
+
+	bboxes, _ := f.BoundingBoxes()
+	mbr := bboxes.MBR()
+
+	if mbr.Min.X != 179.5 || mbr.Max.X != -179.5 {
+		t.Fatalf("Unexpected MBR %v", mbr)
+	}
+
+	testContains(t, f, map[geom.Coord]bool{
+		geom.Coord{X: 180.0, Y: 0.0}:  true,
+		geom.Coord{X: -180.0, Y: 0.0}: true,
+		geom.Coord{X: 0.0, Y: 0.0}:    false,
+	})
+
+	length, _ := geometry.GeodesicLengthForFeature(f)
+
+	if math.Abs(length-111319.49) > 1.0 {
+		t.Fatalf("Unexpected length %f", length)
+	}
+}
@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/feature"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/geometry"
+	"math"
+	"testing"
+)
+
+func closeTo(a float64, b float64, tolerance float64) bool {
+	return math.Abs(a-b) <= math.Abs(b)*tolerance
+}
+
+func TestVincentyDistance(t *testing.T) {
+
+	d := geometry.VincentyDistance(geom.Coord{X: 0, Y: 0}, geom.Coord{X: 1, Y: 0})
+
+	if !closeTo(d, 111319.491, 1e-6) {
+		t.Fatalf("Unexpected distance for 1 degree of longitude at the equator, %f", d)
+	}
+
+	d = geometry.VincentyDistance(geom.Coord{X: 0, Y: 0}, geom.Coord{X: 0, Y: 1})
+
+	if !closeTo(d, 110574.389, 1e-6) {
+		t.Fatalf("Unexpected distance for 1 degree of latitude, %f", d)
+	}
+}
+
+func TestAreaForFeature(t *testing.T) {
+
+	// a one degree square, at the equator, with a hole in the middle; the
+	// expected values for the square come from GeographicLib and those for
+	// the hole from numerically integrating along its geodesic edges (which
+	// reproduces GeographicLib's values for the square to the millimetre)
+
+	body := []byte(`{"type":"Feature","properties":{},"geometry":{"type":"Polygon","coordinates":[
+		[[0,0],[1,0],[1,1],[0,1],[0,0]],
+		[[0.25,0.25],[0.25,0.75],[0.75,0.75],[0.75,0.25],[0.25,0.25]]
+	]}}`)
+
+	f, err := feature.LoadFeature(body)
+
+	if err != nil {
+		t.Fatalf("Failed to load feature, %v", err)
+	}
+
+	planar, err := geometry.PlanarAreaForFeature(f)
+
+	if err != nil || planar != 0.75 {
+		t.Fatalf("Unexpected planar area %f (%v)", planar, err)
+	}
+
+	area, err := geometry.GeodesicAreaForFeature(f)
+
+	if err != nil {
+		t.Fatalf("Failed to calculate geodesic area, %v", err)
+	}
+
+	expected := 12308778361.469 - 3077164136.655
+
+	if !closeTo(area, expected, 1e-6) {
+		t.Fatalf("Unexpected geodesic area %f, expected %f", area, expected)
+	}
+
+	perimeter, err := geometry.GeodesicPerimeterForFeature(f)
+
+	if err != nil {
+		t.Fatalf("Failed to calculate perimeter, %v", err)
+	}
+
+	expected = 443770.917 + 221888.595
+
+	if !closeTo(perimeter, expected, 1e-6) {
+		t.Fatalf("Unexpected perimeter %f, expected %f", perimeter, expected)
+	}
+}
+
+func TestLengthForFeature(t *testing.T) {
+
+	body := []byte(`{"type":"Feature","properties":{},"geometry":{"type":"MultiLineString","coordinates":[[[0,0],[1,0]],[[0,0],[0,1]]]}}`)
+
+	f, err := feature.LoadFeature(body)
+
+	if err != nil {
+		t.Fatalf("Failed to load feature, %v", err)
+	}
+
+	length, err := geometry.GeodesicLengthForFeature(f)
+
+	if err != nil {
+		t.Fatalf("Failed to calculate length, %v", err)
+	}
+
+	if !closeTo(length, 111319.491+110574.389, 1e-6) {
+		t.Fatalf("Unexpected length %f", length)
+	}
+
+	area, _ := geometry.GeodesicAreaForFeature(f)
+
+	if area != 0.0 {
+		t.Fatalf("Expected lines to have no area, got %f", area)
+	}
+}
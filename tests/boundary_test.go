@@ -0,0 +1,235 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/geometry"
+	"math/rand"
+	"testing"
+)
+
+func testLocateCoord(t *testing.T, label string, p geojson.Polygon, coords map[geom.Coord]int) {
+
+	for c, expected := range coords {
+
+		loc := geometry.LocateCoord(p, c)
+
+		if loc != expected {
+			t.Fatalf("Expected %v to be at location %d of %s, got %d", c, expected, label, loc)
+		}
+
+		loc = geometry.LocateCoord(geometry.NewPreparedPolygon(p), c)
+
+		if loc != expected {
+			t.Fatalf("Expected %v to be at location %d of prepared %s, got %d", c, expected, label, loc)
+		}
+	}
+}
+
+func TestLocateCoord(t *testing.T) {
+
+	square := map[geom.Coord]int{
+		{X: 1, Y: 1}:         geometry.LOCATION_INTERIOR,
+		{X: 0, Y: 0}:         geometry.LOCATION_BOUNDARY,
+		{X: 10, Y: 10}:       geometry.LOCATION_BOUNDARY,
+		{X: 0, Y: 10}:        geometry.LOCATION_BOUNDARY,
+		{X: 5, Y: 0}:         geometry.LOCATION_BOUNDARY,
+		{X: 10, Y: 5}:        geometry.LOCATION_BOUNDARY,
+		{X: 5, Y: 10}:        geometry.LOCATION_BOUNDARY,
+		{X: 0, Y: 5}:         geometry.LOCATION_BOUNDARY,
+		{X: 2, Y: 2}:         geometry.LOCATION_BOUNDARY,
+		{X: 8, Y: 5}:         geometry.LOCATION_BOUNDARY,
+		{X: 5, Y: 8}:         geometry.LOCATION_BOUNDARY,
+		{X: 5, Y: 5}:         geometry.LOCATION_EXTERIOR,
+		{X: 11, Y: 5}:        geometry.LOCATION_EXTERIOR,
+		{X: -1, Y: 0}:        geometry.LOCATION_EXTERIOR,
+		{X: 11, Y: 10}:       geometry.LOCATION_EXTERIOR,
+		{X: 5, Y: 10.000001}: geometry.LOCATION_EXTERIOR,
+		{X: 9.999999, Y: 5}:  geometry.LOCATION_INTERIOR,
+	}
+
+	ccw := `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[2,2],[2,8],[8,8],[8,2],[2,2]]]}`
+	cw := `{"type":"Polygon","coordinates":[[[0,0],[0,10],[10,10],[10,0],[0,0]],[[2,2],[8,2],[8,8],[2,8],[2,2]]]}`
+
+	testLocateCoord(t, "counter-clockwise square", relatePolygons(t, ccw)[0], square)
+	testLocateCoord(t, "clockwise square", relatePolygons(t, cw)[0], square)
+
+	triangle := map[geom.Coord]int{
+		{X: 2.5, Y: 7.5}:   geometry.LOCATION_BOUNDARY,
+		{X: 5, Y: 5}:       geometry.LOCATION_BOUNDARY,
+		{X: 0.125, Y: 0}:   geometry.LOCATION_BOUNDARY,
+		{X: 2.5, Y: 7.25}:  geometry.LOCATION_INTERIOR,
+		{X: 2.5, Y: 7.75}:  geometry.LOCATION_EXTERIOR,
+		{X: -2.5, Y: 12.5}: geometry.LOCATION_EXTERIOR,
+	}
+
+	testLocateCoord(t, "triangle", relatePolygons(t, `{"type":"Polygon","coordinates":[[[0,0],[10,0],[0,10],[0,0]]]}`)[0], triangle)
+
+	antimeridian := map[geom.Coord]int{
+		{X: 175, Y: 65}:  geometry.LOCATION_INTERIOR,
+		{X: 180, Y: 62}:  geometry.LOCATION_INTERIOR,
+		{X: -180, Y: 62}: geometry.LOCATION_INTERIOR,
+		{X: 170, Y: 65}:  geometry.LOCATION_BOUNDARY,
+		{X: -170, Y: 65}: geometry.LOCATION_BOUNDARY,
+		{X: 180, Y: 70}:  geometry.LOCATION_BOUNDARY,
+		{X: -178, Y: 65}: geometry.LOCATION_BOUNDARY,
+		{X: 178, Y: 64}:  geometry.LOCATION_BOUNDARY,
+		{X: 180, Y: 65}:  geometry.LOCATION_EXTERIOR,
+		{X: 160, Y: 65}:  geometry.LOCATION_EXTERIOR,
+	}
+
+	polys, err := loadAntimeridianFixture(t, "../fixtures/antimeridian-polygon.geojson").Polygons()
+
+	if err != nil {
+		t.Fatalf("Failed to derive polygons, %v", err)
+	}
+
+	testLocateCoord(t, "antimeridian polygon", polys[0], antimeridian)
+}
+
+// TestLocateCoordNeighbours checks that a coordinate near the shared edge of
+// two polygons is either on the boundary of both or in the interior of
+// exactly one of them, even though the edge runs in opposite directions.
+
+func TestLocateCoordNeighbours(t *testing.T) {
+
+	// coordinates along the first edge are exactly representable, those along
+	// the second are not
+
+	for _, end := range []geom.Coord{{X: 8, Y: 4}, {X: 7.1, Y: 3.3}} {
+
+		a := relatePolygons(t, fmt.Sprintf(`{"type":"Polygon","coordinates":[[[0,0],[%f,%f],[0,10],[0,0]]]}`, end.X, end.Y))[0]
+		b := relatePolygons(t, fmt.Sprintf(`{"type":"Polygon","coordinates":[[[0,0],[10,0],[%f,%f],[0,0]]]}`, end.X, end.Y))[0]
+
+		r := rand.New(rand.NewSource(23))
+
+		coords := []geom.Coord{{X: 0, Y: 0}, end}
+
+		for i := 0; i < 10000; i++ {
+
+			f := r.Float64()
+			c := geom.Coord{X: end.X * f, Y: end.Y * f}
+
+			if i%2 == 0 {
+				c.X += (r.Float64() - 0.5) * 1e-12
+			}
+
+			coords = append(coords, c)
+		}
+
+		boundary := 0
+
+		for _, c := range coords {
+
+			loc_a := geometry.LocateCoord(a, c)
+			loc_b := geometry.LocateCoord(b, c)
+
+			if loc_a == geometry.LOCATION_BOUNDARY || loc_b == geometry.LOCATION_BOUNDARY {
+
+				if loc_a != loc_b {
+					t.Fatalf("Expected %v to be on the boundary of both polygons, got %d and %d", c, loc_a, loc_b)
+				}
+
+				boundary += 1
+				continue
+			}
+
+			if (loc_a == geometry.LOCATION_INTERIOR) == (loc_b == geometry.LOCATION_INTERIOR) {
+				t.Fatalf("Expected %v to be in exactly one polygon, got %d and %d", c, loc_a, loc_b)
+			}
+		}
+
+		if end.X == 8 && boundary < 5000 {
+			t.Fatalf("Expected at least half of the coordinates to be on the shared boundary, got %d", boundary)
+		}
+	}
+}
+
+func TestPolygonContainsCoordWithBoundary(t *testing.T) {
+
+	p := relatePolygons(t, relateSquare(0, 0, 10, 10))[0]
+
+	tests := []struct {
+		Coord     geom.Coord
+		Mode      int
+		Contained bool
+	}{
+		{geom.Coord{X: 0, Y: 5}, geometry.BOUNDARY_INCLUSIVE, true},
+		{geom.Coord{X: 0, Y: 5}, geometry.BOUNDARY_EXCLUSIVE, false},
+		{geom.Coord{X: 0, Y: 5}, geometry.BOUNDARY_SEPARATE, false},
+		{geom.Coord{X: 10, Y: 10}, geometry.BOUNDARY_INCLUSIVE, true},
+		{geom.Coord{X: 10, Y: 10}, geometry.BOUNDARY_EXCLUSIVE, false},
+		{geom.Coord{X: 5, Y: 5}, geometry.BOUNDARY_EXCLUSIVE, true},
+		{geom.Coord{X: 15, Y: 5}, geometry.BOUNDARY_INCLUSIVE, false},
+	}
+
+	for _, test := range tests {
+
+		if geometry.PolygonContainsCoordWithBoundary(p, test.Coord, test.Mode) != test.Contained {
+			t.Fatalf("Expected %v with boundary mode %d to return %t", test.Coord, test.Mode, test.Contained)
+		}
+	}
+}
+
+func TestFeatureContainsCoordWithBoundary(t *testing.T) {
+
+	f := relateFeature(t, `{"type":"MultiPolygon","coordinates":[[[[0,0],[10,0],[10,10],[0,10],[0,0]]],[[[10,0],[20,0],[20,10],[10,10],[10,0]]]]}`)
+
+	opts := geometry.DefaultContainsCoordOptions()
+	opts.Boundary = geometry.BOUNDARY_SEPARATE
+
+	rsp, err := geometry.FeatureContainsCoordWithOptions(f, geom.Coord{X: 20, Y: 5}, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to test coordinate, %v", err)
+	}
+
+	if rsp.Contains || !rsp.Boundary || rsp.Index != 1 {
+		t.Fatalf("Expected coordinate to be on the boundary of the second polygon, got %v", rsp)
+	}
+
+	rsp, err = geometry.FeatureContainsCoordWithOptions(f, geom.Coord{X: 15, Y: 5}, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to test coordinate, %v", err)
+	}
+
+	if !rsp.Contains || rsp.Boundary || rsp.Index != 1 {
+		t.Fatalf("Expected coordinate to be in the second polygon, got %v", rsp)
+	}
+
+	opts.Boundary = geometry.BOUNDARY_INCLUSIVE
+
+	rsp, err = geometry.FeatureContainsCoordWithOptions(f, geom.Coord{X: 10, Y: 5}, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to test coordinate, %v", err)
+	}
+
+	if !rsp.Contains || rsp.Index != 0 {
+		t.Fatalf("Expected coordinate to be contained by the first polygon, got %v", rsp)
+	}
+
+	opts.Boundary = geometry.BOUNDARY_EXCLUSIVE
+
+	rsp, err = geometry.FeatureContainsCoordWithOptions(f, geom.Coord{X: 10, Y: 5}, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to test coordinate, %v", err)
+	}
+
+	if rsp.Contains {
+		t.Fatalf("Expected coordinate not to be contained, got %v", rsp)
+	}
+
+	loc, err := geometry.LocateCoordForFeature(f, geom.Coord{X: 10, Y: 5})
+
+	if err != nil {
+		t.Fatalf("Failed to locate coordinate, %v", err)
+	}
+
+	if loc != geometry.LOCATION_BOUNDARY {
+		t.Fatalf("Expected coordinate to be on the boundary, got %d", loc)
+	}
+}
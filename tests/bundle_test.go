@@ -0,0 +1,200 @@
+package tests
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/bundle"
+	"github.com/whosonfirst/go-whosonfirst-uri"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func bundleEntries(t *testing.T) map[string][]byte {
+
+	entries := make(map[string][]byte)
+
+	for _, fname := range []string{"101851199.geojson", "101851199-alt-quattroshapes.geojson"} {
+
+		body, err := ioutil.ReadFile(filepath.Join("../fixtures", fname))
+
+		if err != nil {
+			t.Fatalf("Failed to read %s, %v", fname, err)
+		}
+
+		entries["whosonfirst-data-admin-fr/data/101/851/199/"+fname] = body
+	}
+
+	entries["whosonfirst-data-admin-fr/README.md"] = []byte("not a feature")
+	return entries
+}
+
+func writeTarGzBundle(t *testing.T, path string) {
+
+	fh, err := os.Create(path)
+
+	if err != nil {
+		t.Fatalf("Failed to create %s, %v", path, err)
+	}
+
+	defer fh.Close()
+
+	gz := gzip.NewWriter(fh)
+	tw := tar.NewWriter(gz)
+
+	for name, body := range bundleEntries(t) {
+
+		hdr := &tar.Header{
+			Name: name,
+			Mode: 0644,
+			Size: int64(len(body)),
+		}
+
+		err := tw.WriteHeader(hdr)
+
+		if err != nil {
+			t.Fatalf("Failed to write header for %s, %v", name, err)
+		}
+
+		_, err = tw.Write(body)
+
+		if err != nil {
+			t.Fatalf("Failed to write %s, %v", name, err)
+		}
+	}
+
+	err = tw.Close()
+
+	if err != nil {
+		t.Fatalf("Failed to close tar writer, %v", err)
+	}
+
+	err = gz.Close()
+
+	if err != nil {
+		t.Fatalf("Failed to close gzip writer, %v", err)
+	}
+}
+
+func writeZipBundle(t *testing.T, path string) {
+
+	fh, err := os.Create(path)
+
+	if err != nil {
+		t.Fatalf("Failed to create %s, %v", path, err)
+	}
+
+	defer fh.Close()
+
+	zw := zip.NewWriter(fh)
+
+	for name, body := range bundleEntries(t) {
+
+		wr, err := zw.Create(name)
+
+		if err != nil {
+			t.Fatalf("Failed to create %s, %v", name, err)
+		}
+
+		_, err = wr.Write(body)
+
+		if err != nil {
+			t.Fatalf("Failed to write %s, %v", name, err)
+		}
+	}
+
+	err = zw.Close()
+
+	if err != nil {
+		t.Fatalf("Failed to close zip writer, %v", err)
+	}
+}
+
+func TestBundle(t *testing.T) {
+
+	root, err := ioutil.TempDir("", "bundle")
+
+	if err != nil {
+		t.Fatalf("Failed to create temporary directory, %v", err)
+	}
+
+	defer os.RemoveAll(root)
+
+	tar_path := filepath.Join(root, "bundle.tar.gz")
+	zip_path := filepath.Join(root, "bundle.zip")
+
+	writeTarGzBundle(t, tar_path)
+	writeZipBundle(t, zip_path)
+
+	ctx := context.Background()
+
+	formats := map[string]string{
+		tar_path: bundle.FORMAT_TAR,
+		zip_path: bundle.FORMAT_ZIP,
+	}
+
+	for path, format := range formats {
+
+		b, err := bundle.Open(path)
+
+		if err != nil {
+			t.Fatalf("Failed to open %s, %v", path, err)
+		}
+
+		if b.Format() != format {
+			t.Fatalf("Expected %s to be %s but got %s", path, format, b.Format())
+		}
+
+		count := 0
+
+		err = b.Iterate(ctx, func(ctx context.Context, path string, f geojson.Feature) error {
+
+			if f.Id() != "101851199" {
+				t.Fatalf("Unexpected feature %s at %s", f.Id(), path)
+			}
+
+			count += 1
+			return nil
+		})
+
+		if err != nil {
+			t.Fatalf("Failed to iterate %s, %v", path, err)
+		}
+
+		if count != 2 {
+			t.Fatalf("Expected 2 features in %s but got %d", path, count)
+		}
+
+		f, err := b.LoadFeatureById(ctx, 101851199)
+
+		if err != nil {
+			t.Fatalf("Failed to load feature from %s, %v", path, err)
+		}
+
+		if f.Placetype() != "locality" {
+			t.Fatalf("Unexpected placetype '%s' for feature from %s", f.Placetype(), path)
+		}
+
+		alt_args := uri.NewAlternateURIArgs("quattroshapes", "")
+
+		alt, err := b.LoadFeatureById(ctx, 101851199, alt_args)
+
+		if err != nil {
+			t.Fatalf("Failed to load alt feature from %s, %v", path, err)
+		}
+
+		if alt.Placetype() != "alt" {
+			t.Fatalf("Unexpected placetype '%s' for alt feature from %s", alt.Placetype(), path)
+		}
+
+		_, err = b.LoadFeatureById(ctx, 1234)
+
+		if err != bundle.ErrNotFound {
+			t.Fatalf("Expected ErrNotFound for missing feature in %s, got %v", path, err)
+		}
+	}
+}
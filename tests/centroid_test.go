@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/feature"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/geometry"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/properties/whosonfirst"
+	"math"
+	"testing"
+)
+
+// a "U" shape whose centroid falls in the notch, outside the polygon
+
+var u_shape = []byte(`{"type":"Feature","properties":{},"geometry":{"type":"Polygon","coordinates":[[[0,0],[3,0],[3,3],[2,3],[2,1],[1,1],[1,3],[0,3],[0,0]]]}}`)
+
+func TestCentroidForFeature(t *testing.T) {
+
+	f, err := feature.LoadFeature(u_shape)
+
+	if err != nil {
+		t.Fatalf("Failed to load feature, %v", err)
+	}
+
+	c, err := geometry.CentroidForFeature(f)
+
+	if err != nil {
+		t.Fatalf("Failed to derive centroid, %v", err)
+	}
+
+	coord := c.Coord()
+
+	// 3 + 2 + 2 = 7 square units; 3 * 0.5 + 2 * 2 + 2 * 2 = 9.5
+
+	if math.Abs(coord.X-1.5) > 1e-9 || math.Abs(coord.Y-9.5/7.0) > 1e-9 {
+		t.Fatalf("Unexpected centroid %v", coord)
+	}
+
+	if c.Source() != geometry.CENTROID_SOURCE_GEOM {
+		t.Fatalf("Unexpected source %s", c.Source())
+	}
+
+	ok, _ := f.ContainsCoord(coord)
+
+	if ok {
+		t.Fatalf("Expected the centroid of a U to be outside of it")
+	}
+
+	lbl, err := geometry.LabelCentroidForFeature(f)
+
+	if err != nil {
+		t.Fatalf("Failed to derive label centroid, %v", err)
+	}
+
+	ok, _ = f.ContainsCoord(lbl.Coord())
+
+	if !ok {
+		t.Fatalf("Expected label centroid %v to be inside the polygon", lbl.Coord())
+	}
+
+	if lbl.Source() != geometry.CENTROID_SOURCE_LBL {
+		t.Fatalf("Unexpected source %s", lbl.Source())
+	}
+}
+
+func TestCentroidWithHole(t *testing.T) {
+
+	body := []byte(`{"type":"Feature","properties":{},"geometry":{"type":"Polygon","coordinates":[
+		[[0,0],[4,0],[4,4],[0,4],[0,0]],
+		[[0.5,0.5],[0.5,3.5],[2,3.5],[2,0.5],[0.5,0.5]]
+	]}}`)
+
+	f, err := feature.LoadFeature(body)
+
+	if err != nil {
+		t.Fatalf("Failed to load feature, %v", err)
+	}
+
+	c, _ := geometry.CentroidForFeature(f)
+
+	if c.Coord().X <= 2.0 || math.Abs(c.Coord().Y-2.0) > 1e-9 {
+		t.Fatalf("Expected centroid to move away from the hole, got %v", c.Coord())
+	}
+
+	lbl, _ := geometry.LabelCentroidForFeature(f)
+
+	// the widest part of the polygon is the 2 x 4 strip to the east of the hole
+
+	if math.Abs(lbl.Coord().X-3.0) > 0.01 {
+		t.Fatalf("Unexpected label centroid %v", lbl.Coord())
+	}
+}
+
+func TestWOFCentroidFallback(t *testing.T) {
+
+	f, err := feature.LoadFeature(u_shape)
+
+	if err != nil {
+		t.Fatalf("Failed to load feature, %v", err)
+	}
+
+	c, err := whosonfirst.Centroid(f)
+
+	if err != nil {
+		t.Fatalf("Failed to derive centroid, %v", err)
+	}
+
+	if c.Source() != geometry.CENTROID_SOURCE_LBL {
+		t.Fatalf("Expected a computed centroid, got %s", c.Source())
+	}
+
+	f, err = feature.LoadFeature([]byte(`{"type":"Feature","properties":{},"geometry":null}`))
+
+	if err != nil {
+		t.Fatalf("Failed to load feature, %v", err)
+	}
+
+	c, err = whosonfirst.Centroid(f)
+
+	if err != nil || c.Source() != "nullisland" {
+		t.Fatalf("Expected a feature without a geometry to fall back to nullisland")
+	}
+}
@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/feature"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadCompressedFeatureFromFile(t *testing.T) {
+
+	plain, err := ioutil.ReadFile("../fixtures/101851199.geojson")
+
+	if err != nil {
+		t.Fatalf("Failed to read fixture, %v", err)
+	}
+
+	root, err := ioutil.TempDir("", "compression")
+
+	if err != nil {
+		t.Fatalf("Failed to create temporary directory, %v", err)
+	}
+
+	defer os.RemoveAll(root)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+
+	_, err = gz.Write(plain)
+
+	if err != nil {
+		t.Fatalf("Failed to compress fixture, %v", err)
+	}
+
+	err = gz.Close()
+
+	if err != nil {
+		t.Fatalf("Failed to compress fixture, %v", err)
+	}
+
+	// the second path is gzip-ed data with an uncompressed extension
+	// so that detection has to rely on magic bytes
+
+	gz_paths := []string{
+		filepath.Join(root, "101851199.geojson.gz"),
+		filepath.Join(root, "101851199.geojson"),
+	}
+
+	for _, path := range gz_paths {
+
+		err = ioutil.WriteFile(path, buf.Bytes(), 0644)
+
+		if err != nil {
+			t.Fatalf("Failed to write %s, %v", path, err)
+		}
+	}
+
+	paths := append(gz_paths, "../fixtures/101851199.geojson.bz2")
+
+	for _, path := range paths {
+
+		f, err := feature.LoadFeatureFromFile(path)
+
+		if err != nil {
+			t.Fatalf("Failed to load %s, %v", path, err)
+		}
+
+		if !bytes.Equal(f.Bytes(), plain) {
+			t.Fatalf("Unexpected body for %s", path)
+		}
+
+		_, err = feature.LoadWOFFeatureFromFile(path)
+
+		if err != nil {
+			t.Fatalf("Failed to load %s as WOF feature, %v", path, err)
+		}
+	}
+
+	if feature.StripCompressionExtension(gz_paths[0]) != gz_paths[1] {
+		t.Fatalf("Failed to strip compression extension")
+	}
+}
+
+func TestLoadCompressedFeatureCollectionFromFile(t *testing.T) {
+
+	body := featureCollectionFixture(t)
+
+	root, err := ioutil.TempDir("", "compression")
+
+	if err != nil {
+		t.Fatalf("Failed to create temporary directory, %v", err)
+	}
+
+	defer os.RemoveAll(root)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+
+	_, err = gz.Write([]byte(body))
+
+	if err != nil {
+		t.Fatalf("Failed to compress feature collection, %v", err)
+	}
+
+	err = gz.Close()
+
+	if err != nil {
+		t.Fatalf("Failed to compress feature collection, %v", err)
+	}
+
+	path := filepath.Join(root, "collection.geojson.gz")
+
+	err = ioutil.WriteFile(path, buf.Bytes(), 0644)
+
+	if err != nil {
+		t.Fatalf("Failed to write %s, %v", path, err)
+	}
+
+	c, err := feature.LoadFeatureCollectionFromFile(path)
+
+	if err != nil {
+		t.Fatalf("Failed to load %s, %v", path, err)
+	}
+
+	defer c.Close()
+
+	count := 0
+
+	for {
+
+		_, err := c.Next()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("Failed to load feature %d from %s, %v", count, path, err)
+		}
+
+		count += 1
+	}
+
+	if count != 3 {
+		t.Fatalf("Expected 3 features in %s but got %d", path, count)
+	}
+}
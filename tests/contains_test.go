@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/feature"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/geometry"
+	"math"
+	"testing"
+)
+
+type containsCoordWithOptions interface {
+	ContainsCoordWithOptions(geom.Coord, *geometry.ContainsCoordOptions) (*geometry.ContainsCoordResult, error)
+}
+
+func loadContainsFeature(t *testing.T, body []byte) geojson.Feature {
+
+	f, err := feature.LoadFeature(body)
+
+	if err != nil {
+		t.Fatalf("Failed to load feature, %v", err)
+	}
+
+	return f
+}
+
+func TestHaversineDistance(t *testing.T) {
+
+	d := geometry.HaversineDistance(geom.Coord{X: 0, Y: 0}, geom.Coord{X: 0, Y: 1})
+
+	if math.Abs(d-111195.08) > 1.0 {
+		t.Fatalf("Unexpected distance for 1 degree of latitude, %f", d)
+	}
+}
+
+func TestPointContainsCoord(t *testing.T) {
+
+	f, err := feature.LoadFeatureFromFile("../fixtures/101851199.geojson")
+
+	if err != nil {
+		t.Fatalf("Failed to load fixture, %v", err)
+	}
+
+	polys, err := f.Polygons()
+
+	if err != nil || len(polys) != 0 {
+		t.Fatalf("Expected a point to have no polygons, got %d (%v)", len(polys), err)
+	}
+
+	bboxes, err := f.BoundingBoxes()
+
+	if err != nil {
+		t.Fatalf("Failed to derive bounding boxes, %v", err)
+	}
+
+	mbr := bboxes.MBR()
+
+	if mbr.Min.X != 2.493097 || mbr.Max.Y != 44.344395 {
+		t.Fatalf("Unexpected MBR %v", mbr)
+	}
+
+	ok, err := f.ContainsCoord(geom.Coord{X: 2.493097, Y: 44.344395})
+
+	if err != nil || !ok {
+		t.Fatalf("Expected point to contain itself (%v)", err)
+	}
+
+	// roughly 11 metres north
+
+	c := geom.Coord{X: 2.493097, Y: 44.344495}
+
+	ok, _ = f.ContainsCoord(c)
+
+	if ok {
+		t.Fatalf("Did not expect point to contain %v with the default tolerance", c)
+	}
+
+	opts := &geometry.ContainsCoordOptions{
+		PointTolerance: 20.0,
+	}
+
+	rsp, err := f.(containsCoordWithOptions).ContainsCoordWithOptions(c, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to test coordinate, %v", err)
+	}
+
+	if !rsp.Contains || rsp.Type != "Point" || rsp.Index != 0 || rsp.Distance < 10.0 || rsp.Distance > 12.0 {
+		t.Fatalf("Unexpected result %v", rsp)
+	}
+}
+
+func TestMultiPointContainsCoord(t *testing.T) {
+
+	f := loadContainsFeature(t, []byte(`{"type":"Feature","properties":{},"geometry":{"type":"MultiPoint","coordinates":[[0,0],[10,10]]}}`))
+
+	rsp, err := f.(containsCoordWithOptions).ContainsCoordWithOptions(geom.Coord{X: 10, Y: 10}, nil)
+
+	if err != nil {
+		t.Fatalf("Failed to test coordinate, %v", err)
+	}
+
+	if !rsp.Contains || rsp.Index != 1 {
+		t.Fatalf("Expected second point to match, %v", rsp)
+	}
+
+	// the old "exterior ring" would have contained this
+
+	ok, _ := f.ContainsCoord(geom.Coord{X: 5, Y: 5})
+
+	if ok {
+		t.Fatalf("Did not expect a MultiPoint to contain a coordinate between its points")
+	}
+}
+
+func TestLineStringContainsCoord(t *testing.T) {
+
+	f := loadContainsFeature(t, []byte(`{"type":"Feature","properties":{},"geometry":{"type":"LineString","coordinates":[[0,0],[0.001,0],[0.001,0.001]]}}`))
+
+	// roughly 2.2 metres north of the first segment
+
+	c := geom.Coord{X: 0.0005, Y: 0.00002}
+
+	ok, _ := f.ContainsCoord(c)
+
+	if ok {
+		t.Fatalf("Did not expect line to contain %v with the default tolerance", c)
+	}
+
+	opts := &geometry.ContainsCoordOptions{
+		LineTolerance: 5.0,
+	}
+
+	rsp, err := f.(containsCoordWithOptions).ContainsCoordWithOptions(c, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to test coordinate, %v", err)
+	}
+
+	if !rsp.Contains || rsp.Type != "LineString" || math.Abs(rsp.Distance-2.22) > 0.1 {
+		t.Fatalf("Unexpected result %v", rsp)
+	}
+
+	// inside the triangle the line would have been closed in to
+
+	ok, _ = f.ContainsCoord(geom.Coord{X: 0.0008, Y: 0.0003})
+
+	if ok {
+		t.Fatalf("Did not expect a line to contain a coordinate away from it")
+	}
+}
+
+func TestGeometryCollectionContainsCoord(t *testing.T) {
+
+	f := loadContainsFeature(t, []byte(`{"type":"Feature","properties":{},"geometry":{"type":"GeometryCollection","geometries":[
+		{"type":"Point","coordinates":[1,1]},
+		{"type":"Polygon","coordinates":[[[0,0],[0,2],[2,2],[2,0],[0,0]]]}
+	]}}`))
+
+	rsp, err := f.(containsCoordWithOptions).ContainsCoordWithOptions(geom.Coord{X: 1, Y: 1}, nil)
+
+	if err != nil {
+		t.Fatalf("Failed to test coordinate, %v", err)
+	}
+
+	if !rsp.Contains || rsp.Type != "Polygon" || rsp.Index != 1 {
+		t.Fatalf("Expected the polygon to match, %v", rsp)
+	}
+}
@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/geometry"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestDistanceToFeature(t *testing.T) {
+
+	tests := []struct {
+		Label    string
+		Geometry string
+		Coord    geom.Coord
+		Closest  geom.Coord
+		Index    int
+		Type     string
+	}{
+		{"inside polygon", relateSquare(0, 0, 10, 10), geom.Coord{X: 5, Y: 5}, geom.Coord{X: 5, Y: 5}, 0, "Polygon"},
+		{"on polygon boundary", relateSquare(0, 0, 10, 10), geom.Coord{X: 10, Y: 5}, geom.Coord{X: 10, Y: 5}, 0, "Polygon"},
+		{"outside polygon", relateSquare(0, 0, 10, 10), geom.Coord{X: 12, Y: 5}, geom.Coord{X: 10, Y: 5}, 0, "Polygon"},
+		{"outside polygon corner", relateSquare(0, 0, 10, 10), geom.Coord{X: -1, Y: -1}, geom.Coord{X: 0, Y: 0}, 0, "Polygon"},
+		{"inside hole", `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[2,2],[8,2],[8,8],[2,8],[2,2]]]}`, geom.Coord{X: 3, Y: 5}, geom.Coord{X: 2, Y: 5}, 0, "Polygon"},
+		{"point", `{"type":"Point","coordinates":[0,0]}`, geom.Coord{X: 0, Y: 1}, geom.Coord{X: 0, Y: 0}, 0, "Point"},
+		{"multipoint", `{"type":"MultiPoint","coordinates":[[0,0],[5,5]]}`, geom.Coord{X: 4, Y: 4}, geom.Coord{X: 5, Y: 5}, 1, "Point"},
+		{"line", `{"type":"LineString","coordinates":[[0,0],[10,0]]}`, geom.Coord{X: 5, Y: 1}, geom.Coord{X: 5, Y: 0}, 0, "LineString"},
+		{"line crossing the antimeridian", `{"type":"LineString","coordinates":[[179,0],[-179,0]]}`, geom.Coord{X: 180, Y: 1}, geom.Coord{X: 180, Y: 0}, 0, "LineString"},
+		{"collection", `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[20,20]},{"type":"LineString","coordinates":[[0,0],[0,10]]},` + relateSquare(30, 0, 40, 10) + `]}`, geom.Coord{X: 29, Y: 5}, geom.Coord{X: 30, Y: 5}, 2, "Polygon"},
+	}
+
+	for _, test := range tests {
+
+		f := relateFeature(t, test.Geometry)
+
+		rsp, err := geometry.DistanceToFeature(f, test.Coord)
+
+		if err != nil {
+			t.Fatalf("Failed to measure distance for %s, %v", test.Label, err)
+		}
+
+		if rsp.Index != test.Index || rsp.Type != test.Type {
+			t.Fatalf("Expected %s to match %s %d, got %s %d", test.Label, test.Type, test.Index, rsp.Type, rsp.Index)
+		}
+
+		if math.Abs(math.Abs(rsp.Coord.X)-math.Abs(test.Closest.X)) > 1e-6 || math.Abs(rsp.Coord.Y-test.Closest.Y) > 1e-6 {
+			t.Fatalf("Expected closest point for %s to be %v, got %v", test.Label, test.Closest, rsp.Coord)
+		}
+
+		expected := geometry.VincentyDistance(test.Coord, test.Closest)
+
+		if math.Abs(rsp.Distance-expected) > 0.01 {
+			t.Fatalf("Expected distance for %s to be %f, got %f", test.Label, expected, rsp.Distance)
+		}
+	}
+
+	f := relateFeature(t, `{"type":"Point","coordinates":[0,0]}`)
+
+	rsp, err := geometry.DistanceToFeature(f, geom.Coord{X: 0, Y: 1})
+
+	if err != nil {
+		t.Fatalf("Failed to measure distance, %v", err)
+	}
+
+	// one degree of latitude at the equator
+
+	if math.Abs(rsp.Distance-110574.4) > 1.0 {
+		t.Fatalf("Unexpected distance %f", rsp.Distance)
+	}
+
+	f = relateFeature(t, "null")
+
+	rsp, err = geometry.DistanceToFeature(f, geom.Coord{X: 0, Y: 1})
+
+	if err != nil {
+		t.Fatalf("Failed to measure distance, %v", err)
+	}
+
+	if rsp.Index != -1 || !math.IsInf(rsp.Distance, 1) {
+		t.Fatalf("Expected no match for a null geometry, got %v", rsp)
+	}
+}
+
+func TestDistanceToFeatureBoundary(t *testing.T) {
+
+	f := relateFeature(t, relateSquare(0, 0, 10, 10))
+
+	rsp, err := geometry.DistanceToFeatureBoundary(f, geom.Coord{X: 9, Y: 5})
+
+	if err != nil {
+		t.Fatalf("Failed to measure distance, %v", err)
+	}
+
+	if math.Abs(rsp.Coord.X-10) > 1e-6 || math.Abs(rsp.Coord.Y-5) > 1e-6 {
+		t.Fatalf("Unexpected closest point %v", rsp.Coord)
+	}
+
+	if math.Abs(rsp.Distance-geometry.VincentyDistance(geom.Coord{X: 9, Y: 5}, geom.Coord{X: 10, Y: 5})) > 0.01 {
+		t.Fatalf("Unexpected distance %f", rsp.Distance)
+	}
+}
+
+func TestMinDistanceToRect(t *testing.T) {
+
+	r := rand.New(rand.NewSource(24))
+
+	for i := 0; i < 1000; i++ {
+
+		c := geom.Coord{X: r.Float64()*360.0 - 180.0, Y: r.Float64()*170.0 - 85.0}
+
+		min_x := r.Float64()*360.0 - 180.0
+		min_y := r.Float64()*170.0 - 85.0
+
+		rect := geom.Rect{
+			Min: geom.Coord{X: min_x, Y: min_y},
+			Max: geom.Coord{X: min_x + r.Float64()*20.0, Y: math.Min(90.0, min_y+r.Float64()*20.0)},
+		}
+
+		// some rectangles cross the antimeridian
+
+		if rect.Max.X > 180.0 {
+			rect.Max.X -= 360.0
+		}
+
+		bound := geometry.MinDistanceToRect(c, rect)
+
+		for j := 0; j < 100; j++ {
+
+			width := rect.Max.X - rect.Min.X
+
+			if width < 0.0 {
+				width += 360.0
+			}
+
+			pt := geom.Coord{
+				X: rect.Min.X + r.Float64()*width,
+				Y: rect.Min.Y + r.Float64()*(rect.Max.Y-rect.Min.Y),
+			}
+
+			d := geometry.VincentyDistance(c, pt)
+
+			if bound > d {
+				t.Fatalf("Lower bound %f for %v and %v is greater than the distance %f to %v", bound, c, rect, d, pt)
+			}
+		}
+	}
+
+	rect := geom.Rect{Min: geom.Coord{X: 170, Y: -10}, Max: geom.Coord{X: -170, Y: 10}}
+
+	if geometry.MinDistanceToRect(geom.Coord{X: 180, Y: 0}, rect) != 0.0 {
+		t.Fatal("Expected a rectangle that crosses the antimeridian to contain (180, 0)")
+	}
+}
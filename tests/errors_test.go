@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"errors"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/feature"
+	"testing"
+)
+
+func TestInvalidJSONError(t *testing.T) {
+
+	_, err := feature.NewWOFFeature([]byte(`{"type":"Feature", "properties": {]}`))
+
+	if !errors.Is(err, geojson.ErrInvalidJSON) {
+		t.Fatalf("Expected ErrInvalidJSON, got %v", err)
+	}
+
+	var json_err *geojson.InvalidJSONError
+
+	if !errors.As(err, &json_err) {
+		t.Fatalf("Expected InvalidJSONError, got %T", err)
+	}
+
+	if json_err.Offset != 35 {
+		t.Fatalf("Unexpected offset %d", json_err.Offset)
+	}
+}
+
+func TestMissingPropertyError(t *testing.T) {
+
+	body := []byte(`{"type":"Feature","properties":{"wof:id":1234,"wof:repo":"whosonfirst-data"},"geometry":{"type":"Point","coordinates":[0,0]}}`)
+
+	_, err := feature.NewWOFFeature(body)
+
+	if !errors.Is(err, geojson.ErrMissingProperty) {
+		t.Fatalf("Expected ErrMissingProperty, got %v", err)
+	}
+
+	var prop_err *geojson.MissingPropertyError
+
+	if !errors.As(err, &prop_err) {
+		t.Fatalf("Expected MissingPropertyError, got %T", err)
+	}
+
+	if prop_err.Path != "properties.wof:name" || prop_err.FeatureId != "1234" {
+		t.Fatalf("Unexpected missing property error: %v (%s)", prop_err, prop_err.FeatureId)
+	}
+
+	if geojson.IsWarning(err) {
+		t.Fatalf("Missing property error should not be a warning")
+	}
+}
+
+func TestInvalidPlacetypeWarning(t *testing.T) {
+
+	body := []byte(`{"type":"Feature","properties":{"wof:id":1234,"wof:name":"test","wof:repo":"whosonfirst-data","wof:placetype":"spaceship","geom:latitude":0,"geom:longitude":0,"geom:bbox":"0,0,0,0"},"geometry":{"type":"Point","coordinates":[0,0]}}`)
+
+	f, err := feature.LoadFeature(body)
+
+	if f == nil {
+		t.Fatalf("Expected a feature alongside an invalid placetype warning")
+	}
+
+	if !geojson.IsWarning(err) {
+		t.Fatalf("Expected a warning, got %v", err)
+	}
+
+	if !errors.Is(err, geojson.ErrInvalidPlacetype) {
+		t.Fatalf("Expected ErrInvalidPlacetype, got %v", err)
+	}
+
+	var pt_err *geojson.InvalidPlacetypeWarning
+
+	if !errors.As(err, &pt_err) {
+		t.Fatalf("Expected InvalidPlacetypeWarning, got %T", err)
+	}
+
+	if pt_err.Placetype != "spaceship" || pt_err.FeatureId != "1234" {
+		t.Fatalf("Unexpected invalid placetype warning: %v", pt_err)
+	}
+}
+
+func TestInvalidGeometryError(t *testing.T) {
+
+	body := []byte(`{"type":"Feature","id":"abc","properties":{},"geometry":{"type":"Spaceship","coordinates":[0,0]}}`)
+
+	f, err := feature.LoadFeature(body)
+
+	if err != nil {
+		t.Fatalf("Failed to load feature, %v", err)
+	}
+
+	_, err = f.Polygons()
+
+	if !errors.Is(err, geojson.ErrInvalidGeometry) {
+		t.Fatalf("Expected ErrInvalidGeometry, got %v", err)
+	}
+
+	var geom_err *geojson.InvalidGeometryError
+
+	if !errors.As(err, &geom_err) {
+		t.Fatalf("Expected InvalidGeometryError, got %T", err)
+	}
+
+	if geom_err.FeatureId != "abc" {
+		t.Fatalf("Unexpected feature ID '%s'", geom_err.FeatureId)
+	}
+}
@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/feature"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func featureCollectionFixture(t *testing.T, features ...string) string {
+
+	primary, err := ioutil.ReadFile("../fixtures/101851199.geojson")
+
+	if err != nil {
+		t.Fatalf("Failed to read primary fixture, %v", err)
+	}
+
+	alt, err := ioutil.ReadFile("../fixtures/101851199-alt-quattroshapes.geojson")
+
+	if err != nil {
+		t.Fatalf("Failed to read alt fixture, %v", err)
+	}
+
+	plain := `{"type":"Feature","properties":{"name":"plain"},"geometry":{"type":"Point","coordinates":[1.0,2.0]}}`
+
+	members := []string{
+		string(primary),
+		string(alt),
+		plain,
+	}
+
+	members = append(members, features...)
+
+	return fmt.Sprintf(`{"type":"FeatureCollection","name":"test","features":[%s],"bbox":[1.0,2.0,3.0,4.0],"x:extra":{"a":1}}`, strings.Join(members, ","))
+}
+
+func TestFeatureCollection(t *testing.T) {
+
+	body := featureCollectionFixture(t)
+
+	c, err := feature.LoadFeatureCollectionFromReader(strings.NewReader(body))
+
+	if err != nil {
+		t.Fatalf("Failed to load feature collection, %v", err)
+	}
+
+	expected := []string{
+		"*feature.WOFFeature",
+		"*feature.WOFAltFeature",
+		"*feature.GeoJSONFeature",
+	}
+
+	count := 0
+
+	for {
+
+		f, err := c.Next()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("Failed to load feature %d, %v", count, err)
+		}
+
+		str_type := fmt.Sprintf("%T", f)
+
+		if str_type != expected[count] {
+			t.Fatalf("Unexpected type for feature %d: %s", count, str_type)
+		}
+
+		count += 1
+	}
+
+	if count != len(expected) {
+		t.Fatalf("Expected %d features but got %d", len(expected), count)
+	}
+
+	bbox := c.BBox()
+
+	if len(bbox) != 4 || bbox[2] != 3.0 {
+		t.Fatalf("Invalid bbox: %v", bbox)
+	}
+
+	foreign := c.ForeignMembers()
+
+	if !bytes.Equal(foreign["name"], []byte(`"test"`)) {
+		t.Fatalf("Invalid name foreign member: %s", foreign["name"])
+	}
+
+	_, ok := foreign["x:extra"]
+
+	if !ok {
+		t.Fatalf("Missing x:extra foreign member")
+	}
+}
+
+func TestFeatureCollectionErrors(t *testing.T) {
+
+	invalid := `{"type":"Feature","properties":{}}`
+	body := featureCollectionFixture(t, invalid)
+
+	c, err := feature.LoadFeatureCollectionFromReader(strings.NewReader(body))
+
+	if err != nil {
+		t.Fatalf("Failed to load feature collection, %v", err)
+	}
+
+	failed := -1
+
+	for {
+
+		_, err := c.Next()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+
+			var fc_err *feature.FeatureCollectionError
+
+			if !errors.As(err, &fc_err) {
+				t.Fatalf("Unexpected error type, %v", err)
+			}
+
+			failed = fc_err.Index
+		}
+	}
+
+	if failed != 3 {
+		t.Fatalf("Expected feature 3 to fail, got %d", failed)
+	}
+
+	_, err = feature.LoadFeatureCollectionFromReader(strings.NewReader(`{"type":"Feature","geometry":null}`))
+
+	if err == nil {
+		t.Fatalf("Expected a Feature to be rejected as a FeatureCollection")
+	}
+}
@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/feature"
+	"io"
+	"strings"
+	"testing"
+)
+
+func readFeatureSequence(t *testing.T, fh io.Reader) []geojson.Feature {
+
+	features := make([]geojson.Feature, 0)
+	seq := feature.NewFeatureSequenceReader(fh)
+
+	for {
+
+		f, err := seq.Next()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("Failed to read feature %d, %v", seq.Index(), err)
+		}
+
+		features = append(features, f)
+	}
+
+	return features
+}
+
+func TestFeatureSequenceRoundTrip(t *testing.T) {
+
+	paths := []string{
+		"../fixtures/101851199.geojson",
+		"../fixtures/101851199-alt-quattroshapes.geojson",
+	}
+
+	for _, rfc8142 := range []bool{true, false} {
+
+		var buf bytes.Buffer
+		wr := feature.NewFeatureSequenceWriter(&buf, rfc8142)
+
+		for _, path := range paths {
+
+			f, err := feature.LoadFeatureFromFile(path)
+
+			if err != nil {
+				t.Fatalf("Failed to load %s, %v", path, err)
+			}
+
+			err = wr.WriteFeature(f)
+
+			if err != nil {
+				t.Fatalf("Failed to write %s, %v", path, err)
+			}
+		}
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+		if len(lines) != len(paths) {
+			t.Fatalf("Expected %d lines but got %d", len(paths), len(lines))
+		}
+
+		if rfc8142 && lines[1][0] != feature.RECORD_SEPARATOR {
+			t.Fatalf("Expected record to start with a record separator")
+		}
+
+		features := readFeatureSequence(t, &buf)
+
+		if len(features) != len(paths) {
+			t.Fatalf("Expected %d features but got %d", len(paths), len(features))
+		}
+
+		if features[0].Id() != "101851199" || features[1].Placetype() != "alt" {
+			t.Fatalf("Unexpected features read from sequence")
+		}
+	}
+}
+
+func TestFeatureSequenceErrors(t *testing.T) {
+
+	plain := `{"type":"Feature","properties":{},"geometry":{"type":"Point","coordinates":[1.0,2.0]}}`
+
+	body := strings.Join([]string{plain, "", `{"type":"Feature"}`, plain}, "\n")
+	seq := feature.NewFeatureSequenceReader(strings.NewReader(body))
+
+	count := 0
+	failed := -1
+
+	for {
+
+		_, err := seq.Next()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+
+			var seq_err *feature.FeatureSequenceError
+
+			if !errors.As(err, &seq_err) {
+				t.Fatalf("Unexpected error type, %v", err)
+			}
+
+			failed = seq_err.Index
+			continue
+		}
+
+		count += 1
+	}
+
+	if count != 2 || failed != 1 {
+		t.Fatalf("Expected two features and a failure at index 1, got %d features and failure at %d", count, failed)
+	}
+}
+
+func TestFeatureSequenceWalk(t *testing.T) {
+
+	plain := `{"type":"Feature","properties":{},"geometry":{"type":"Point","coordinates":[1.0,2.0]}}`
+
+	body := strings.Join([]string{plain, plain, `{"type":"Feature"}`, plain}, "\n")
+
+	count := 0
+
+	err := feature.NewFeatureSequenceReader(strings.NewReader(body)).Walk(func(f geojson.Feature) error {
+		count += 1
+		return nil
+	})
+
+	var seq_err *feature.FeatureSequenceError
+
+	if !errors.As(err, &seq_err) || seq_err.Index != 2 || count != 2 {
+		t.Fatalf("Expected walk to stop at index 2 after two features, got %d features and %v", count, err)
+	}
+
+	stop := errors.New("stop")
+	count = 0
+
+	err = feature.NewFeatureSequenceReader(strings.NewReader(body)).WalkBytes(func(body []byte) error {
+		count += 1
+		return stop
+	})
+
+	if err != stop || count != 1 {
+		t.Fatalf("Expected walk to stop after the first record, got %d records and %v", count, err)
+	}
+
+	count = 0
+
+	err = feature.NewFeatureSequenceReader(strings.NewReader(plain + "\n" + plain)).WalkBytes(func(body []byte) error {
+		count += 1
+		return nil
+	})
+
+	if err != nil || count != 2 {
+		t.Fatalf("Expected to walk two records, got %d records and %v", count, err)
+	}
+}
@@ -0,0 +1,308 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/mmcloughlin/geohash"
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/feature"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/geometry"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/index"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// testGeohashCover checks that every cell in the cover of f is between the
+// minimum and maximum precision, that no cell is inside another one, that
+// points in interior cells are in f and that every point in f is in a cell.
+
+func testGeohashCover(t *testing.T, label string, f geojson.Feature, opts *geometry.GeohashCoverOptions) []*geometry.GeohashCell {
+
+	cells, err := geometry.GeohashCoverForFeature(f, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to cover %s, %v", label, err)
+	}
+
+	if len(cells) == 0 {
+		t.Fatalf("Expected a non-empty cover for %s", label)
+	}
+
+	lookup := make(map[string]*geometry.GeohashCell)
+
+	for i, cell := range cells {
+
+		if len(cell.Hash) < opts.MinPrecision || len(cell.Hash) > opts.MaxPrecision {
+			t.Fatalf("Unexpected precision for cell %s of %s", cell.Hash, label)
+		}
+
+		if cell.Location == geometry.LOCATION_BOUNDARY && len(cell.Hash) != opts.MaxPrecision {
+			t.Fatalf("Expected boundary cell %s of %s to have the maximum precision", cell.Hash, label)
+		}
+
+		if i > 0 && strings.HasPrefix(cell.Hash, cells[i-1].Hash) {
+			t.Fatalf("Cell %s of %s is inside cell %s", cell.Hash, label, cells[i-1].Hash)
+		}
+
+		if i > 0 && cell.Hash <= cells[i-1].Hash {
+			t.Fatalf("Expected cells of %s to be sorted", label)
+		}
+
+		lookup[cell.Hash] = cell
+	}
+
+	r := rand.New(rand.NewSource(25))
+
+	for _, cell := range cells {
+
+		if cell.Location != geometry.LOCATION_INTERIOR {
+			continue
+		}
+
+		box := geohash.BoundingBox(cell.Hash)
+
+		for i := 0; i < 10; i++ {
+
+			c := geom.Coord{
+				X: box.MinLng + r.Float64()*(box.MaxLng-box.MinLng),
+				Y: box.MinLat + r.Float64()*(box.MaxLat-box.MinLat),
+			}
+
+			ok, err := f.ContainsCoord(c)
+
+			if err != nil || !ok {
+				t.Fatalf("Expected %v in interior cell %s to be in %s (%v)", c, cell.Hash, label, err)
+			}
+		}
+	}
+
+	bboxes, err := f.BoundingBoxes()
+
+	if err != nil {
+		t.Fatalf("Failed to derive bounding boxes, %v", err)
+	}
+
+	mbr := bboxes.MBR()
+	width := mbr.Max.X - mbr.Min.X
+
+	if width < 0.0 {
+		width += 360.0
+	}
+
+	for i := 0; i < 10000; i++ {
+
+		c := geom.Coord{
+			X: mbr.Min.X + r.Float64()*width,
+			Y: mbr.Min.Y + r.Float64()*(mbr.Max.Y-mbr.Min.Y),
+		}
+
+		ok, err := f.ContainsCoord(c)
+
+		if err != nil {
+			t.Fatalf("Failed to test %v, %v", c, err)
+		}
+
+		if !ok {
+			continue
+		}
+
+		found := false
+
+		for _, hash := range geometry.GeohashesForCoord(c, opts.MaxPrecision) {
+
+			_, found = lookup[hash]
+
+			if found {
+				break
+			}
+		}
+
+		if !found {
+			t.Fatalf("Expected %v to be covered by a cell of %s", c, label)
+		}
+	}
+
+	return cells
+}
+
+func TestGeohashCover(t *testing.T) {
+
+	square := relateFeature(t, relateSquare(0.5, 0.5, 10.5, 10.5))
+
+	cells := testGeohashCover(t, "square", square, &geometry.GeohashCoverOptions{MinPrecision: 2, MaxPrecision: 2})
+
+	interior := 0
+
+	for _, cell := range cells {
+
+		if cell.Location == geometry.LOCATION_INTERIOR {
+			interior += 1
+		}
+	}
+
+	// a precision 2 cell is 11.25 degrees wide and 5.625 degrees high so the
+	// square touches two of them, both of which it crosses
+
+	if interior != 0 || len(cells) != 2 || cells[0].Hash != "s0" || cells[1].Hash != "s1" {
+		t.Fatalf("Unexpected cover for square %v", cells)
+	}
+
+	circle, err := feature.LoadFeature(circleFeature(1000))
+
+	if err != nil {
+		t.Fatalf("Failed to load feature, %v", err)
+	}
+
+	testGeohashCover(t, "circle", circle, &geometry.GeohashCoverOptions{MinPrecision: 1, MaxPrecision: 4})
+	testGeohashCover(t, "circle", circle, &geometry.GeohashCoverOptions{MinPrecision: 3, MaxPrecision: 3})
+
+	hole := relateFeature(t, `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[2,2],[8,2],[8,8],[2,8],[2,2]]]}`)
+	testGeohashCover(t, "polygon with a hole", hole, &geometry.GeohashCoverOptions{MinPrecision: 1, MaxPrecision: 4})
+
+	for _, path := range []string{"../fixtures/antimeridian-polygon.geojson", "../fixtures/antimeridian-multipolygon.geojson", "../fixtures/101851199-alt-quattroshapes.geojson"} {
+		testGeohashCover(t, path, loadAntimeridianFixture(t, path), &geometry.GeohashCoverOptions{MinPrecision: 1, MaxPrecision: 4})
+	}
+}
+
+func TestGeohashCoverOptions(t *testing.T) {
+
+	f := relateFeature(t, relateSquare(0, 0, 10, 10))
+
+	invalid := []*geometry.GeohashCoverOptions{
+		{MinPrecision: 0, MaxPrecision: 4},
+		{MinPrecision: 4, MaxPrecision: 3},
+		{MinPrecision: 1, MaxPrecision: 13},
+		{MinPrecision: 1, MaxPrecision: 4, MaxCells: -1},
+	}
+
+	for _, opts := range invalid {
+
+		_, err := geometry.GeohashCoverForFeature(f, opts)
+
+		if err == nil {
+			t.Fatalf("Expected options %v to be invalid", opts)
+		}
+	}
+
+	_, err := geometry.GeohashCoverForFeature(f, &geometry.GeohashCoverOptions{MinPrecision: 1, MaxPrecision: 6, MaxCells: 100})
+
+	if err == nil {
+		t.Fatal("Expected cover to exceed the maximum number of cells")
+	}
+
+	cells, err := geometry.GeohashCoverForFeature(relateFeature(t, `{"type":"Point","coordinates":[0,0]}`), nil)
+
+	if err != nil || len(cells) != 0 {
+		t.Fatalf("Expected an empty cover for a point, got %v (%v)", cells, err)
+	}
+}
+
+func TestGeohashesForCoord(t *testing.T) {
+
+	hashes := geometry.GeohashesForCoord(geom.Coord{X: -122.4194, Y: 37.7749}, 5)
+
+	if fmt.Sprintf("%v", hashes) != "[9 9q 9q8 9q8y 9q8yy]" {
+		t.Fatalf("Unexpected geohashes %v", hashes)
+	}
+
+	for _, c := range []geom.Coord{{X: 180, Y: 90}, {X: -180, Y: -90}, {X: 190, Y: 0}} {
+
+		hashes := geometry.GeohashesForCoord(c, 12)
+
+		if len(hashes) != 12 || geohash.Validate(hashes[11]) != nil {
+			t.Fatalf("Unexpected geohashes for %v, %v", c, hashes)
+		}
+
+		box := geohash.BoundingBox(hashes[11])
+
+		if box.MinLat > c.Y+1e-6 || box.MaxLat < c.Y-1e-6 {
+			t.Fatalf("Expected geohash %s to contain %v", hashes[11], c)
+		}
+	}
+}
+
+func TestGeohashIndex(t *testing.T) {
+
+	opts := &geometry.GeohashCoverOptions{MinPrecision: 1, MaxPrecision: 4}
+
+	gh_idx := index.NewGeohashIndex(opts)
+	idx := index.NewIndex()
+
+	features := []geojson.Feature{
+		indexFeature(t, 1, "country", 1, relateSquare(0, 0, 10, 10)),
+		indexFeature(t, 2, "region", 1, relateSquare(0, 0, 5, 5)),
+		indexFeature(t, 3, "region", 0, relateSquare(0, 0, 5, 5)),
+		indexFeature(t, 4, "locality", 1, `{"type":"Polygon","coordinates":[[[1,1],[4,1],[1,4],[1,1]]]}`),
+		indexFeature(t, 5, "region", 1, `{"type":"Polygon","coordinates":[[[170,-10],[-170,-10],[-170,10],[170,10],[170,-10]]]}`),
+	}
+
+	for _, f := range features {
+
+		err := gh_idx.Add(f)
+
+		if err != nil {
+			t.Fatalf("Failed to add feature, %v", err)
+		}
+
+		err = idx.Add(f)
+
+		if err != nil {
+			t.Fatalf("Failed to add feature, %v", err)
+		}
+	}
+
+	if gh_idx.Len() != 5 {
+		t.Fatalf("Unexpected index size %d", gh_idx.Len())
+	}
+
+	err := gh_idx.Add(features[0])
+
+	if err == nil {
+		t.Fatal("Expected adding the same feature twice to fail")
+	}
+
+	candidates := gh_idx.Candidates(geom.Coord{X: 7, Y: 7})
+
+	if len(candidates) != 1 || candidates[0].Id != "1" || candidates[0].Location != geometry.LOCATION_INTERIOR {
+		t.Fatalf("Unexpected candidates %v", candidates)
+	}
+
+	filters := &index.QueryFilters{
+		IncludePlacetypes: []string{"region"},
+	}
+
+	r := rand.New(rand.NewSource(25))
+
+	for i := 0; i < 1000; i++ {
+
+		var c geom.Coord
+
+		if i%2 == 0 {
+			c = geom.Coord{X: r.Float64()*12.0 - 1.0, Y: r.Float64()*12.0 - 1.0}
+		} else {
+			c = geom.Coord{X: r.Float64()*360.0 - 180.0, Y: r.Float64()*30.0 - 15.0}
+		}
+
+		for _, fl := range []*index.QueryFilters{nil, filters} {
+
+			expected := indexQueryIds(t, idx, c, fl)
+
+			results, err := gh_idx.Query(c, fl)
+
+			if err != nil {
+				t.Fatalf("Failed to query index, %v", err)
+			}
+
+			ids := make([]string, len(results))
+
+			for j, rsp := range results {
+				ids[j] = rsp.Id()
+			}
+
+			if fmt.Sprintf("%v", ids) != fmt.Sprintf("%v", expected) {
+				t.Fatalf("Unexpected results for %v, expected %v but got %v", c, expected, ids)
+			}
+		}
+	}
+}
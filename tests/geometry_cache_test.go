@@ -0,0 +1,168 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/feature"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/geometry"
+	"math"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type geometryCacheDropper interface {
+	DropGeometryCache()
+}
+
+// circleFeature returns a WOF feature whose geometry is a polygon with count
+// vertices approximating a circle of radius 1 degree around (0, 0).
+
+func circleFeature(count int) []byte {
+
+	coords := make([]string, 0)
+
+	for i := 0; i <= count; i++ {
+
+		a := 2.0 * math.Pi * float64(i%count) / float64(count)
+		coords = append(coords, fmt.Sprintf("[%f,%f]", math.Cos(a), math.Sin(a)))
+	}
+
+	return []byte(fmt.Sprintf(`{"type":"Feature","properties":{"wof:id":1234,"wof:name":"circle","wof:repo":"whosonfirst-data","wof:placetype":"region","geom:latitude":0,"geom:longitude":0,"geom:bbox":"-1,-1,1,1"},"geometry":{"type":"Polygon","coordinates":[[%s]]}}`, strings.Join(coords, ",")))
+}
+
+func TestGeometryCache(t *testing.T) {
+
+	f, err := feature.LoadFeature(circleFeature(1000))
+
+	if err != nil {
+		t.Fatalf("Failed to load feature, %v", err)
+	}
+
+	inside := geom.Coord{X: 0.5, Y: 0.5}
+	outside := geom.Coord{X: 0.9, Y: 0.9}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+
+		wg.Add(1)
+
+		go func() {
+
+			defer wg.Done()
+
+			ok, err := f.ContainsCoord(inside)
+
+			if err != nil || !ok {
+				t.Errorf("Expected feature to contain %v (%v)", inside, err)
+			}
+
+			ok, err = f.ContainsCoord(outside)
+
+			if err != nil || ok {
+				t.Errorf("Expected feature not to contain %v (%v)", outside, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	polys, err := f.Polygons()
+
+	if err != nil {
+		t.Fatalf("Failed to derive polygons, %v", err)
+	}
+
+	again, _ := f.Polygons()
+
+	if &polys[0] != &again[0] {
+		t.Fatalf("Expected polygons to be cached")
+	}
+
+	d, ok := f.(geometryCacheDropper)
+
+	if !ok {
+		t.Fatalf("Expected %T to have a DropGeometryCache method", f)
+	}
+
+	d.DropGeometryCache()
+
+	again, _ = f.Polygons()
+
+	if &polys[0] == &again[0] {
+		t.Fatalf("Expected polygons to be re-derived after dropping the cache")
+	}
+
+	bboxes, err := f.BoundingBoxes()
+
+	if err != nil {
+		t.Fatalf("Failed to derive bounding boxes, %v", err)
+	}
+
+	mbr := bboxes.MBR()
+
+	if mbr.Min.X != -1.0 || mbr.Max.X != 1.0 {
+		t.Fatalf("Unexpected MBR %v", mbr)
+	}
+}
+
+func benchmarkFeature(b *testing.B) geojson.Feature {
+
+	f, err := feature.LoadFeature(circleFeature(10000))
+
+	if err != nil {
+		b.Fatalf("Failed to load feature, %v", err)
+	}
+
+	return f
+}
+
+func BenchmarkContainsCoordCached(b *testing.B) {
+
+	f := benchmarkFeature(b)
+	c := geom.Coord{X: 0.5, Y: 0.5}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		f.ContainsCoord(c)
+	}
+}
+
+func BenchmarkContainsCoordUncached(b *testing.B) {
+
+	f := benchmarkFeature(b)
+	c := geom.Coord{X: 0.5, Y: 0.5}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		geometry.FeatureContainsCoord(f, c)
+	}
+}
+
+func BenchmarkSPRCached(b *testing.B) {
+
+	f := benchmarkFeature(b)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		f.SPR()
+	}
+}
+
+func BenchmarkSPRUncached(b *testing.B) {
+
+	f := benchmarkFeature(b)
+	d := f.(geometryCacheDropper)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		d.DropGeometryCache()
+		f.SPR()
+	}
+}
@@ -0,0 +1,286 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/feature"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/geometry"
+	"math"
+	"testing"
+)
+
+func TestMultiLineString(t *testing.T) {
+
+	body := []byte(`{"type":"Feature","properties":{},"geometry":{"type":"MultiLineString","coordinates":[[[0,0],[1,1]],[[2,2],[3,4]]]}}`)
+
+	f, err := feature.LoadFeature(body)
+
+	if err != nil {
+		t.Fatalf("Failed to load feature, %v", err)
+	}
+
+	polys, err := f.Polygons()
+
+	if err != nil {
+		t.Fatalf("Failed to derive polygons, %v", err)
+	}
+
+	if len(polys) != 0 {
+		t.Fatalf("Expected lines not to be polygons, got %d", len(polys))
+	}
+
+	members, err := geometry.MembersForFeature(f)
+
+	if err != nil {
+		t.Fatalf("Failed to derive members, %v", err)
+	}
+
+	if len(members) != 2 || geometry.MemberType(members[1]) != "LineString" {
+		t.Fatalf("Expected 2 lines, got %d members", len(members))
+	}
+
+	bboxes, err := f.BoundingBoxes()
+
+	if err != nil {
+		t.Fatalf("Failed to derive bounding boxes, %v", err)
+	}
+
+	mbr := bboxes.MBR()
+
+	if mbr.Min.X != 0 || mbr.Min.Y != 0 || mbr.Max.X != 3 || mbr.Max.Y != 4 {
+		t.Fatalf("Unexpected MBR %v", mbr)
+	}
+}
+
+func TestGeometryCollection(t *testing.T) {
+
+	body := []byte(`{"type":"Feature","properties":{},"geometry":{"type":"GeometryCollection","geometries":[
+		{"type":"Point","coordinates":[-10,-10]},
+		{"type":"Polygon","coordinates":[[[0,0],[0,2],[2,2],[2,0],[0,0]]]},
+		{"type":"GeometryCollection","geometries":[{"type":"LineString","coordinates":[[5,5],[6,8]]}]}
+	]}}`)
+
+	_, err := feature.UnmarshalFeature(body)
+
+	if err != nil {
+		t.Fatalf("Failed to unmarshal GeometryCollection, %v", err)
+	}
+
+	f, err := feature.LoadFeature(body)
+
+	if err != nil {
+		t.Fatalf("Failed to load feature, %v", err)
+	}
+
+	polys, err := f.Polygons()
+
+	if err != nil {
+		t.Fatalf("Failed to derive polygons, %v", err)
+	}
+
+	if len(polys) != 1 {
+		t.Fatalf("Expected 1 polygon, got %d", len(polys))
+	}
+
+	members, err := geometry.MembersForFeature(f)
+
+	if err != nil {
+		t.Fatalf("Failed to derive members, %v", err)
+	}
+
+	expected := []string{"Point", "Polygon", "LineString"}
+
+	if len(members) != len(expected) {
+		t.Fatalf("Expected %d members, got %d", len(expected), len(members))
+	}
+
+	for i, typ := range expected {
+
+		if geometry.MemberType(members[i]) != typ {
+			t.Fatalf("Expected member %d to be a %s, got %s", i, typ, geometry.MemberType(members[i]))
+		}
+	}
+
+	ok, err := f.ContainsCoord(geom.Coord{X: 1, Y: 1})
+
+	if err != nil || !ok {
+		t.Fatalf("Expected GeometryCollection to contain (1, 1), %v", err)
+	}
+
+	bboxes, _ := f.BoundingBoxes()
+	mbr := bboxes.MBR()
+
+	if mbr.Min.X != -10 || mbr.Min.Y != -10 || mbr.Max.X != 6 || mbr.Max.Y != 8 {
+		t.Fatalf("Unexpected MBR %v", mbr)
+	}
+}
+
+func TestNullGeometry(t *testing.T) {
+
+	docs := [][]byte{
+		[]byte(`{"type":"Feature","id":"nowhere","properties":{"name":"Nowhere"},"geometry":null}`),
+		[]byte(`{"type":"Feature","properties":{"wof:id":1234,"wof:name":"Unlocated","wof:repo":"whosonfirst-data","wof:placetype":"locality","geom:latitude":0,"geom:longitude":0,"geom:bbox":"0,0,0,0"},"geometry":null}`),
+	}
+
+	for _, body := range docs {
+
+		_, err := feature.UnmarshalFeature(body)
+
+		if err != nil {
+			t.Fatalf("Failed to unmarshal feature with a null geometry, %v", err)
+		}
+
+		f, err := feature.LoadFeature(body)
+
+		if err != nil {
+			t.Fatalf("Failed to load feature with a null geometry, %v", err)
+		}
+
+		if geometry.HasGeometry(f) {
+			t.Fatalf("Expected %s to have no geometry", f.Id())
+		}
+
+		polys, err := f.Polygons()
+
+		if err != nil || len(polys) != 0 {
+			t.Fatalf("Expected no polygons, got %d (%v)", len(polys), err)
+		}
+
+		bboxes, err := f.BoundingBoxes()
+
+		if err != nil || len(bboxes.Bounds()) != 0 {
+			t.Fatalf("Expected no bounds (%v)", err)
+		}
+
+		ok, err := f.ContainsCoord(geom.Coord{X: 0, Y: 0})
+
+		if err != nil || ok {
+			t.Fatalf("Expected a null geometry to contain nothing (%v)", err)
+		}
+
+		s, err := f.SPR()
+
+		if err != nil {
+			t.Fatalf("Failed to create SPR, %v", err)
+		}
+
+		_, err = json.Marshal(s)
+
+		if err != nil {
+			t.Fatalf("Failed to encode SPR, %v", err)
+		}
+	}
+
+	_, err := feature.UnmarshalFeature([]byte(`{"type":"Feature","properties":{}}`))
+
+	if err == nil {
+		t.Fatalf("Expected a feature without any geometry property to fail")
+	}
+}
+
+func TestEmptyCoordinates(t *testing.T) {
+
+	empty := []string{
+		`{"type":"Point","coordinates":[]}`,
+		`{"type":"LineString","coordinates":[]}`,
+		`{"type":"MultiLineString","coordinates":[[]]}`,
+		`{"type":"Polygon","coordinates":[]}`,
+		`{"type":"Polygon","coordinates":[[]]}`,
+		`{"type":"MultiPolygon","coordinates":[[]]}`,
+		`{"type":"MultiPolygon","coordinates":[[[]]]}`,
+		`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[]}]}`,
+	}
+
+	for _, g := range empty {
+
+		f, err := feature.LoadFeature([]byte(`{"type":"Feature","properties":{},"geometry":` + g + `}`))
+
+		if err != nil {
+			t.Fatalf("Failed to load %s, %v", g, err)
+		}
+
+		polys, err := f.Polygons()
+
+		if err != nil || len(polys) != 0 {
+			t.Fatalf("Expected no polygons for %s, got %d (%v)", g, len(polys), err)
+		}
+
+		ok, err := f.ContainsCoord(geom.Coord{X: 0, Y: 0})
+
+		if err != nil || ok {
+			t.Fatalf("Expected %s to contain nothing (%v)", g, err)
+		}
+
+		s, err := f.SPR()
+
+		if err != nil {
+			t.Fatalf("Failed to create SPR for %s, %v", g, err)
+		}
+
+		_, err = json.Marshal(s)
+
+		if err != nil {
+			t.Fatalf("Failed to encode SPR for %s, %v", g, err)
+		}
+
+		bboxes, err := f.BoundingBoxes()
+
+		if err != nil {
+			t.Fatalf("Failed to derive bounding boxes for %s, %v", g, err)
+		}
+
+		mbr := bboxes.MBR()
+
+		for _, v := range []float64{mbr.Min.X, mbr.Min.Y, mbr.Max.X, mbr.Max.Y} {
+
+			if math.IsInf(v, 0) || math.IsNaN(v) {
+				t.Fatalf("Expected finite bounds for %s, got %v", g, mbr)
+			}
+		}
+
+		if len(bboxes.Bounds()) != 0 {
+			t.Fatalf("Expected no bounds for %s, got %d", g, len(bboxes.Bounds()))
+		}
+	}
+
+	invalid := []string{
+		`{"type":"Point","coordinates":[1]}`,
+		`{"type":"MultiPoint","coordinates":[[]]}`,
+		`{"type":"LineString","coordinates":[[1]]}`,
+		`{"type":"MultiLineString","coordinates":[[[0,0],[1]]]}`,
+		`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1],[0,0]]]}`,
+		`{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]],[[[0,0],[1,0],[],[0,0]]]]}`,
+		`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1]}]}`,
+	}
+
+	for _, g := range invalid {
+
+		f, err := feature.LoadFeature([]byte(`{"type":"Feature","properties":{},"geometry":` + g + `}`))
+
+		if err != nil {
+			t.Fatalf("Failed to load %s, %v", g, err)
+		}
+
+		_, err = f.Polygons()
+
+		var geom_err *geojson.InvalidGeometryError
+
+		if !errors.As(err, &geom_err) || geom_err.Path != "geometry.coordinates" {
+			t.Fatalf("Expected an invalid geometry error for %s, got %v", g, err)
+		}
+
+		_, err = f.ContainsCoord(geom.Coord{X: 0, Y: 0})
+
+		if !errors.Is(err, geojson.ErrInvalidGeometry) {
+			t.Fatalf("Expected ContainsCoord to fail for %s, got %v", g, err)
+		}
+
+		_, err = f.SPR()
+
+		if !errors.Is(err, geojson.ErrInvalidGeometry) {
+			t.Fatalf("Expected SPR to fail for %s, got %v", g, err)
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/feature"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/geometry"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/index"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func indexFeature(t *testing.T, id int, placetype string, is_current int, geom string) geojson.Feature {
+
+	body := fmt.Sprintf(`{"type":"Feature","properties":{"wof:id":%d,"wof:name":"feature %d","wof:repo":"whosonfirst-data","wof:placetype":"%s","mz:is_current":%d,"geom:latitude":0,"geom:longitude":0,"geom:bbox":"0,0,0,0"},"geometry":%s}`, id, id, placetype, is_current, geom)
+
+	f, err := feature.LoadFeature([]byte(body))
+
+	if err != nil {
+		t.Fatalf("Failed to load feature, %v", err)
+	}
+
+	return f
+}
+
+func indexQueryIds(t *testing.T, idx *index.Index, c geom.Coord, filters *index.QueryFilters) []string {
+
+	results, err := idx.Query(c, filters)
+
+	if err != nil {
+		t.Fatalf("Failed to query index, %v", err)
+	}
+
+	ids := make([]string, len(results))
+
+	for i, r := range results {
+		ids[i] = r.Id()
+	}
+
+	return ids
+}
+
+func TestRTree(t *testing.T) {
+
+	tree := index.NewRTree()
+	rects := make([]geom.Rect, 0)
+
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 1000; i++ {
+
+		x := r.Float64()*360.0 - 180.0
+		y := r.Float64()*180.0 - 90.0
+
+		rect := geom.Rect{
+			Min: geom.Coord{X: x, Y: y},
+			Max: geom.Coord{X: x + r.Float64()*10.0, Y: y + r.Float64()*10.0},
+		}
+
+		rects = append(rects, rect)
+		tree.Insert(rect, i)
+	}
+
+	if tree.Len() != 1000 {
+		t.Fatalf("Unexpected tree size %d", tree.Len())
+	}
+
+	for i := 0; i < 100; i++ {
+
+		c := geom.Coord{X: r.Float64()*360.0 - 180.0, Y: r.Float64()*180.0 - 90.0}
+
+		expected := make([]int, 0)
+
+		for j, rect := range rects {
+
+			if rect.Min.X <= c.X && rect.Max.X >= c.X && rect.Min.Y <= c.Y && rect.Max.Y >= c.Y {
+				expected = append(expected, j)
+			}
+		}
+
+		found := make([]int, 0)
+
+		for _, v := range tree.SearchCoord(c) {
+			found = append(found, v.(int))
+		}
+
+		sort.Ints(found)
+
+		if fmt.Sprintf("%v", found) != fmt.Sprintf("%v", expected) {
+			t.Fatalf("Unexpected results for %v, expected %v but got %v", c, expected, found)
+		}
+	}
+}
+
+func TestIndexQuery(t *testing.T) {
+
+	idx := index.NewIndex()
+
+	features := []geojson.Feature{
+		indexFeature(t, 1, "country", 1, relateSquare(0, 0, 10, 10)),
+		indexFeature(t, 2, "region", 1, relateSquare(0, 0, 5, 5)),
+		indexFeature(t, 3, "region", 0, relateSquare(0, 0, 5, 5)),
+		indexFeature(t, 4, "locality", 1, `{"type":"Polygon","coordinates":[[[1,1],[4,1],[1,4],[1,1]]]}`),
+		indexFeature(t, 5, "region", 1, `{"type":"Polygon","coordinates":[[[170,-10],[-170,-10],[-170,10],[170,10],[170,-10]]]}`),
+	}
+
+	for _, f := range features {
+
+		err := idx.Add(f)
+
+		if err != nil {
+			t.Fatalf("Failed to add feature, %v", err)
+		}
+	}
+
+	if idx.Len() != 5 {
+		t.Fatalf("Unexpected index size %d", idx.Len())
+	}
+
+	err := idx.Add(features[0])
+
+	if err == nil {
+		t.Fatalf("Expected adding the same feature twice to fail")
+	}
+
+	tests := []struct {
+		Coord    geom.Coord
+		Filters  *index.QueryFilters
+		Expected []string
+	}{
+		{geom.Coord{X: 2, Y: 2}, nil, []string{"1", "2", "3", "4"}},
+		{geom.Coord{X: 3.5, Y: 3.5}, nil, []string{"1", "2", "3"}},
+		{geom.Coord{X: 7, Y: 7}, nil, []string{"1"}},
+		{geom.Coord{X: 20, Y: 20}, nil, []string{}},
+		{geom.Coord{X: 2, Y: 2}, &index.QueryFilters{IncludePlacetypes: []string{"region"}}, []string{"2", "3"}},
+		{geom.Coord{X: 2, Y: 2}, &index.QueryFilters{ExcludePlacetypes: []string{"region"}}, []string{"1", "4"}},
+		{geom.Coord{X: 2, Y: 2}, &index.QueryFilters{IncludePlacetypes: []string{"region"}, IncludeExistential: []string{"is_current"}}, []string{"2"}},
+		{geom.Coord{X: 175, Y: 0}, nil, []string{"5"}},
+		{geom.Coord{X: -175, Y: 0}, nil, []string{"5"}},
+	}
+
+	for _, test := range tests {
+
+		ids := indexQueryIds(t, idx, test.Coord, test.Filters)
+
+		if fmt.Sprintf("%v", ids) != fmt.Sprintf("%v", test.Expected) {
+			t.Fatalf("Unexpected results for %v, expected %v but got %v", test.Coord, test.Expected, ids)
+		}
+	}
+
+	_, err = idx.Query(geom.Coord{X: 2, Y: 2}, &index.QueryFilters{IncludeExistential: []string{"is_spaceship"}})
+
+	if err == nil {
+		t.Fatalf("Expected an invalid existential flag to fail")
+	}
+}
+
+func TestIndexQueryTolerance(t *testing.T) {
+
+	idx := index.NewIndex()
+
+	features := []geojson.Feature{
+		indexFeature(t, 1, "venue", 1, `{"type":"Point","coordinates":[10,10]}`),
+		indexFeature(t, 2, "venue", 1, `{"type":"Point","coordinates":[179.999996,0]}`),
+		indexFeature(t, 3, "venue", 1, `{"type":"LineString","coordinates":[[0,20],[10,20]]}`),
+	}
+
+	for _, f := range features {
+
+		err := idx.Add(f)
+
+		if err != nil {
+			t.Fatalf("Failed to add feature, %v", err)
+		}
+	}
+
+	// each coordinate is less than a metre, the default tolerance, from a
+	// point or line and outside its bounding box
+
+	tests := []struct {
+		Coord    geom.Coord
+		Expected []string
+	}{
+		{geom.Coord{X: 10.000005, Y: 10.000005}, []string{"1"}},
+		{geom.Coord{X: 10.0001, Y: 10.0001}, []string{}},
+		{geom.Coord{X: -179.999998, Y: 0}, []string{"2"}},
+		{geom.Coord{X: 5, Y: 20.000004}, []string{"3"}},
+	}
+
+	for _, test := range tests {
+
+		ids := indexQueryIds(t, idx, test.Coord, nil)
+
+		if fmt.Sprintf("%v", ids) != fmt.Sprintf("%v", test.Expected) {
+			t.Fatalf("Unexpected results for %v, expected %v but got %v", test.Coord, test.Expected, ids)
+		}
+
+		for _, id := range test.Expected {
+
+			f, _ := idx.Feature(id)
+			ok, err := f.ContainsCoord(test.Coord)
+
+			if err != nil || !ok {
+				t.Fatalf("Expected feature %s to contain %v", id, test.Coord)
+			}
+		}
+	}
+}
+
+func TestIndexConcurrency(t *testing.T) {
+
+	idx := index.NewIndex()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 4; i++ {
+
+		wg.Add(1)
+
+		go func(offset int) {
+
+			defer wg.Done()
+
+			for j := 0; j < 25; j++ {
+
+				id := offset*100 + j
+				f := indexFeature(t, id, "region", 1, relateSquare(0, 0, float64(j+1), float64(j+1)))
+
+				err := idx.Add(f)
+
+				if err != nil {
+					t.Errorf("Failed to add feature, %v", err)
+				}
+			}
+
+		}(i)
+
+		wg.Add(1)
+
+		go func() {
+
+			defer wg.Done()
+
+			for j := 0; j < 25; j++ {
+
+				_, err := idx.Query(geom.Coord{X: 0.5, Y: 0.5}, nil)
+
+				if err != nil {
+					t.Errorf("Failed to query index, %v", err)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	ids := indexQueryIds(t, idx, geom.Coord{X: 0.5, Y: 0.5}, nil)
+
+	if len(ids) != 100 {
+		t.Fatalf("Expected 100 results, got %d", len(ids))
+	}
+}
+
+func TestIndexNearest(t *testing.T) {
+
+	idx := index.NewIndex()
+	features := make([]geojson.Feature, 0)
+
+	r := rand.New(rand.NewSource(24))
+
+	for i := 1; i <= 500; i++ {
+
+		x := r.Float64()*360.0 - 180.0
+		y := r.Float64()*160.0 - 80.0
+
+		var g string
+
+		switch i % 3 {
+		case 0:
+			g = relateSquare(x, y, x+r.Float64()*5.0, y+r.Float64()*5.0)
+		case 1:
+			g = fmt.Sprintf(`{"type":"LineString","coordinates":[[%f,%f],[%f,%f]]}`, x, y, x+r.Float64()*5.0, y-r.Float64()*5.0)
+		default:
+			g = fmt.Sprintf(`{"type":"Point","coordinates":[%f,%f]}`, x, y)
+		}
+
+		placetype := "locality"
+
+		if i%5 == 0 {
+			placetype = "region"
+		}
+
+		f := indexFeature(t, i, placetype, 1, g)
+
+		err := idx.Add(f)
+
+		if err != nil {
+			t.Fatalf("Failed to add feature, %v", err)
+		}
+
+		features = append(features, f)
+	}
+
+	filters := &index.QueryFilters{
+		IncludePlacetypes: []string{"region"},
+	}
+
+	for i := 0; i < 50; i++ {
+
+		c := geom.Coord{X: r.Float64()*360.0 - 180.0, Y: r.Float64()*160.0 - 80.0}
+
+		for _, fl := range []*index.QueryFilters{nil, filters} {
+
+			type candidate struct {
+				id       string
+				distance float64
+			}
+
+			expected := make([]candidate, 0)
+
+			for _, f := range features {
+
+				if fl != nil && f.Placetype() != "region" {
+					continue
+				}
+
+				rsp, err := geometry.DistanceToFeature(f, c)
+
+				if err != nil {
+					t.Fatalf("Failed to measure distance, %v", err)
+				}
+
+				expected = append(expected, candidate{f.Id(), rsp.Distance})
+			}
+
+			sort.Slice(expected, func(i, j int) bool {
+
+				if expected[i].distance != expected[j].distance {
+					return expected[i].distance < expected[j].distance
+				}
+
+				return expected[i].id < expected[j].id
+			})
+
+			results, err := idx.Nearest(c, 5, fl)
+
+			if err != nil {
+				t.Fatalf("Failed to query index, %v", err)
+			}
+
+			if len(results) != 5 {
+				t.Fatalf("Expected 5 results, got %d", len(results))
+			}
+
+			for j, rsp := range results {
+
+				if rsp.SPR.Id() != expected[j].id || rsp.Distance != expected[j].distance {
+					t.Fatalf("Unexpected result %d for %v, expected %s (%f) but got %s (%f)", j, c, expected[j].id, expected[j].distance, rsp.SPR.Id(), rsp.Distance)
+				}
+			}
+		}
+	}
+
+	results, err := idx.Nearest(geom.Coord{X: 0, Y: 0}, 0, nil)
+
+	if err != nil || len(results) != 0 {
+		t.Fatalf("Expected no results for k = 0, got %v (%v)", results, err)
+	}
+}
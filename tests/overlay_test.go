@@ -0,0 +1,215 @@
+package tests
+
+import (
+	"encoding/json"
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/geometry"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/utils"
+	"math"
+	"testing"
+)
+
+func overlayArea(polys []geojson.Polygon) float64 {
+
+	area := 0.0
+
+	for _, p := range polys {
+		area += geometry.PlanarArea(p)
+	}
+
+	return area
+}
+
+func TestOverlay(t *testing.T) {
+
+	square := relateSquare(0, 0, 10, 10)
+	overlapping := relateSquare(5, 5, 15, 15)
+	adjacent := relateSquare(10, 0, 20, 10)
+	inner := relateSquare(2, 2, 4, 4)
+	disjoint := relateSquare(20, 20, 30, 30)
+	multi := `{"type":"MultiPolygon","coordinates":[[[[0,0],[2,0],[2,2],[0,2],[0,0]]],[[[4,0],[6,0],[6,2],[4,2],[4,0]]]]}`
+	bridge := relateSquare(1, 0, 5, 2)
+	donut := `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[2,2],[2,8],[8,8],[8,2],[2,2]]]}`
+	bar := relateSquare(5, 4, 15, 6)
+
+	tests := []struct {
+		Label string
+		A     string
+		B     string
+		Op    int
+		Count int
+		Holes int
+		Area  float64
+	}{
+		{"intersection", square, overlapping, geometry.OVERLAY_INTERSECTION, 1, 0, 25},
+		{"union", square, overlapping, geometry.OVERLAY_UNION, 1, 0, 175},
+		{"difference", square, overlapping, geometry.OVERLAY_DIFFERENCE, 1, 0, 75},
+		{"symmetric difference", square, overlapping, geometry.OVERLAY_SYMMETRIC_DIFFERENCE, 2, 0, 150},
+		{"union of adjacent polygons", square, adjacent, geometry.OVERLAY_UNION, 1, 0, 200},
+		{"intersection of adjacent polygons", square, adjacent, geometry.OVERLAY_INTERSECTION, 0, 0, 0},
+		{"difference of adjacent polygons", square, adjacent, geometry.OVERLAY_DIFFERENCE, 1, 0, 100},
+		{"difference making a hole", square, inner, geometry.OVERLAY_DIFFERENCE, 1, 1, 96},
+		{"difference removing everything", inner, square, geometry.OVERLAY_DIFFERENCE, 0, 0, 0},
+		{"union of disjoint polygons", square, disjoint, geometry.OVERLAY_UNION, 2, 0, 200},
+		{"union of equal polygons", square, square, geometry.OVERLAY_UNION, 1, 0, 100},
+		{"intersection of multipolygon", multi, bridge, geometry.OVERLAY_INTERSECTION, 2, 0, 4},
+		{"union of multipolygon", multi, bridge, geometry.OVERLAY_UNION, 1, 0, 12},
+		{"intersection with hole", donut, bar, geometry.OVERLAY_INTERSECTION, 1, 0, 4},
+		{"union with hole", donut, bar, geometry.OVERLAY_UNION, 1, 1, 64 + 6 + 10},
+		{"union filling hole", donut, relateSquare(2, 2, 8, 8), geometry.OVERLAY_UNION, 1, 0, 100},
+	}
+
+	for _, test := range tests {
+
+		a := relatePolygons(t, test.A)
+		b := relatePolygons(t, test.B)
+
+		result := geometry.Overlay(a, b, test.Op)
+
+		if len(result) != test.Count {
+			t.Fatalf("Unexpected number of polygons for %s, expected %d but got %d", test.Label, test.Count, len(result))
+		}
+
+		holes := 0
+
+		for _, p := range result {
+			holes += len(p.InteriorRings())
+		}
+
+		if holes != test.Holes {
+			t.Fatalf("Unexpected number of holes for %s, expected %d but got %d", test.Label, test.Holes, holes)
+		}
+
+		area := overlayArea(result)
+
+		if math.Abs(area-test.Area) > 1e-9 {
+			t.Fatalf("Unexpected area for %s, expected %f but got %f", test.Label, test.Area, area)
+		}
+	}
+}
+
+func TestUnionAll(t *testing.T) {
+
+	children := [][]geojson.Polygon{
+		relatePolygons(t, relateSquare(0, 0, 1, 1)),
+		relatePolygons(t, relateSquare(1, 0, 2, 1)),
+		relatePolygons(t, relateSquare(0, 1, 1, 2)),
+		relatePolygons(t, relateSquare(1, 1, 2, 2)),
+	}
+
+	result := geometry.UnionAll(children...)
+
+	if len(result) != 1 {
+		t.Fatalf("Expected a single polygon, got %d", len(result))
+	}
+
+	ring := result[0].ExteriorRing()
+	vertices := ring.Path.Vertices()
+
+	if len(vertices) != 5 {
+		t.Fatalf("Expected merged squares to have 5 vertices, got %d", len(vertices))
+	}
+}
+
+func TestOverlayAntimeridian(t *testing.T) {
+
+	a, err := loadAntimeridianFixture(t, "../fixtures/antimeridian-polygon.geojson").Polygons()
+
+	if err != nil {
+		t.Fatalf("Failed to derive polygons, %v", err)
+	}
+
+	b := relatePolygons(t, `{"type":"Polygon","coordinates":[[[175,58],[-175,58],[-175,62],[175,62],[175,58]]]}`)
+
+	result := geometry.Intersection(a, b)
+
+	if len(result) != 1 {
+		t.Fatalf("Expected a single polygon, got %d", len(result))
+	}
+
+	area := overlayArea(result)
+
+	if math.Abs(area-20.0) > 1e-9 {
+		t.Fatalf("Unexpected area %f", area)
+	}
+
+	ring := result[0].ExteriorRing()
+
+	for _, v := range ring.Path.Vertices() {
+
+		if v.X < -180.0 || v.X > 180.0 {
+			t.Fatalf("Unexpected longitude %f", v.X)
+		}
+	}
+
+	// the result isn't split at the antimeridian but reads back as a single
+	// polygon that crosses it
+
+	enc, err := json.Marshal(geometry.GeometryForPolygons(result))
+
+	if err != nil {
+		t.Fatalf("Failed to encode result, %v", err)
+	}
+
+	f := relateFeature(t, string(enc))
+
+	polys, err := geometry.PolygonsForFeature(f)
+
+	if err != nil {
+		t.Fatalf("Failed to derive polygons for result, %v", err)
+	}
+
+	if len(polys) != 1 || math.Abs(overlayArea(polys)-20.0) > 1e-9 {
+		t.Fatalf("Expected the result to read back as a single polygon with the same area")
+	}
+
+	bboxes, err := f.BoundingBoxes()
+
+	if err != nil {
+		t.Fatalf("Failed to derive bounding boxes for result, %v", err)
+	}
+
+	if !utils.RectCrossesAntimeridian(bboxes.MBR()) {
+		t.Fatalf("Expected the bounds of the result to cross the antimeridian, got %v", bboxes.MBR())
+	}
+
+	tests := map[geom.Coord]bool{
+		geom.Coord{X: 177, Y: 60}:  true,
+		geom.Coord{X: -177, Y: 60}: true,
+		geom.Coord{X: 0, Y: 60}:    false,
+	}
+
+	for c, expected := range tests {
+
+		ok, err := f.ContainsCoord(c)
+
+		if err != nil || ok != expected {
+			t.Fatalf("Expected result containing %v to be %t (%v)", c, expected, err)
+		}
+	}
+}
+
+func TestGeometryForPolygons(t *testing.T) {
+
+	a := relatePolygons(t, relateSquare(0, 0, 10, 10))
+	b := relatePolygons(t, relateSquare(5, 5, 15, 15))
+
+	g := geometry.GeometryForPolygons(geometry.Intersection(a, b))
+
+	if !g.IsPolygon() {
+		t.Fatalf("Expected a Polygon, got %s", g.Type)
+	}
+
+	ring := g.Polygon[0]
+
+	if len(ring) != 5 || ring[0][0] != ring[4][0] || ring[0][1] != ring[4][1] {
+		t.Fatalf("Expected a closed ring with five coordinates, got %v", ring)
+	}
+
+	g = geometry.GeometryForPolygons(geometry.SymmetricDifference(a, b))
+
+	if !g.IsMultiPolygon() || len(g.MultiPolygon) != 2 {
+		t.Fatalf("Expected a MultiPolygon with two polygons, got %s", g.Type)
+	}
+}
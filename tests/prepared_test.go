@@ -0,0 +1,228 @@
+package tests
+
+import (
+	"github.com/skelterjohn/geom"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/feature"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/geometry"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// preparedTestCoords returns random coordinates in and around the MBR of polys
+// along with every vertex of every ring and the midpoint of every edge, which
+// are where the two implementations are most likely to disagree.
+
+func preparedTestCoords(polys []geojson.Polygon, count int) []geom.Coord {
+
+	coords := make([]geom.Coord, 0)
+	mbr := geom.NilRect()
+
+	for _, p := range polys {
+
+		rings := append([]geom.Polygon{p.ExteriorRing()}, p.InteriorRings()...)
+
+		for _, ring := range rings {
+
+			vertices := ring.Path.Vertices()
+
+			for i, v := range vertices {
+
+				coords = append(coords, v)
+
+				if i > 0 {
+					prev := vertices[i-1]
+					coords = append(coords, geom.Coord{X: (prev.X + v.X) / 2.0, Y: (prev.Y + v.Y) / 2.0})
+				}
+
+				mbr.ExpandToContainCoord(v)
+			}
+		}
+	}
+
+	r := rand.New(rand.NewSource(22))
+
+	pad_x := mbr.Width() * 0.1
+	pad_y := mbr.Height() * 0.1
+
+	for i := 0; i < count; i++ {
+
+		x := mbr.Min.X - pad_x + r.Float64()*(mbr.Width()+2.0*pad_x)
+		y := mbr.Min.Y - pad_y + r.Float64()*(mbr.Height()+2.0*pad_y)
+
+		coords = append(coords, geom.Coord{X: x, Y: y})
+	}
+
+	coords = append(coords, geom.Coord{X: math.NaN(), Y: 0.0}, geom.Coord{X: 0.0, Y: math.Inf(-1)})
+	return coords
+}
+
+func testPreparedPolygons(t *testing.T, label string, f geojson.Feature) {
+
+	polys, err := f.Polygons()
+
+	if err != nil {
+		t.Fatalf("Failed to derive polygons for %s, %v", label, err)
+	}
+
+	prepared := geometry.PreparePolygons(polys)
+
+	for _, c := range preparedTestCoords(polys, 10000) {
+
+		for i, p := range polys {
+
+			expected := p.ContainsCoord(c)
+
+			if prepared[i].ContainsCoord(c) != expected {
+				t.Fatalf("Expected prepared polygon %d of %s to return %t for %v", i, label, expected, c)
+			}
+		}
+	}
+}
+
+func TestPreparedPolygon(t *testing.T) {
+
+	paths := []string{
+		"../fixtures/101851199-alt-quattroshapes.geojson",
+		"../fixtures/antimeridian-polygon.geojson",
+		"../fixtures/antimeridian-multipolygon.geojson",
+	}
+
+	for _, path := range paths {
+
+		f, err := feature.LoadFeatureFromFile(path)
+
+		if err != nil {
+			t.Fatalf("Failed to load %s, %v", path, err)
+		}
+
+		testPreparedPolygons(t, path, f)
+	}
+
+	f, err := feature.LoadFeature(circleFeature(5000))
+
+	if err != nil {
+		t.Fatalf("Failed to load feature, %v", err)
+	}
+
+	testPreparedPolygons(t, "circle", f)
+
+	geoms := map[string]string{
+		"polygon with a hole":   `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[2,2],[8,2],[8,8],[2,8],[2,2]]]}`,
+		"comb":                  `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[9,10],[9,1],[8,1],[8,10],[7,10],[7,1],[1,1],[1,10],[0,10],[0,0]]]}`,
+		"unclosed ring":         `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10]]]}`,
+		"long horizontal edges": `{"type":"Polygon","coordinates":[[[0,0],[100,0],[100,1],[0,1],[0,2],[100,2],[100,3],[0,3],[0,0]]]}`,
+	}
+
+	for label, g := range geoms {
+		testPreparedPolygons(t, label, relateFeature(t, g))
+	}
+}
+
+func TestPreparedPolygonIdempotent(t *testing.T) {
+
+	f, err := feature.LoadFeature(circleFeature(100))
+
+	if err != nil {
+		t.Fatalf("Failed to load feature, %v", err)
+	}
+
+	polys, err := f.Polygons()
+
+	if err != nil {
+		t.Fatalf("Failed to derive polygons, %v", err)
+	}
+
+	prepared := geometry.NewPreparedPolygon(polys[0])
+
+	if geometry.NewPreparedPolygon(prepared) != prepared {
+		t.Fatal("Expected preparing a prepared polygon to return it as-is")
+	}
+
+	ext := prepared.ExteriorRing()
+
+	if len(ext.Path.Vertices()) != 101 {
+		t.Fatalf("Unexpected exterior ring %v", ext)
+	}
+}
+
+func benchmarkPolygon(b *testing.B, path string) geojson.Polygon {
+
+	var f geojson.Feature
+	var err error
+
+	if path == "" {
+		f, err = feature.LoadFeature(circleFeature(10000))
+	} else {
+		f, err = feature.LoadFeatureFromFile(path)
+	}
+
+	if err != nil {
+		b.Fatalf("Failed to load feature, %v", err)
+	}
+
+	polys, err := f.Polygons()
+
+	if err != nil {
+		b.Fatalf("Failed to derive polygons, %v", err)
+	}
+
+	return polys[0]
+}
+
+func benchmarkContainsCoord(b *testing.B, p geojson.Polygon, c geom.Coord) {
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		p.ContainsCoord(c)
+	}
+}
+
+func BenchmarkPolygonContainsCoordFixture(b *testing.B) {
+
+	p := benchmarkPolygon(b, "../fixtures/101851199-alt-quattroshapes.geojson")
+	benchmarkContainsCoord(b, p, geom.Coord{X: 2.49, Y: 44.34})
+}
+
+func BenchmarkPreparedPolygonContainsCoordFixture(b *testing.B) {
+
+	p := benchmarkPolygon(b, "../fixtures/101851199-alt-quattroshapes.geojson")
+	benchmarkContainsCoord(b, geometry.NewPreparedPolygon(p), geom.Coord{X: 2.49, Y: 44.34})
+}
+
+func BenchmarkPolygonContainsCoordAntimeridian(b *testing.B) {
+
+	p := benchmarkPolygon(b, "../fixtures/antimeridian-polygon.geojson")
+	benchmarkContainsCoord(b, p, geom.Coord{X: -179.0, Y: 62.0})
+}
+
+func BenchmarkPreparedPolygonContainsCoordAntimeridian(b *testing.B) {
+
+	p := benchmarkPolygon(b, "../fixtures/antimeridian-polygon.geojson")
+	benchmarkContainsCoord(b, geometry.NewPreparedPolygon(p), geom.Coord{X: -179.0, Y: 62.0})
+}
+
+func BenchmarkPolygonContainsCoordCircle(b *testing.B) {
+
+	p := benchmarkPolygon(b, "")
+	benchmarkContainsCoord(b, p, geom.Coord{X: 0.5, Y: 0.5})
+}
+
+func BenchmarkPreparedPolygonContainsCoordCircle(b *testing.B) {
+
+	p := benchmarkPolygon(b, "")
+	benchmarkContainsCoord(b, geometry.NewPreparedPolygon(p), geom.Coord{X: 0.5, Y: 0.5})
+}
+
+func BenchmarkNewPreparedPolygonCircle(b *testing.B) {
+
+	p := benchmarkPolygon(b, "")
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		geometry.NewPreparedPolygon(p)
+	}
+}
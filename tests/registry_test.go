@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/tidwall/gjson"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/feature"
+	"io/ioutil"
+	"testing"
+)
+
+type sfomFeature struct {
+	geojson.Feature
+}
+
+func TestFeatureTypeRegistry(t *testing.T) {
+
+	types := feature.FeatureTypes()
+
+	expected := []string{
+		feature.FEATURE_TYPE_WOF_ALT,
+		feature.FEATURE_TYPE_WOF,
+		feature.FEATURE_TYPE_GEOJSON,
+	}
+
+	if len(types) != len(expected) {
+		t.Fatalf("Expected %d registered feature types, got %d", len(expected), len(types))
+	}
+
+	for i, name := range expected {
+
+		if types[i].Name != name {
+			t.Fatalf("Expected feature type %d to be %s, got %s", i, name, types[i].Name)
+		}
+	}
+
+	sfom := &feature.FeatureType{
+		Name:     "sfomuseum",
+		Priority: 300,
+		Detect: func(body []byte) bool {
+			return gjson.GetBytes(body, "properties.wof:alt_label").Exists()
+		},
+		New: func(body []byte) (geojson.Feature, error) {
+
+			f, err := feature.NewGeoJSONFeature(body)
+
+			if err != nil {
+				return nil, err
+			}
+
+			return &sfomFeature{f}, nil
+		},
+	}
+
+	err := feature.RegisterFeatureType(sfom)
+
+	if err != nil {
+		t.Fatalf("Failed to register feature type, %v", err)
+	}
+
+	defer feature.UnregisterFeatureType(sfom.Name)
+
+	err = feature.RegisterFeatureType(sfom)
+
+	if err == nil {
+		t.Fatalf("Expected duplicate registration to fail")
+	}
+
+	body := []byte(`{"type":"Feature","properties":{"wof:id":1,"wof:alt_label":"sfo-2020"},"geometry":{"type":"Point","coordinates":[0,0]}}`)
+
+	f, err := feature.LoadFeature(body)
+
+	if err != nil {
+		t.Fatalf("Failed to load feature, %v", err)
+	}
+
+	_, ok := f.(*sfomFeature)
+
+	if !ok {
+		t.Fatalf("Expected custom feature type, got %T", f)
+	}
+
+	primary, err := ioutil.ReadFile("../fixtures/101851199.geojson")
+
+	if err != nil {
+		t.Fatalf("Failed to read fixture, %v", err)
+	}
+
+	f, err = feature.LoadFeatureAs(feature.FEATURE_TYPE_GEOJSON, primary)
+
+	if err != nil {
+		t.Fatalf("Failed to load feature as GeoJSON, %v", err)
+	}
+
+	if fmt.Sprintf("%T", f) != "*feature.GeoJSONFeature" {
+		t.Fatalf("Expected GeoJSON feature, got %T", f)
+	}
+
+	_, err = feature.LoadFeatureAs("unknown", primary)
+
+	if err == nil {
+		t.Fatalf("Expected loading an unregistered feature type to fail")
+	}
+
+	err = feature.UnregisterFeatureType(sfom.Name)
+
+	if err != nil {
+		t.Fatalf("Failed to unregister feature type, %v", err)
+	}
+
+	// without the custom type this is treated as an (invalid) WOF
+	// feature so all we care about is what kind of feature it isn't
+
+	f, _ = feature.LoadFeature(body)
+
+	_, ok = f.(*sfomFeature)
+
+	if ok {
+		t.Fatalf("Unregistered feature type is still being used")
+	}
+}
@@ -0,0 +1,184 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/geometry"
+	"testing"
+)
+
+func relateFeature(t *testing.T, geom string) geojson.Feature {
+
+	body := fmt.Sprintf(`{"type":"Feature","properties":{},"geometry":%s}`, geom)
+	return loadContainsFeature(t, []byte(body))
+}
+
+// relatePolygons returns the polygons of the geometry geom.
+
+func relatePolygons(t *testing.T, geom string) []geojson.Polygon {
+
+	polys, err := relateFeature(t, geom).Polygons()
+
+	if err != nil {
+		t.Fatalf("Failed to derive polygons, %v", err)
+	}
+
+	return polys
+}
+
+func relateSquare(min_x float64, min_y float64, max_x float64, max_y float64) string {
+	return fmt.Sprintf(`{"type":"Polygon","coordinates":[[[%f,%f],[%f,%f],[%f,%f],[%f,%f],[%f,%f]]]}`, min_x, min_y, max_x, min_y, max_x, max_y, min_x, max_y, min_x, min_y)
+}
+
+// relateHoledSquare returns the 0..10 square with a hole from 4 to 6.
+
+func relateHoledSquare() string {
+	return `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[4,4],[6,4],[6,6],[4,6],[4,4]]]}`
+}
+
+func TestRelate(t *testing.T) {
+
+	tests := []struct {
+		Label  string
+		A      string
+		B      string
+		Matrix string
+	}{
+		{"polygon contains polygon", relateSquare(0, 0, 10, 10), relateSquare(2, 2, 4, 4), "212FF1FF2"},
+		{"polygons overlap", relateSquare(0, 0, 10, 10), relateSquare(5, 5, 15, 15), "212101212"},
+		{"polygons touch along an edge", relateSquare(0, 0, 10, 10), relateSquare(10, 0, 20, 10), "FF2F11212"},
+		{"polygons touch at a corner", relateSquare(0, 0, 10, 10), relateSquare(10, 10, 20, 20), "FF2F01212"},
+		{"polygons disjoint", relateSquare(0, 0, 10, 10), relateSquare(20, 20, 30, 30), "FF2FF1212"},
+		{"polygons equal", relateSquare(0, 0, 10, 10), relateSquare(0, 0, 10, 10), "2FFF1FFF2"},
+		{"polygon with a hole", `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[2,2],[8,2],[8,8],[2,8],[2,2]]]}`, relateSquare(3, 3, 4, 4), "FF2FF1212"},
+		{"polygon covers a hole", relateHoledSquare(), relateSquare(3, 3, 7, 7), "2121F12F2"},
+		{"polygon covers a hole (reversed)", relateSquare(3, 3, 7, 7), relateHoledSquare(), "2121FF212"},
+		{"polygon fills a hole", relateHoledSquare(), relateSquare(4, 4, 6, 6), "FF2F112F2"},
+		{"polygon contains line", relateSquare(0, 0, 10, 10), `{"type":"LineString","coordinates":[[1,1],[5,5]]}`, "102FF1FF2"},
+		{"line crosses polygon", relateSquare(0, 0, 10, 10), `{"type":"LineString","coordinates":[[5,5],[15,5]]}`, "1020F1102"},
+		{"lines cross", `{"type":"LineString","coordinates":[[0,0],[10,10]]}`, `{"type":"LineString","coordinates":[[0,10],[10,0]]}`, "0F1FF0102"},
+		{"lines overlap", `{"type":"LineString","coordinates":[[0,0],[10,0]]}`, `{"type":"LineString","coordinates":[[5,0],[15,0]]}`, "1010F0102"},
+		{"lines touch", `{"type":"LineString","coordinates":[[0,0],[10,0]]}`, `{"type":"LineString","coordinates":[[10,0],[10,10]]}`, "FF1F00102"},
+		{"polygon contains point", relateSquare(0, 0, 10, 10), `{"type":"Point","coordinates":[5,5]}`, "0F2FF1FF2"},
+		{"point on polygon boundary", relateSquare(0, 0, 10, 10), `{"type":"Point","coordinates":[10,5]}`, "FF20F1FF2"},
+		{"point on line", `{"type":"LineString","coordinates":[[0,0],[10,0]]}`, `{"type":"Point","coordinates":[5,0]}`, "0F1FF0FF2"},
+		{"points equal", `{"type":"Point","coordinates":[5,0]}`, `{"type":"Point","coordinates":[5,0]}`, "0FFFFFFF2"},
+	}
+
+	for _, test := range tests {
+
+		a := relateFeature(t, test.A)
+		b := relateFeature(t, test.B)
+
+		rel, err := geometry.RelateFeatures(a, b)
+
+		if err != nil {
+			t.Fatalf("Failed to relate features (%s), %v", test.Label, err)
+		}
+
+		if rel.Matrix.String() != test.Matrix {
+			t.Fatalf("Unexpected matrix for %s, expected %s but got %s", test.Label, test.Matrix, rel.Matrix.String())
+		}
+	}
+}
+
+func TestRelatePredicates(t *testing.T) {
+
+	outer := relateFeature(t, relateSquare(0, 0, 10, 10))
+	inner := relateFeature(t, relateSquare(2, 2, 4, 4))
+	neighbour := relateFeature(t, relateSquare(10, 0, 20, 10))
+	overlapping := relateFeature(t, relateSquare(5, 5, 15, 15))
+	line := relateFeature(t, `{"type":"LineString","coordinates":[[-5,5],[5,5]]}`)
+	holed := relateFeature(t, relateHoledSquare())
+	covering := relateFeature(t, relateSquare(3, 3, 7, 7))
+
+	tests := []struct {
+		Label    string
+		Func     func(geojson.Feature, geojson.Feature) (bool, error)
+		A        geojson.Feature
+		B        geojson.Feature
+		Expected bool
+	}{
+		{"contains", geometry.Contains, outer, inner, true},
+		{"contains (reversed)", geometry.Contains, inner, outer, false},
+		{"within", geometry.Within, inner, outer, true},
+		{"touches", geometry.Touches, outer, neighbour, true},
+		{"touches (overlapping)", geometry.Touches, outer, overlapping, false},
+		{"overlaps", geometry.Overlaps, outer, overlapping, true},
+		{"overlaps (contained)", geometry.Overlaps, outer, inner, false},
+		{"overlaps (different dimensions)", geometry.Overlaps, outer, line, false},
+		{"intersects", geometry.Intersects, outer, line, true},
+		{"disjoint", geometry.Disjoint, inner, neighbour, true},
+		{"disjoint (touching)", geometry.Disjoint, outer, neighbour, false},
+		{"contains (hole)", geometry.Contains, holed, covering, false},
+		{"within (hole)", geometry.Within, covering, holed, false},
+		{"overlaps (hole)", geometry.Overlaps, holed, covering, true},
+	}
+
+	for _, test := range tests {
+
+		ok, err := test.Func(test.A, test.B)
+
+		if err != nil {
+			t.Fatalf("Failed to test %s, %v", test.Label, err)
+		}
+
+		if ok != test.Expected {
+			t.Fatalf("Unexpected result for %s, expected %t", test.Label, test.Expected)
+		}
+	}
+}
+
+func TestRelatePolygons(t *testing.T) {
+
+	a, err := relateFeature(t, relateSquare(0, 0, 10, 10)).Polygons()
+
+	if err != nil {
+		t.Fatalf("Failed to derive polygons, %v", err)
+	}
+
+	b, err := relateFeature(t, relateSquare(5, 5, 15, 15)).Polygons()
+
+	if err != nil {
+		t.Fatalf("Failed to derive polygons, %v", err)
+	}
+
+	rel := geometry.RelatePolygons(a, b)
+
+	if !rel.Overlaps() || rel.Contains() || rel.Touches() {
+		t.Fatalf("Unexpected relation %s", rel.Matrix)
+	}
+}
+
+func TestRelateAntimeridian(t *testing.T) {
+
+	a := loadAntimeridianFixture(t, "../fixtures/antimeridian-polygon.geojson")
+
+	east := relateFeature(t, relateSquare(-175, 61, -174, 62))
+	west := relateFeature(t, relateSquare(174, 61, 175, 62))
+	crossing := relateFeature(t, `{"type":"Polygon","coordinates":[[[179,61],[-179,61],[-179,62],[179,62],[179,61]]]}`)
+	hole := relateFeature(t, relateSquare(179, 64.5, 179.5, 65))
+
+	for _, b := range []geojson.Feature{east, west, crossing} {
+
+		ok, err := geometry.Contains(a, b)
+
+		if err != nil {
+			t.Fatalf("Failed to test containment, %v", err)
+		}
+
+		if !ok {
+			t.Fatalf("Expected antimeridian polygon to contain %s", b.String())
+		}
+	}
+
+	ok, err := geometry.Disjoint(a, hole)
+
+	if err != nil {
+		t.Fatalf("Failed to test disjointness, %v", err)
+	}
+
+	if !ok {
+		t.Fatalf("Expected polygon inside hole to be disjoint")
+	}
+}
@@ -0,0 +1,182 @@
+package tests
+
+import (
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/feature"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/geometry"
+	"math"
+	"testing"
+)
+
+func repairFeature(t *testing.T, geom string) (geojson.Feature, []*geometry.RepairAction) {
+
+	f := relateFeature(t, geom)
+
+	repaired, actions, err := feature.RepairFeature(f)
+
+	if err != nil {
+		t.Fatalf("Failed to repair %s, %v", geom, err)
+	}
+
+	report := geometry.ValidateFeatureGeometry(repaired)
+
+	if len(report.Findings) != 0 {
+		t.Fatalf("Expected repaired %s to be valid, got %v", geom, report.Err())
+	}
+
+	return repaired, actions
+}
+
+func repairActions(actions []*geometry.RepairAction) map[string]int {
+
+	counts := make(map[string]int)
+
+	for _, a := range actions {
+		counts[a.Action] += 1
+	}
+
+	return counts
+}
+
+func TestRepairFeature(t *testing.T) {
+
+	tests := []struct {
+		Label    string
+		Geometry string
+		Actions  []string
+		Count    int
+		Area     float64
+	}{
+		{"valid", relateSquare(0, 0, 10, 10), []string{}, 1, 100},
+		{"open ring", `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10]]]}`, []string{geometry.REPAIR_CLOSE_RING}, 1, 100},
+		{"duplicate vertices", `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,0],[10,10],[0,10],[0,10],[0,0]]]}`, []string{geometry.REPAIR_REMOVE_DUPLICATES}, 1, 100},
+		{"clockwise", `{"type":"Polygon","coordinates":[[[0,0],[0,10],[10,10],[10,0],[0,0]],[[2,2],[8,2],[8,8],[2,8],[2,2]]]}`, []string{geometry.REPAIR_REWIND, geometry.REPAIR_REWIND}, 1, 64},
+		{"degenerate hole", `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[2,2],[8,2],[2,2]]]}`, []string{geometry.REPAIR_DROP_RING}, 1, 100},
+		{"hole outside shell", `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[20,20],[20,25],[25,25],[25,20],[20,20]]]}`, []string{geometry.REPAIR_DROP_RING}, 1, 100},
+		{"nested hole", `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[2,2],[2,8],[8,8],[8,2],[2,2]],[[4,4],[4,6],[6,6],[6,4],[4,4]]]}`, []string{geometry.REPAIR_DROP_RING}, 1, 64},
+		{"bow-tie", `{"type":"Polygon","coordinates":[[[0,0],[10,10],[10,0],[0,10],[0,0]]]}`, []string{geometry.REPAIR_MAKE_VALID}, 2, 50},
+		{"spike", `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[10,15],[10,10],[0,10],[0,0]]]}`, []string{geometry.REPAIR_MAKE_VALID}, 1, 100},
+		{"hole crossing shell", `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[5,2],[5,8],[15,8],[15,2],[5,2]]]}`, []string{geometry.REPAIR_MAKE_VALID}, 2, 100},
+		{"multipolygon", `{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[0,0]]],[[[5,5],[6,5],[6,6],[5,6]]]]}`, []string{geometry.REPAIR_DROP_PART, geometry.REPAIR_CLOSE_RING}, 1, 1},
+	}
+
+	for _, test := range tests {
+
+		repaired, actions := repairFeature(t, test.Geometry)
+
+		expected := make(map[string]int)
+
+		for _, a := range test.Actions {
+			expected[a] += 1
+		}
+
+		counts := repairActions(actions)
+
+		if len(counts) != len(expected) {
+			t.Fatalf("Unexpected actions for %s: %v", test.Label, actions)
+		}
+
+		for a, count := range expected {
+
+			if counts[a] != count {
+				t.Fatalf("Unexpected actions for %s: %v", test.Label, actions)
+			}
+		}
+
+		polys, err := geometry.PolygonsForFeature(repaired)
+
+		if err != nil {
+			t.Fatalf("Failed to derive polygons for repaired %s, %v", test.Label, err)
+		}
+
+		if len(polys) != test.Count {
+			t.Fatalf("Expected %d polygons for %s, got %d", test.Count, test.Label, len(polys))
+		}
+
+		area := overlayArea(polys)
+
+		if math.Abs(area-test.Area) > 1e-9 {
+			t.Fatalf("Unexpected area for %s, %f", test.Label, area)
+		}
+	}
+}
+
+func TestRepairFeatureKeepsProperties(t *testing.T) {
+
+	f, err := feature.LoadFeature(circleFeature(10))
+
+	if err != nil {
+		t.Fatalf("Failed to load feature, %v", err)
+	}
+
+	repaired, actions, err := feature.RepairFeature(f)
+
+	if err != nil {
+		t.Fatalf("Failed to repair feature, %v", err)
+	}
+
+	if len(actions) != 0 || repaired != f {
+		t.Fatalf("Expected a valid feature to be returned as-is")
+	}
+
+	body := []byte(`{"type":"Feature","properties":{"wof:id":1234,"wof:name":"bow-tie","wof:repo":"whosonfirst-data","wof:placetype":"region","geom:latitude":0,"geom:longitude":0,"geom:bbox":"0,0,10,10"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[10,10],[10,0],[0,10],[0,0]]]}}`)
+
+	f, err = feature.LoadFeature(body)
+
+	if err != nil {
+		t.Fatalf("Failed to load feature, %v", err)
+	}
+
+	repaired, _, err = feature.RepairFeature(f)
+
+	if err != nil {
+		t.Fatalf("Failed to repair feature, %v", err)
+	}
+
+	if repaired.Id() != "1234" || repaired.Name() != "bow-tie" {
+		t.Fatalf("Expected repaired feature to keep its properties")
+	}
+}
+
+func TestRepairDegenerateGeometry(t *testing.T) {
+
+	tests := []string{
+		`{"type":"Polygon","coordinates":[[[0,0],[1,1],[0,0]]]}`,
+		`{"type":"Polygon","coordinates":[]}`,
+		`{"type":"MultiPolygon","coordinates":[[]]}`,
+	}
+
+	for _, geom := range tests {
+
+		f := relateFeature(t, geom)
+
+		repaired, actions, err := feature.RepairFeature(f)
+
+		if err != nil {
+			t.Fatalf("Failed to repair %s, %v", geom, err)
+		}
+
+		if len(actions) != 1 || actions[0].Action != geometry.REPAIR_DROP_PART {
+			t.Fatalf("Unexpected actions for %s, %v", geom, actions)
+		}
+
+		if repaired == f || geometry.HasGeometry(repaired) {
+			t.Fatalf("Expected degenerate %s to be repaired to a null geometry", geom)
+		}
+	}
+}
+
+func TestRepairAntimeridian(t *testing.T) {
+
+	f := loadAntimeridianFixture(t, "../fixtures/antimeridian-polygon.geojson")
+
+	_, actions, err := feature.RepairFeature(f)
+
+	if err != nil {
+		t.Fatalf("Failed to repair feature, %v", err)
+	}
+
+	if len(actions) != 0 {
+		t.Fatalf("Expected no repairs for antimeridian polygon, got %v", actions)
+	}
+}
@@ -0,0 +1,278 @@
+package tests
+
+import (
+	"encoding/json"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/feature"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/geometry"
+	"testing"
+)
+
+func simplifiedRings(t *testing.T, f geojson.Feature) [][][]float64 {
+
+	g, err := geometry.GeometryForFeature(f)
+
+	if err != nil {
+		t.Fatalf("Failed to parse geometry, %v", err)
+	}
+
+	if !g.IsPolygon() {
+		t.Fatalf("Expected a Polygon, got %s", g.Type)
+	}
+
+	return g.Polygon
+}
+
+func TestSimplifyFeature(t *testing.T) {
+
+	f, err := feature.LoadFeature(circleFeature(1000))
+
+	if err != nil {
+		t.Fatalf("Failed to load feature, %v", err)
+	}
+
+	for _, algo := range []string{geometry.SIMPLIFY_DOUGLAS_PEUCKER, geometry.SIMPLIFY_VISVALINGAM_WHYATT} {
+
+		for _, tolerance := range []float64{0.01, 10.0} {
+
+			opts := &geometry.SimplifyOptions{
+				Algorithm: algo,
+				Tolerance: tolerance,
+				Units:     geometry.SIMPLIFY_UNITS_DEGREES,
+			}
+
+			s, err := feature.SimplifyFeature(f, opts)
+
+			if err != nil {
+				t.Fatalf("Failed to simplify feature (%s), %v", algo, err)
+			}
+
+			if s.Id() != f.Id() || s.Name() != "circle" || s.Placetype() != "region" {
+				t.Fatalf("Expected simplified feature (%s) to keep its properties", algo)
+			}
+
+			ring := simplifiedRings(t, s)[0]
+
+			if len(ring) >= 1001 || len(ring) < 4 {
+				t.Fatalf("Unexpected number of vertices (%s, %f), %d", algo, tolerance, len(ring))
+			}
+
+			if tolerance == 10.0 && len(ring) != 4 {
+				t.Fatalf("Expected ring (%s) to be simplified to 4 vertices, got %d", algo, len(ring))
+			}
+
+			first := ring[0]
+			last := ring[len(ring)-1]
+
+			if first[0] != last[0] || first[1] != last[1] {
+				t.Fatalf("Expected simplified ring (%s) to be closed", algo)
+			}
+		}
+	}
+}
+
+func TestSimplifyKeepsHolesInside(t *testing.T) {
+
+	// the hole sits inside a bump that simplifying the exterior ring would
+	// otherwise cut off
+
+	body := []byte(`{"type":"Feature","properties":{},"geometry":{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[6,10],[5,12],[4,10],[0,10],[0,0]],[[4.9,10.5],[5,11],[5.1,10.5],[4.9,10.5]]]}}`)
+
+	f := loadContainsFeature(t, body)
+
+	opts := &geometry.SimplifyOptions{
+		Algorithm: geometry.SIMPLIFY_DOUGLAS_PEUCKER,
+		Tolerance: 3.0,
+		Units:     geometry.SIMPLIFY_UNITS_DEGREES,
+	}
+
+	s, err := feature.SimplifyFeature(f, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to simplify feature, %v", err)
+	}
+
+	rings := simplifiedRings(t, s)
+
+	if len(rings) != 2 {
+		t.Fatalf("Expected the interior ring to be kept")
+	}
+
+	// the exterior ring is simplified with a smaller tolerance, which keeps
+	// the top of the bump, rather than not at all
+
+	if len(rings[0]) != 6 {
+		t.Fatalf("Expected exterior ring to be simplified with a smaller tolerance, got %v", rings[0])
+	}
+
+	report := geometry.ValidateFeatureGeometry(s)
+
+	if report.HasErrors() {
+		t.Fatalf("Expected simplified polygon to be valid, %v", report.Err())
+	}
+}
+
+func TestSimplifyKeepsShellsValid(t *testing.T) {
+
+	// the dip in the bottom edge is less than the tolerance but removing it
+	// would leave the notch coming down from the top edge poking out of the
+	// polygon
+
+	ring := `[[0,0],[5,-0.3],[10,0],[10,10],[5.2,10],[5,-0.1],[4.8,10],[0,10],[0,0]]`
+
+	opts := &geometry.SimplifyOptions{
+		Algorithm: geometry.SIMPLIFY_DOUGLAS_PEUCKER,
+		Tolerance: 1.0,
+		Units:     geometry.SIMPLIFY_UNITS_DEGREES,
+	}
+
+	line := relateFeature(t, `{"type":"LineString","coordinates":`+ring+`}`)
+
+	g, err := geometry.SimplifyFeatureGeometry(line, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to simplify line, %v", err)
+	}
+
+	if len(g.LineString) != 8 {
+		t.Fatalf("Expected the dip to be removed from an unchecked line, got %v", g.LineString)
+	}
+
+	poly := relateFeature(t, `{"type":"Polygon","coordinates":[`+ring+`]}`)
+
+	s, err := feature.SimplifyFeature(poly, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to simplify feature, %v", err)
+	}
+
+	rings := simplifiedRings(t, s)
+
+	if len(rings[0]) < 2 || rings[0][1][1] != -0.3 {
+		t.Fatalf("Expected the dip to be kept, got %v", rings[0])
+	}
+
+	report := geometry.ValidateFeatureGeometry(s)
+
+	if report.HasErrors() {
+		t.Fatalf("Expected simplified polygon to be valid, %v", report.Err())
+	}
+}
+
+func TestSimplifyKeepsPartsApart(t *testing.T) {
+
+	// simplifying the first part on its own would flatten the dip in its top
+	// edge that the second part sits in
+
+	first := `[[[0,0],[10,0],[10,10],[5,9.7],[0,10],[0,0]]]`
+	second := `[[[4,9.8],[6,9.8],[6,12],[4,12],[4,9.8]]]`
+
+	opts := &geometry.SimplifyOptions{
+		Algorithm: geometry.SIMPLIFY_DOUGLAS_PEUCKER,
+		Tolerance: 1.0,
+		Units:     geometry.SIMPLIFY_UNITS_DEGREES,
+	}
+
+	g, err := geometry.SimplifyFeatureGeometry(relateFeature(t, `{"type":"Polygon","coordinates":`+first+`}`), opts)
+
+	if err != nil {
+		t.Fatalf("Failed to simplify polygon, %v", err)
+	}
+
+	if len(g.Polygon[0]) != 5 {
+		t.Fatalf("Expected the dip to be removed from a single polygon, got %v", g.Polygon[0])
+	}
+
+	f := relateFeature(t, `{"type":"MultiPolygon","coordinates":[`+first+`,`+second+`]}`)
+
+	g, err = geometry.SimplifyFeatureGeometry(f, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to simplify MultiPolygon, %v", err)
+	}
+
+	if len(g.MultiPolygon) != 2 {
+		t.Fatalf("Expected two parts, got %d", len(g.MultiPolygon))
+	}
+
+	parts := make([]geojson.Feature, 2)
+
+	for i, p := range g.MultiPolygon {
+
+		enc, err := json.Marshal(p)
+
+		if err != nil {
+			t.Fatalf("Failed to encode part, %v", err)
+		}
+
+		parts[i] = relateFeature(t, `{"type":"Polygon","coordinates":`+string(enc)+`}`)
+	}
+
+	rel, err := geometry.RelateFeatures(parts[0], parts[1])
+
+	if err != nil {
+		t.Fatalf("Failed to relate parts, %v", err)
+	}
+
+	if !rel.Matrix.Matches("F********") {
+		t.Fatalf("Expected simplified parts not to overlap, %s", rel.Matrix)
+	}
+}
+
+func TestSimplifyMetres(t *testing.T) {
+
+	// the middle vertex is about 55 metres from the line between the others
+
+	body := []byte(`{"type":"Feature","properties":{},"geometry":{"type":"LineString","coordinates":[[0,0],[0.01,0.0005],[0.02,0]]}}`)
+
+	f := loadContainsFeature(t, body)
+
+	tests := map[float64]int{
+		100.0: 2,
+		10.0:  3,
+	}
+
+	for tolerance, expected := range tests {
+
+		opts := &geometry.SimplifyOptions{
+			Algorithm: geometry.SIMPLIFY_DOUGLAS_PEUCKER,
+			Tolerance: tolerance,
+			Units:     geometry.SIMPLIFY_UNITS_METRES,
+		}
+
+		g, err := geometry.SimplifyFeatureGeometry(f, opts)
+
+		if err != nil {
+			t.Fatalf("Failed to simplify geometry, %v", err)
+		}
+
+		if len(g.LineString) != expected {
+			t.Fatalf("Expected %d vertices with a tolerance of %f metres, got %d", expected, tolerance, len(g.LineString))
+		}
+	}
+}
+
+func TestSimplifyInvalidOptions(t *testing.T) {
+
+	f := loadContainsFeature(t, circleFeature(10))
+
+	opts := &geometry.SimplifyOptions{
+		Algorithm: "spaceship",
+		Tolerance: 1.0,
+		Units:     geometry.SIMPLIFY_UNITS_DEGREES,
+	}
+
+	_, err := feature.SimplifyFeature(f, opts)
+
+	if err == nil {
+		t.Fatalf("Expected an invalid algorithm to fail")
+	}
+
+	f = relateFeature(t, `{"type":"LineString","coordinates":[[0,0],[1]]}`)
+
+	_, err = feature.SimplifyFeature(f, geometry.DefaultSimplifyOptions())
+
+	if err == nil {
+		t.Fatalf("Expected a short position to fail")
+	}
+}
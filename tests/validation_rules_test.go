@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"errors"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/validation"
+	"io/ioutil"
+	"regexp"
+	"testing"
+)
+
+func TestWOFRuleSetFixture(t *testing.T) {
+
+	body, err := ioutil.ReadFile("../fixtures/101851199.geojson")
+
+	if err != nil {
+		t.Fatalf("Failed to read fixture, %v", err)
+	}
+
+	report := validation.NewWOFRuleSet().Validate(body)
+
+	if !report.Ok() {
+		t.Fatalf("Expected fixture to be valid, %v", report.Err())
+	}
+
+	// edtf:inception is "uuuu" which is from the deprecated 2012 spec
+
+	warnings := report.Warnings()
+
+	if len(warnings) != 1 || warnings[0].Path != "properties.edtf:inception" {
+		t.Fatalf("Expected a single edtf:inception warning, %v", report.Err())
+	}
+}
+
+func TestWOFRuleSet(t *testing.T) {
+
+	body := []byte(`{"type":"Feature","properties":{
+		"wof:id":"1234",
+		"wof:repo":"whosonfirst-data",
+		"wof:placetype":"locality",
+		"wof:country":"usa",
+		"wof:hierarchy":[{"country_id":85633793,"region":85688637}],
+		"geom:bbox":"0,0,0",
+		"name:eng_x_preferred":"Example",
+		"name:fra_x_preferred":["Exemple"],
+		"edtf:inception":"2020-13-45",
+		"edtf:cessation":"..",
+		"mz:is_current":2
+	},"geometry":{"type":"Point","coordinates":[0,0]}}`)
+
+	report := validation.NewWOFRuleSet().Validate(body)
+
+	expected := []string{
+		"properties.wof:id",
+		"properties.wof:country",
+		"properties.wof:hierarchy",
+		"properties.geom:bbox",
+		"properties.name:eng_x_preferred",
+		"properties.edtf:inception",
+		"properties.mz:is_current",
+		"properties.wof:name",
+	}
+
+	errs := report.Errors()
+
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errs), report.Err())
+	}
+
+	for i, path := range expected {
+
+		if errs[i].Path != path {
+			t.Fatalf("Expected error %d to be for %s, got %s", i, path, errs[i].Path)
+		}
+	}
+
+	if !errors.Is(errs[0], geojson.ErrInvalidProperty) {
+		t.Fatalf("Expected invalid property error, got %v", errs[0].Err)
+	}
+
+	if !errors.Is(errs[len(errs)-1], geojson.ErrMissingProperty) {
+		t.Fatalf("Expected missing property error, got %v", errs[len(errs)-1].Err)
+	}
+
+	if len(report.Warnings()) != 0 {
+		t.Fatalf("Unexpected warnings, %v", report.Err())
+	}
+}
+
+func TestWOFRuleSetCountry(t *testing.T) {
+
+	tests := map[string]bool{
+		`"US"`:  true,
+		`""`:    true,
+		`"usa"`: false,
+		`"U"`:   false,
+		`1`:     false,
+	}
+
+	for country, ok := range tests {
+
+		body := []byte(`{"type":"Feature","properties":{"wof:id":1234,"wof:name":"Example","wof:repo":"whosonfirst-data","wof:placetype":"ocean","wof:country":` + country + `},"geometry":{"type":"Point","coordinates":[0,0]}}`)
+
+		report := validation.NewWOFRuleSet().Validate(body)
+
+		if report.Ok() != ok {
+			t.Fatalf("Expected validity of wof:country %s to be %t, %v", country, ok, report.Err())
+		}
+	}
+}
+
+func TestCustomRuleSet(t *testing.T) {
+
+	body := []byte(`{"type":"Feature","properties":{"wof:id":1234,"wof:name":"SFO","wof:repo":"sfomuseum-data-architecture","wof:placetype":"wing","sfomuseum:placetype":"terminal","sfomuseum:building_id":"abc"},"geometry":{"type":"Point","coordinates":[0,0]}}`)
+
+	house := validation.NewRuleSet(
+		&validation.Rule{
+			Name:     "sfomuseum:placetype",
+			Property: "sfomuseum:placetype",
+			Required: true,
+			Check:    validation.IsOneOf("building", "gate", "wing"),
+		},
+		&validation.Rule{
+			Name:     "sfomuseum:*_id",
+			Property: "sfomuseum:*_id",
+			Check:    validation.IsInteger,
+		},
+		&validation.Rule{
+			Name:     "wof:repo",
+			Property: "wof:repo",
+			Severity: validation.SEVERITY_WARNING,
+			Check:    validation.MatchesRegexp(regexp.MustCompile(`^sfomuseum-data-`)),
+		},
+	)
+
+	report := validation.Validate(body, validation.NewWOFRuleSet(), house)
+
+	errs := report.Errors()
+
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 errors, got %d: %v", len(errs), report.Err())
+	}
+
+	if errs[0].Rule != "sfomuseum:placetype" || errs[1].Rule != "sfomuseum:*_id" {
+		t.Fatalf("Unexpected rules %s, %s", errs[0].Rule, errs[1].Rule)
+	}
+
+	if len(report.Warnings()) != 0 {
+		t.Fatalf("Unexpected warnings, %v", report.Err())
+	}
+}
@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"errors"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/feature"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/validation"
+	"io/ioutil"
+	"testing"
+)
+
+func TestValidateWOFFeature(t *testing.T) {
+
+	body := []byte(`{"type":"Feature","properties":{"wof:id":1234,"wof:placetype":"spaceship","geom:latitude":0,"geom:longitude":0},"geometry":{"type":"Point","coordinates":[0,0]}}`)
+
+	report := feature.ValidateWOFFeature(body)
+
+	if report.Ok() {
+		t.Fatalf("Expected report to have errors")
+	}
+
+	expected := []string{
+		"properties.wof:name",
+		"properties.wof:repo",
+		"properties.geom:bbox",
+		"properties.wof:placetype",
+	}
+
+	if len(report.Findings) != len(expected) {
+		t.Fatalf("Expected %d findings, got %d: %v", len(expected), len(report.Findings), report.Err())
+	}
+
+	for i, path := range expected {
+
+		if report.Findings[i].Path != path {
+			t.Fatalf("Expected finding %d to be for %s, got %s", i, path, report.Findings[i].Path)
+		}
+	}
+
+	if len(report.Errors()) != 3 || len(report.Warnings()) != 1 {
+		t.Fatalf("Unexpected number of errors (%d) or warnings (%d)", len(report.Errors()), len(report.Warnings()))
+	}
+
+	if report.Warnings()[0].Severity != validation.SEVERITY_WARNING {
+		t.Fatalf("Invalid severity for placetype warning")
+	}
+
+	if !errors.Is(report.Warnings()[0], geojson.ErrInvalidPlacetype) {
+		t.Fatalf("Expected placetype finding to wrap ErrInvalidPlacetype")
+	}
+
+	err := feature.EnsureWOFFeature(body)
+
+	var prop_err *geojson.MissingPropertyError
+
+	if !errors.As(err, &prop_err) || prop_err.Path != "properties.wof:name" {
+		t.Fatalf("Expected EnsureWOFFeature to return the first missing property, got %v", err)
+	}
+
+	if report.Err() == nil {
+		t.Fatalf("Expected a combined error")
+	}
+}
+
+func TestValidateWOFFeatureOk(t *testing.T) {
+
+	body, err := ioutil.ReadFile("../fixtures/101851199.geojson")
+
+	if err != nil {
+		t.Fatalf("Failed to read fixture, %v", err)
+	}
+
+	report := feature.ValidateWOFFeature(body)
+
+	if len(report.Findings) != 0 {
+		t.Fatalf("Expected no findings, got %v", report.Err())
+	}
+
+	if report.Err() != nil || feature.EnsureWOFFeature(body) != nil {
+		t.Fatalf("Expected nil errors for a valid feature")
+	}
+}
+
+func TestValidateWOFFeatureSPRGeometry(t *testing.T) {
+
+	// geom:longitude is missing but its SPR equivalent, mz:longitude, isn't
+
+	body := []byte(`{"type":"Feature","properties":{"wof:id":1234,"wof:name":"test","wof:repo":"whosonfirst-data","wof:placetype":"locality","geom:latitude":0,"mz:longitude":0,"geom:bbox":"0,0,0,0"},"geometry":{"type":"Point","coordinates":[0,0]}}`)
+
+	report := feature.ValidateWOFFeature(body)
+
+	if len(report.Findings) != 0 {
+		t.Fatalf("Expected no findings, got %v", report.Err())
+	}
+
+	// mz:latitude is not a substitute for geom:longitude
+
+	body = []byte(`{"type":"Feature","properties":{"wof:id":1234,"wof:name":"test","wof:repo":"whosonfirst-data","wof:placetype":"locality","geom:latitude":0,"mz:latitude":0,"geom:bbox":"0,0,0,0"},"geometry":{"type":"Point","coordinates":[0,0]}}`)
+
+	report = feature.ValidateWOFFeature(body)
+
+	if len(report.Findings) != 1 || report.Findings[0].Path != "properties.geom:longitude" {
+		t.Fatalf("Expected a single finding for properties.geom:longitude, got %v", report.Err())
+	}
+}
+
+func TestValidateWOFAltFeature(t *testing.T) {
+
+	body := []byte(`{"type":"Feature","properties":{"wof:id":1234},"geometry":{"type":"Point","coordinates":[0,0]}}`)
+
+	report := feature.ValidateWOFAltFeature(body)
+
+	if len(report.Errors()) != 2 {
+		t.Fatalf("Expected 2 errors, got %d", len(report.Errors()))
+	}
+
+	err := feature.EnsureWOFAltFeature(body)
+
+	if !errors.Is(err, geojson.ErrMissingProperty) {
+		t.Fatalf("Expected missing property error, got %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/geometry"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/validation"
+	"testing"
+)
+
+func validityReport(geom string) *validation.Report {
+
+	body := fmt.Sprintf(`{"type":"Feature","id":"test","properties":{},"geometry":%s}`, geom)
+	return geometry.ValidateGeometry([]byte(body))
+}
+
+func TestValidateGeometryValid(t *testing.T) {
+
+	tests := []string{
+		`null`,
+		`{"type":"Point","coordinates":[-122.4,37.7]}`,
+		`{"type":"LineString","coordinates":[[0,0],[1,1]]}`,
+		relateSquare(0, 0, 10, 10),
+		`{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[2,2],[2,8],[8,8],[8,2],[2,2]]]}`,
+		`{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[0,0],[2,8],[8,8],[0,0]]]}`,
+		`{"type":"Polygon","coordinates":[[[170,60],[-170,60],[-170,70],[170,70],[170,60]],[[178,64],[178,66],[-178,66],[-178,64],[178,64]]]}`,
+		`{"type":"MultiPolygon","coordinates":[[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[2,2],[2,8],[8,8],[8,2],[2,2]]],[[[4,4],[6,4],[6,6],[4,6],[4,4]]]]}`,
+		`{"type":"MultiPolygon","coordinates":[[[[0,0],[10,0],[10,10],[0,10],[0,0]]],[[[10,10],[20,10],[20,20],[10,20],[10,10]]]]}`,
+		`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[0,0]}]}`,
+	}
+
+	for _, geom := range tests {
+
+		report := validityReport(geom)
+
+		if len(report.Findings) != 0 {
+			t.Fatalf("Expected %s to be valid, got %v", geom, report.Err())
+		}
+	}
+}
+
+func TestValidateGeometryInvalid(t *testing.T) {
+
+	tests := []struct {
+		Geometry string
+		Rule     string
+		Path     string
+		Part     int
+		Ring     int
+		Vertex   int
+		Warning  bool
+	}{
+		{`{"type":"Spaceship","coordinates":[0,0]}`, geometry.VALIDITY_TYPE, "geometry.type", -1, -1, -1, false},
+		{`{"type":"Point","coordinates":[0]}`, geometry.VALIDITY_POSITION, "geometry.coordinates", -1, -1, -1, false},
+		{`{"type":"Point","coordinates":[0,"zero"]}`, geometry.VALIDITY_POSITION, "geometry.coordinates", -1, -1, -1, false},
+		{`{"type":"Point","coordinates":[0,91]}`, geometry.VALIDITY_RANGE, "geometry.coordinates", -1, -1, -1, false},
+		{`{"type":"MultiPoint","coordinates":[[0,0],[181,0]]}`, geometry.VALIDITY_RANGE, "geometry.coordinates.1", -1, -1, 1, false},
+		{`{"type":"LineString","coordinates":[[0,0]]}`, geometry.VALIDITY_LINE_LENGTH, "geometry.coordinates", -1, -1, -1, false},
+		{`{"type":"Polygon","coordinates":[[[0,0],[1,0],[0,0]]]}`, geometry.VALIDITY_RING_LENGTH, "geometry.coordinates.0", -1, 0, -1, false},
+		{`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1]]]}`, geometry.VALIDITY_RING_CLOSED, "geometry.coordinates.0.3", -1, 0, 3, false},
+		{`{"type":"Polygon","coordinates":[[[0,0],[10,10],[10,0],[0,10],[0,0]]]}`, geometry.VALIDITY_SELF_INTERSECTION, "geometry.coordinates.0.2", -1, 0, 2, false},
+		{`{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[0,2],[0,8],[5,8],[5,2],[0,2]]]}`, geometry.VALIDITY_RING_INTERSECTION, "geometry.coordinates.1.0", -1, 1, 0, false},
+		{`{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[20,20],[20,25],[25,25],[25,20],[20,20]]]}`, geometry.VALIDITY_HOLE_OUTSIDE_SHELL, "geometry.coordinates.1.0", -1, 1, 0, false},
+		{`{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[2,2],[2,8],[8,8],[8,2],[2,2]],[[4,4],[4,6],[6,6],[6,4],[4,4]]]}`, geometry.VALIDITY_NESTED_HOLE, "geometry.coordinates.2", -1, 2, -1, false},
+		{`{"type":"MultiPolygon","coordinates":[[[[0,0],[10,0],[10,10],[0,10],[0,0]]],[[[5,5],[15,5],[15,15],[5,15],[5,5]]]]}`, geometry.VALIDITY_OVERLAPPING_PARTS, "geometry.coordinates.1", 1, -1, -1, false},
+		{`{"type":"MultiPolygon","coordinates":[[[[0,0],[10,0],[10,10],[0,10],[0,0]]],[[[2,2],[4,2],[4,4],[2,4],[2,2]]]]}`, geometry.VALIDITY_OVERLAPPING_PARTS, "geometry.coordinates.1", 1, -1, -1, false},
+		{`{"type":"MultiPolygon","coordinates":[[[[170,60],[-170,60],[-170,70],[170,70],[170,60]]],[[[-175,62],[-160,62],[-160,64],[-175,64],[-175,62]]]]}`, geometry.VALIDITY_OVERLAPPING_PARTS, "geometry.coordinates.1", 1, -1, -1, false},
+		{`{"type":"Polygon","coordinates":[[[0,0],[0,10],[10,10],[10,0],[0,0]]]}`, geometry.VALIDITY_WINDING_ORDER, "geometry.coordinates.0", -1, 0, -1, true},
+		{`{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,1],[0,0]]],[[[5,5],[6,5],[6,6],[5,6]]]]}`, geometry.VALIDITY_RING_CLOSED, "geometry.coordinates.1.0.3", 1, 0, 3, false},
+		{`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[0,0]},{"type":"LineString","coordinates":[[0,0]]}]}`, geometry.VALIDITY_LINE_LENGTH, "geometry.geometries.1.coordinates", -1, -1, -1, false},
+	}
+
+	for _, test := range tests {
+
+		report := validityReport(test.Geometry)
+
+		if len(report.Findings) != 1 {
+			t.Fatalf("Expected a single finding for %s, got %v", test.Geometry, report.Err())
+		}
+
+		f := report.Findings[0]
+
+		if f.Rule != test.Rule || f.Path != test.Path {
+			t.Fatalf("Unexpected finding for %s: %s %s", test.Geometry, f.Rule, f.Path)
+		}
+
+		if (f.Severity == validation.SEVERITY_WARNING) != test.Warning || geojson.IsWarning(f.Err) != test.Warning {
+			t.Fatalf("Unexpected severity for %s: %s", test.Geometry, f.Severity)
+		}
+
+		var v_err *geometry.ValidityError
+
+		if !errors.As(f.Err, &v_err) {
+			t.Fatalf("Expected a ValidityError for %s, got %T", test.Geometry, f.Err)
+		}
+
+		if v_err.Part != test.Part || v_err.Ring != test.Ring || v_err.Vertex != test.Vertex {
+			t.Fatalf("Unexpected location for %s: %v", test.Geometry, v_err)
+		}
+
+		if v_err.FeatureId != "test" {
+			t.Fatalf("Unexpected feature ID '%s'", v_err.FeatureId)
+		}
+
+		if !errors.Is(f.Err, geojson.ErrInvalidGeometry) {
+			t.Fatalf("Expected finding for %s to be ErrInvalidGeometry", test.Geometry)
+		}
+	}
+}
+
+func TestValidateGeometryReportsEverything(t *testing.T) {
+
+	report := validityReport(`{"type":"MultiPolygon","coordinates":[[[[0,0],[0,1],[1,1],[1,0],[0,0]]],[[[0,95],[1,0],[1,1]]]]}`)
+
+	if len(report.Errors()) != 2 || len(report.Warnings()) != 1 {
+		t.Fatalf("Unexpected findings %v", report.Err())
+	}
+}
@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/walk"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func newTestRepo(t *testing.T) string {
+
+	root, err := ioutil.TempDir("", "walk")
+
+	if err != nil {
+		t.Fatalf("Failed to create temporary directory, %v", err)
+	}
+
+	data := filepath.Join(root, "data", "101", "851", "199")
+
+	err = os.MkdirAll(data, 0755)
+
+	if err != nil {
+		t.Fatalf("Failed to create data directory, %v", err)
+	}
+
+	files := []string{
+		"101851199.geojson",
+		"101851199-alt-quattroshapes.geojson",
+	}
+
+	for _, fname := range files {
+
+		body, err := ioutil.ReadFile(filepath.Join("../fixtures", fname))
+
+		if err != nil {
+			t.Fatalf("Failed to read %s, %v", fname, err)
+		}
+
+		err = ioutil.WriteFile(filepath.Join(data, fname), body, 0644)
+
+		if err != nil {
+			t.Fatalf("Failed to write %s, %v", fname, err)
+		}
+	}
+
+	err = ioutil.WriteFile(filepath.Join(data, "README.md"), []byte("not a feature"), 0644)
+
+	if err != nil {
+		t.Fatalf("Failed to write README, %v", err)
+	}
+
+	return root
+}
+
+type walkCounter struct {
+	mu   sync.Mutex
+	ids  []string
+	alts []string
+}
+
+func (c *walkCounter) options(filters *walk.Filters) *walk.WalkOptions {
+
+	opts := &walk.WalkOptions{
+		Workers: 2,
+		Filters: filters,
+		FeatureFunc: func(ctx context.Context, path string, f geojson.Feature) error {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.ids = append(c.ids, f.Id())
+			return nil
+		},
+		AltFeatureFunc: func(ctx context.Context, path string, f geojson.Feature) error {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.alts = append(c.alts, f.Id())
+			return nil
+		},
+	}
+
+	return opts
+}
+
+func TestWalkRepo(t *testing.T) {
+
+	root := newTestRepo(t)
+	defer os.RemoveAll(root)
+
+	ctx := context.Background()
+
+	tests := []struct {
+		filters *walk.Filters
+		ids     int
+		alts    int
+	}{
+		{nil, 1, 1},
+		{&walk.Filters{IncludePlacetypes: []string{"locality"}}, 1, 1},
+		{&walk.Filters{ExcludePlacetypes: []string{"locality"}}, 0, 1},
+		{&walk.Filters{ExcludeAltLabels: []string{"quattroshapes"}}, 1, 0},
+		{&walk.Filters{IncludeAltLabels: []string{"quattroshapes"}}, 1, 1},
+		{&walk.Filters{IncludeExistential: []string{"is_current"}}, 0, 1},
+		{&walk.Filters{ExcludeExistential: []string{"is_superseded"}}, 0, 1},
+		{&walk.Filters{IncludeExistential: []string{"is_superseded", "is_ceased"}}, 1, 1},
+	}
+
+	for i, test := range tests {
+
+		c := new(walkCounter)
+		err := walk.WalkRepo(ctx, root, c.options(test.filters))
+
+		if err != nil {
+			t.Fatalf("Failed to walk repo (test %d), %v", i, err)
+		}
+
+		if len(c.ids) != test.ids || len(c.alts) != test.alts {
+			t.Fatalf("Test %d expected %d features and %d alts, got %d and %d", i, test.ids, test.alts, len(c.ids), len(c.alts))
+		}
+	}
+}
+
+func TestWalkRepoErrors(t *testing.T) {
+
+	root := newTestRepo(t)
+	defer os.RemoveAll(root)
+
+	ctx := context.Background()
+
+	expected := errors.New("stop")
+
+	opts := &walk.WalkOptions{
+		FeatureFunc: func(ctx context.Context, path string, f geojson.Feature) error {
+			return expected
+		},
+	}
+
+	err := walk.WalkRepo(ctx, root, opts)
+
+	if err != expected {
+		t.Fatalf("Expected walk to return error from FeatureFunc, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	c := new(walkCounter)
+	err = walk.WalkRepo(ctx, root, c.options(nil))
+
+	if err == nil {
+		t.Fatalf("Expected cancelled walk to fail")
+	}
+
+	err = walk.WalkRepo(context.Background(), filepath.Join(root, "data"), c.options(nil))
+
+	if err == nil {
+		t.Fatalf("Expected walk of a directory without a data folder to fail")
+	}
+}
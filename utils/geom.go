@@ -2,6 +2,7 @@ package utils
 
 import (
 	"github.com/skelterjohn/geom"
+	"math"
 )
 
 func NewCoordinateFromLatLons(lat float64, lon float64) (geom.Coord, error) {
@@ -49,3 +50,64 @@ func NewPolygonFromCoords(coords []geom.Coord) (geom.Polygon, error) {
 
 	return *poly, nil
 }
+
+// RectCrossesAntimeridian reports whether r crosses the antimeridian. Following
+// RFC 7946 (section 5.2) such rectangles have a western edge (Min.X) that is
+// greater than their eastern edge (Max.X).
+
+func RectCrossesAntimeridian(r geom.Rect) bool {
+	return r.Min.X > r.Max.X
+}
+
+// RectCenter returns the center of r, accounting for rectangles that cross the
+// antimeridian.
+
+func RectCenter(r geom.Rect) geom.Coord {
+
+	lat := r.Min.Y + ((r.Max.Y - r.Min.Y) / 2.0)
+
+	if !RectCrossesAntimeridian(r) {
+		lon := r.Min.X + ((r.Max.X - r.Min.X) / 2.0)
+		return geom.Coord{X: lon, Y: lat}
+	}
+
+	width := (r.Max.X + 360.0) - r.Min.X
+	lon := NormalizeLongitude(r.Min.X + (width / 2.0))
+
+	return geom.Coord{X: lon, Y: lat}
+}
+
+// RectContainsCoord reports whether c is inside r, accounting for rectangles
+// that cross the antimeridian.
+
+func RectContainsCoord(r geom.Rect, c geom.Coord) bool {
+
+	if c.Y < r.Min.Y || c.Y > r.Max.Y {
+		return false
+	}
+
+	if !RectCrossesAntimeridian(r) {
+		return c.X >= r.Min.X && c.X <= r.Max.X
+	}
+
+	return c.X >= r.Min.X || c.X <= r.Max.X
+}
+
+// NormalizeLongitude returns lon wrapped in to the range -180 to 180.
+
+func NormalizeLongitude(lon float64) float64 {
+
+	if math.IsInf(lon, 0) || math.IsNaN(lon) {
+		return lon
+	}
+
+	for lon > 180.0 {
+		lon -= 360.0
+	}
+
+	for lon < -180.0 {
+		lon += 360.0
+	}
+
+	return lon
+}
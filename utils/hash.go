@@ -18,7 +18,7 @@ func GeohashFeature(f geojson.Feature) (string, error) {
 	}
 
 	mbr := bboxes.MBR()
-	center := mbr.Center()
+	center := RectCenter(mbr)
 
 	lat := center.Y
 	lon := center.X
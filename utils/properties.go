@@ -1,9 +1,11 @@
 package utils
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/tidwall/gjson"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
 	"strings"
 )
 
@@ -31,14 +33,59 @@ func EnsureProperties(body []byte, properties []string) error {
 		r := gjson.GetBytes(body, path)
 
 		if !r.Exists() {
-			msg := fmt.Sprintf("Feature is missing a %s property", path)
-			return errors.New(msg)
+
+			err := &geojson.MissingPropertyError{
+				Path:      path,
+				FeatureId: FeatureId(body),
+			}
+
+			return err
 		}
 	}
 
 	return nil
 }
 
+func EnsureJSON(body []byte) error {
+
+	var stub interface{}
+	err := json.Unmarshal(body, &stub)
+
+	if err == nil {
+		return nil
+	}
+
+	offset := int64(-1)
+
+	syntax_err, ok := err.(*json.SyntaxError)
+
+	if ok {
+		offset = syntax_err.Offset
+	}
+
+	json_err := &geojson.InvalidJSONError{
+		Offset:    offset,
+		FeatureId: FeatureId(body),
+		Err:       err,
+	}
+
+	return json_err
+}
+
+// FeatureId returns the (string) value of the first of the wof:id or top-level
+// id properties found in body, or an empty string. It is used to label errors
+// so it doesn't care whether body is a valid feature or not.
+
+func FeatureId(body []byte) string {
+
+	possible := []string{
+		"properties.wof:id",
+		"id",
+	}
+
+	return StringProperty(body, possible, "")
+}
+
 func Int64Property(body []byte, possible []string, d int64) int64 {
 
 	for _, path := range possible {
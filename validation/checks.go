@@ -0,0 +1,260 @@
+package validation
+
+import (
+	"fmt"
+	"github.com/sfomuseum/go-edtf"
+	"github.com/sfomuseum/go-edtf/parser"
+	"github.com/tidwall/gjson"
+	"github.com/whosonfirst/warning"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var re_country = regexp.MustCompile(`^[A-Z]{2}$`)
+
+func IsString(value gjson.Result) error {
+
+	if value.Type != gjson.String {
+		return fmt.Errorf("Expected a string, got %s", value.Type)
+	}
+
+	return nil
+}
+
+func IsNumber(value gjson.Result) error {
+
+	if value.Type != gjson.Number {
+		return fmt.Errorf("Expected a number, got %s", value.Type)
+	}
+
+	return nil
+}
+
+func IsInteger(value gjson.Result) error {
+
+	if value.Type != gjson.Number {
+		return fmt.Errorf("Expected an integer, got %s", value.Type)
+	}
+
+	_, err := strconv.ParseInt(value.Raw, 10, 64)
+
+	if err != nil {
+		return fmt.Errorf("Expected an integer, got %s", value.Raw)
+	}
+
+	return nil
+}
+
+func IsStringArray(value gjson.Result) error {
+	return isArrayOf(value, IsString)
+}
+
+func IsIntegerArray(value gjson.Result) error {
+	return isArrayOf(value, IsInteger)
+}
+
+// IsBBoxString checks for a "minx,miny,maxx,maxy" string, as used by geom:bbox.
+
+func IsBBoxString(value gjson.Result) error {
+
+	err := IsString(value)
+
+	if err != nil {
+		return err
+	}
+
+	parts := strings.Split(value.String(), ",")
+
+	if len(parts) != 4 {
+		return fmt.Errorf("Expected four comma-separated numbers, got %d values", len(parts))
+	}
+
+	for _, str_coord := range parts {
+
+		_, err := strconv.ParseFloat(strings.TrimSpace(str_coord), 64)
+
+		if err != nil {
+			return fmt.Errorf("Invalid bounding box coordinate '%s'", str_coord)
+		}
+	}
+
+	return nil
+}
+
+// IsEDTFString checks that value is a string that go-edtf can parse. Strings
+// from the pre-2019 EDTF spec (like "uuuu") are reported as warnings.
+
+func IsEDTFString(value gjson.Result) error {
+
+	err := IsString(value)
+
+	if err != nil {
+		return err
+	}
+
+	str_edtf := value.String()
+
+	switch str_edtf {
+	case edtf.OPEN_2012, edtf.UNSPECIFIED_2012:
+		return warning.New(fmt.Sprintf("Deprecated EDTF string '%s'", str_edtf))
+	default:
+		// pass
+	}
+
+	_, err = parser.ParseString(str_edtf)
+
+	if err != nil {
+		return fmt.Errorf("Invalid EDTF string '%s', %v", str_edtf, err)
+	}
+
+	return nil
+}
+
+// IsExistentialFlag checks for one of -1 (unknown), 0 (false) or 1 (true).
+
+func IsExistentialFlag(value gjson.Result) error {
+
+	err := IsInteger(value)
+
+	if err != nil {
+		return err
+	}
+
+	switch value.Int() {
+	case -1, 0, 1:
+		return nil
+	default:
+		return fmt.Errorf("Expected -1, 0 or 1, got %d", value.Int())
+	}
+}
+
+// IsCountryCode checks for a two letter, upper case, country code or an empty
+// string, which Who's On First uses for features that aren't in any country
+// (like oceans).
+
+func IsCountryCode(value gjson.Result) error {
+
+	err := IsString(value)
+
+	if err != nil {
+		return err
+	}
+
+	if value.String() == "" {
+		return nil
+	}
+
+	if !re_country.MatchString(value.String()) {
+		return fmt.Errorf("Expected a two letter country code, got '%s'", value.String())
+	}
+
+	return nil
+}
+
+// IsHierarchy checks for an array of objects whose keys all end in "_id" and
+// whose values are all integers, as used by wof:hierarchy.
+
+func IsHierarchy(value gjson.Result) error {
+
+	if !value.IsArray() {
+		return fmt.Errorf("Expected an array, got %s", value.Type)
+	}
+
+	var err error
+
+	for i, h := range value.Array() {
+
+		if !h.IsObject() {
+			return fmt.Errorf("Expected hierarchy %d to be an object", i)
+		}
+
+		h.ForEach(func(k gjson.Result, v gjson.Result) bool {
+
+			key := k.String()
+
+			if !strings.HasSuffix(key, "_id") {
+				err = fmt.Errorf("Invalid key '%s' in hierarchy %d, keys must end in _id", key, i)
+				return false
+			}
+
+			check_err := IsInteger(v)
+
+			if check_err != nil {
+				err = fmt.Errorf("Invalid %s in hierarchy %d, %v", key, i, check_err)
+				return false
+			}
+
+			return true
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IsOneOf returns a CheckFunc that tests whether value is one of a fixed set
+// of strings.
+
+func IsOneOf(values ...string) CheckFunc {
+
+	return func(value gjson.Result) error {
+
+		err := IsString(value)
+
+		if err != nil {
+			return err
+		}
+
+		for _, v := range values {
+
+			if value.String() == v {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("Unexpected value '%s'", value.String())
+	}
+}
+
+// MatchesRegexp returns a CheckFunc that tests whether value is a string
+// matching re.
+
+func MatchesRegexp(re *regexp.Regexp) CheckFunc {
+
+	return func(value gjson.Result) error {
+
+		err := IsString(value)
+
+		if err != nil {
+			return err
+		}
+
+		if !re.MatchString(value.String()) {
+			return fmt.Errorf("'%s' does not match %s", value.String(), re.String())
+		}
+
+		return nil
+	}
+}
+
+func isArrayOf(value gjson.Result, check CheckFunc) error {
+
+	if !value.IsArray() {
+		return fmt.Errorf("Expected an array, got %s", value.Type)
+	}
+
+	for i, v := range value.Array() {
+
+		err := check(v)
+
+		if err != nil {
+			return fmt.Errorf("Invalid item %d, %v", i, err)
+		}
+	}
+
+	return nil
+}
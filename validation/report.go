@@ -0,0 +1,159 @@
+package validation
+
+import (
+	"fmt"
+	"github.com/hashicorp/go-multierror"
+)
+
+type Severity int
+
+const (
+	SEVERITY_ERROR Severity = iota
+	SEVERITY_WARNING
+)
+
+func (s Severity) String() string {
+
+	switch s {
+	case SEVERITY_ERROR:
+		return "error"
+	case SEVERITY_WARNING:
+		return "warning"
+	default:
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+}
+
+// Finding is a single problem with a document. Path is the (gjson) path of the
+// property the finding is about, Rule is the name of the rule that produced it
+// (if any) and Err is the error that would have been returned had validation
+// stopped there.
+
+type Finding struct {
+	Severity Severity
+	Path     string
+	Rule     string
+	Err      error
+}
+
+func (f *Finding) Error() string {
+	return fmt.Sprintf("%s: %v", f.Severity, f.Err)
+}
+
+func (f *Finding) Unwrap() error {
+	return f.Err
+}
+
+// Report collects every finding for a document, in the order they were
+// recorded, rather than stopping at the first one.
+
+type Report struct {
+	Findings []*Finding
+}
+
+func NewReport() *Report {
+
+	r := &Report{
+		Findings: make([]*Finding, 0),
+	}
+
+	return r
+}
+
+func (r *Report) AddError(path string, err error) {
+	r.add(SEVERITY_ERROR, path, err)
+}
+
+func (r *Report) AddWarning(path string, err error) {
+	r.add(SEVERITY_WARNING, path, err)
+}
+
+func (r *Report) Errors() []*Finding {
+	return r.filter(SEVERITY_ERROR)
+}
+
+func (r *Report) Warnings() []*Finding {
+	return r.filter(SEVERITY_WARNING)
+}
+
+func (r *Report) HasErrors() bool {
+	return len(r.Errors()) > 0
+}
+
+func (r *Report) HasWarnings() bool {
+	return len(r.Warnings()) > 0
+}
+
+// Ok reports whether the document has no findings whose severity is error.
+// Warnings do not make a document invalid.
+
+func (r *Report) Ok() bool {
+	return !r.HasErrors()
+}
+
+// First returns the underlying error of the first finding whose severity is
+// error or, if there are none, of the first warning. It returns nil if the
+// report is empty. This is what the "stop at the first problem" functions, like
+// feature.EnsureWOFFeature, return.
+
+func (r *Report) First() error {
+
+	errs := r.Errors()
+
+	if len(errs) > 0 {
+		return errs[0].Err
+	}
+
+	warnings := r.Warnings()
+
+	if len(warnings) > 0 {
+		return warnings[0].Err
+	}
+
+	return nil
+}
+
+// Err returns all the findings, errors and warnings alike, as a
+// *multierror.Error or nil if the report is empty.
+
+func (r *Report) Err() error {
+
+	var result *multierror.Error
+
+	for _, f := range r.Findings {
+		result = multierror.Append(result, f)
+	}
+
+	return result.ErrorOrNil()
+}
+
+// AddFinding appends f to the report as-is.
+
+func (r *Report) AddFinding(f *Finding) {
+	r.Findings = append(r.Findings, f)
+}
+
+func (r *Report) add(severity Severity, path string, err error) {
+
+	f := &Finding{
+		Severity: severity,
+		Path:     path,
+		Err:      err,
+	}
+
+	r.AddFinding(f)
+}
+
+func (r *Report) filter(severity Severity) []*Finding {
+
+	findings := make([]*Finding, 0)
+
+	for _, f := range r.Findings {
+
+		if f.Severity == severity {
+			findings = append(findings, f)
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,186 @@
+package validation
+
+import (
+	"github.com/tidwall/gjson"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/utils"
+	"path"
+	"strings"
+)
+
+// CheckFunc tests the value of a single property. Returning an error for
+// which geojson.IsWarning is true records a warning instead of an error.
+
+type CheckFunc func(value gjson.Result) error
+
+// Rule applies Check to every property whose name matches Property, which may
+// be a literal name like "wof:id" or a path.Match pattern like "name:*". If
+// Required is true and Property is a literal name then its absence is also
+// recorded as an error.
+
+type Rule struct {
+	Name     string
+	Property string
+	Required bool
+	Severity Severity
+	Check    CheckFunc
+}
+
+type RuleSet struct {
+	rules []*Rule
+}
+
+func NewRuleSet(rules ...*Rule) *RuleSet {
+
+	rs := &RuleSet{
+		rules: make([]*Rule, 0),
+	}
+
+	for _, r := range rules {
+		rs.AddRule(r)
+	}
+
+	return rs
+}
+
+func (rs *RuleSet) AddRule(r *Rule) {
+	rs.rules = append(rs.rules, r)
+}
+
+func (rs *RuleSet) Rules() []*Rule {
+	return rs.rules
+}
+
+// Validate applies rs to body and returns a new report.
+
+func (rs *RuleSet) Validate(body []byte) *Report {
+
+	report := NewReport()
+	rs.ValidateWithReport(body, report)
+
+	return report
+}
+
+// ValidateWithReport applies rs to body, appending any findings to report.
+// Findings are recorded in the order properties appear in body.
+
+func (rs *RuleSet) ValidateWithReport(body []byte, report *Report) {
+
+	feature_id := utils.FeatureId(body)
+	seen := make(map[*Rule]bool)
+
+	props := gjson.GetBytes(body, "properties")
+
+	props.ForEach(func(k gjson.Result, v gjson.Result) bool {
+
+		key := k.String()
+		prop_path := "properties." + escapePath(key)
+
+		for _, r := range rs.rules {
+
+			if !r.matches(key) {
+				continue
+			}
+
+			seen[r] = true
+
+			if r.Check == nil {
+				continue
+			}
+
+			err := r.Check(v)
+
+			if err == nil {
+				continue
+			}
+
+			severity := r.Severity
+
+			if geojson.IsWarning(err) {
+				severity = SEVERITY_WARNING
+			}
+
+			prop_err := &geojson.InvalidPropertyError{
+				Path:      prop_path,
+				FeatureId: feature_id,
+				Err:       err,
+			}
+
+			f := &Finding{
+				Severity: severity,
+				Path:     prop_path,
+				Rule:     r.Name,
+				Err:      prop_err,
+			}
+
+			report.AddFinding(f)
+		}
+
+		return true
+	})
+
+	for _, r := range rs.rules {
+
+		if !r.Required || seen[r] || isPattern(r.Property) {
+			continue
+		}
+
+		prop_path := "properties." + escapePath(r.Property)
+
+		prop_err := &geojson.MissingPropertyError{
+			Path:      prop_path,
+			FeatureId: feature_id,
+		}
+
+		f := &Finding{
+			Severity: SEVERITY_ERROR,
+			Path:     prop_path,
+			Rule:     r.Name,
+			Err:      prop_err,
+		}
+
+		report.AddFinding(f)
+	}
+}
+
+// Validate applies each rule set in turn to body and returns a single report
+// with all of their findings.
+
+func Validate(body []byte, sets ...*RuleSet) *Report {
+
+	report := NewReport()
+
+	for _, rs := range sets {
+		rs.ValidateWithReport(body, report)
+	}
+
+	return report
+}
+
+func (r *Rule) matches(key string) bool {
+
+	if !isPattern(r.Property) {
+		return key == r.Property
+	}
+
+	ok, err := path.Match(r.Property, key)
+	return err == nil && ok
+}
+
+func isPattern(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+// escapePath escapes the characters that gjson treats specially so that a
+// property name can be used as a single path component.
+
+func escapePath(key string) string {
+
+	replacer := strings.NewReplacer(
+		".", "\\.",
+		"*", "\\*",
+		"?", "\\?",
+	)
+
+	return replacer.Replace(key)
+}
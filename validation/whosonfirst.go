@@ -0,0 +1,30 @@
+package validation
+
+// NewWOFRuleSet returns the rules for the core Who's On First property
+// conventions. Each call returns a new RuleSet so callers are free to add
+// their own rules to it.
+
+func NewWOFRuleSet() *RuleSet {
+
+	rules := []*Rule{
+		&Rule{Name: "wof:id", Property: "wof:id", Required: true, Check: IsInteger},
+		&Rule{Name: "wof:parent_id", Property: "wof:parent_id", Check: IsInteger},
+		&Rule{Name: "wof:name", Property: "wof:name", Required: true, Check: IsString},
+		&Rule{Name: "wof:placetype", Property: "wof:placetype", Required: true, Check: IsString},
+		&Rule{Name: "wof:repo", Property: "wof:repo", Required: true, Check: IsString},
+		&Rule{Name: "wof:country", Property: "wof:country", Check: IsCountryCode},
+		&Rule{Name: "wof:hierarchy", Property: "wof:hierarchy", Check: IsHierarchy},
+		&Rule{Name: "wof:belongsto", Property: "wof:belongsto", Check: IsIntegerArray},
+		&Rule{Name: "wof:supersedes", Property: "wof:supersedes", Check: IsIntegerArray},
+		&Rule{Name: "wof:superseded_by", Property: "wof:superseded_by", Check: IsIntegerArray},
+		&Rule{Name: "wof:lastmodified", Property: "wof:lastmodified", Check: IsInteger},
+		&Rule{Name: "geom:bbox", Property: "geom:bbox", Check: IsBBoxString},
+		&Rule{Name: "geom:latitude", Property: "geom:latitude", Check: IsNumber},
+		&Rule{Name: "geom:longitude", Property: "geom:longitude", Check: IsNumber},
+		&Rule{Name: "name:*", Property: "name:*", Check: IsStringArray},
+		&Rule{Name: "edtf:*", Property: "edtf:*", Check: IsEDTFString},
+		&Rule{Name: "mz:is_*", Property: "mz:is_*", Check: IsExistentialFlag},
+	}
+
+	return NewRuleSet(rules...)
+}
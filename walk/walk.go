@@ -0,0 +1,327 @@
+package walk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/whosonfirst/go-whosonfirst-flags"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/feature"
+	"github.com/whosonfirst/go-whosonfirst-geojson-v2/properties/whosonfirst"
+	"github.com/whosonfirst/go-whosonfirst-uri"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+type WalkFunc func(ctx context.Context, path string, f geojson.Feature) error
+
+type WalkOptions struct {
+	// The number of features to load concurrently. Defaults to runtime.NumCPU()
+	Workers int
+	// Optional filters to apply to features before they are handed to FeatureFunc or AltFeatureFunc
+	Filters *Filters
+	// The function to invoke for every primary feature
+	FeatureFunc WalkFunc
+	// The function to invoke for every alternate geometry feature. If nil alternate geometry files are skipped.
+	AltFeatureFunc WalkFunc
+}
+
+// Filters are applied in two stages. Alt label filters are tested against the
+// file name, before a file is loaded, and only apply to alternate geometries.
+// Placetype and existential filters are tested against the loaded feature and
+// only apply to primary features.
+
+type Filters struct {
+	IncludePlacetypes []string
+	ExcludePlacetypes []string
+	IncludeAltLabels  []string
+	ExcludeAltLabels  []string
+	// Existential flags ("is_current", "is_ceased", "is_deprecated", "is_superseded", "is_superseding") that must all be true
+	IncludeExistential []string
+	// Existential flags that, if any are true, will cause a feature to be skipped
+	ExcludeExistential []string
+}
+
+type walkTask struct {
+	path   string
+	is_alt bool
+}
+
+// WalkRepo walks the "data" directory of a whosonfirst-data style repository
+// and loads every Who's On First file it finds. Walking stops, and the first
+// error is returned, if the context is cancelled or a WalkFunc returns an error.
+
+func WalkRepo(ctx context.Context, root string, opts *WalkOptions) error {
+
+	data := filepath.Join(root, "data")
+
+	info, err := os.Stat(data)
+
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", data)
+	}
+
+	return WalkDirectory(ctx, data, opts)
+}
+
+// WalkDirectory is like WalkRepo but walks an arbitrary directory.
+
+func WalkDirectory(ctx context.Context, root string, opts *WalkOptions) error {
+
+	if opts.FeatureFunc == nil && opts.AltFeatureFunc == nil {
+		return errors.New("Nothing to do, both FeatureFunc and AltFeatureFunc are nil")
+	}
+
+	workers := opts.Workers
+
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var walk_err error
+	err_once := new(sync.Once)
+
+	on_error := func(err error) {
+
+		err_once.Do(func() {
+			walk_err = err
+			cancel()
+		})
+	}
+
+	tasks := make(chan *walkTask)
+	wg := new(sync.WaitGroup)
+
+	for i := 0; i < workers; i++ {
+
+		wg.Add(1)
+
+		go func() {
+
+			defer wg.Done()
+
+			for t := range tasks {
+
+				if ctx.Err() != nil {
+					continue
+				}
+
+				err := walkFile(ctx, t, opts)
+
+				if err != nil {
+					on_error(err)
+				}
+			}
+		}()
+	}
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+
+		if err != nil {
+			return err
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		t, err := newWalkTask(path, opts)
+
+		if err != nil {
+			return err
+		}
+
+		if t == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case tasks <- t:
+			return nil
+		}
+	})
+
+	close(tasks)
+	wg.Wait()
+
+	if walk_err != nil {
+		return walk_err
+	}
+
+	return err
+}
+
+// newWalkTask returns nil (and no error) for files that should be skipped.
+
+func newWalkTask(path string, opts *WalkOptions) (*walkTask, error) {
+
+	// compressed files (like 123.geojson.gz) are classified by their
+	// uncompressed name and decompressed by feature.LoadFeatureFromFile
+
+	uri_path := feature.StripCompressionExtension(path)
+
+	is_wof, err := uri.IsWOFFile(uri_path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !is_wof {
+		return nil, nil
+	}
+
+	_, uri_args, err := uri.ParseURI(uri_path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !uri_args.IsAlternate {
+
+		if opts.FeatureFunc == nil {
+			return nil, nil
+		}
+
+		return &walkTask{path: path}, nil
+	}
+
+	if opts.AltFeatureFunc == nil {
+		return nil, nil
+	}
+
+	if opts.Filters != nil {
+
+		label, err := uri_args.AltGeom.String()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !matchesIncludeExclude(label, opts.Filters.IncludeAltLabels, opts.Filters.ExcludeAltLabels) {
+			return nil, nil
+		}
+	}
+
+	return &walkTask{path: path, is_alt: true}, nil
+}
+
+func walkFile(ctx context.Context, t *walkTask, opts *WalkOptions) error {
+
+	f, err := feature.LoadFeatureFromFile(t.path)
+
+	if err != nil && !(f != nil && geojson.IsWarning(err)) {
+		return fmt.Errorf("Failed to load %s, %w", t.path, err)
+	}
+
+	if t.is_alt {
+		return opts.AltFeatureFunc(ctx, t.path, f)
+	}
+
+	if opts.Filters != nil {
+
+		ok, err := matchesFilters(f, opts.Filters)
+
+		if err != nil {
+			return fmt.Errorf("Failed to filter %s, %w", t.path, err)
+		}
+
+		if !ok {
+			return nil
+		}
+	}
+
+	return opts.FeatureFunc(ctx, t.path, f)
+}
+
+func matchesFilters(f geojson.Feature, filters *Filters) (bool, error) {
+
+	if !matchesIncludeExclude(f.Placetype(), filters.IncludePlacetypes, filters.ExcludePlacetypes) {
+		return false, nil
+	}
+
+	for _, name := range filters.IncludeExistential {
+
+		fl, err := existentialFlag(f, name)
+
+		if err != nil {
+			return false, err
+		}
+
+		if !fl.IsTrue() || !fl.IsKnown() {
+			return false, nil
+		}
+	}
+
+	for _, name := range filters.ExcludeExistential {
+
+		fl, err := existentialFlag(f, name)
+
+		if err != nil {
+			return false, err
+		}
+
+		if fl.IsTrue() && fl.IsKnown() {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func matchesIncludeExclude(value string, include []string, exclude []string) bool {
+
+	if len(include) > 0 && !stringInSlice(value, include) {
+		return false
+	}
+
+	if stringInSlice(value, exclude) {
+		return false
+	}
+
+	return true
+}
+
+func existentialFlag(f geojson.Feature, name string) (flags.ExistentialFlag, error) {
+
+	switch name {
+	case "is_current":
+		return whosonfirst.IsCurrent(f)
+	case "is_ceased":
+		return whosonfirst.IsCeased(f)
+	case "is_deprecated":
+		return whosonfirst.IsDeprecated(f)
+	case "is_superseded":
+		return whosonfirst.IsSuperseded(f)
+	case "is_superseding":
+		return whosonfirst.IsSuperseding(f)
+	default:
+		return nil, fmt.Errorf("Invalid existential flag '%s'", name)
+	}
+}
+
+func stringInSlice(value string, candidates []string) bool {
+
+	for _, c := range candidates {
+
+		if c == value {
+			return true
+		}
+	}
+
+	return false
+}